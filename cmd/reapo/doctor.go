@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"reapo/internal/auth"
+	"reapo/internal/config"
+	"reapo/internal/logger"
+)
+
+// checkResult is the outcome of one doctor check
+type checkResult struct {
+	name   string
+	ok     bool
+	detail string
+	hint   string // How to fix a failed check
+}
+
+// runDoctor checks the environment reapo depends on, prints the results and
+// exits non-zero if any check failed
+func runDoctor(ctx context.Context, client anthropic.Client) {
+	results := []checkResult{
+		checkConfig(),
+		checkAuth(),
+		checkDataDir(),
+		checkLogDir(),
+		checkWorkingDir(),
+		checkAPI(ctx, client),
+	}
+
+	failed := 0
+	for _, result := range results {
+		status := "ok"
+		if !result.ok {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Printf("[%-4s] %-12s %s\n", status, result.name, result.detail)
+		if !result.ok && result.hint != "" {
+			fmt.Printf("       %-12s -> %s\n", "", result.hint)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d of %d checks failed\n", failed, len(results))
+		exit(1)
+	}
+	fmt.Println("\nAll checks passed")
+}
+
+// checkConfig re-reads the config file so parse errors are reported
+func checkConfig() checkResult {
+	result := checkResult{name: "config"}
+	path, err := config.DefaultPath()
+	if err != nil {
+		result.detail = err.Error()
+		result.hint = "set HOME or XDG_CONFIG_HOME"
+		return result
+	}
+	if err := config.Init(""); err != nil {
+		result.detail = err.Error()
+		result.hint = "fix or remove " + path
+		return result
+	}
+
+	result.ok = true
+	result.detail = path
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		result.detail += " (not found, using defaults)"
+	}
+	return result
+}
+
+// checkAuth reports which authentication method is in use
+func checkAuth() checkResult {
+	status := auth.GetAuthStatus()
+	if !auth.IsAuthenticated() {
+		return checkResult{
+			name:   "auth",
+			detail: "not authenticated (ANTHROPIC_API_KEY not set and no saved login)",
+			hint:   "run reapo and use /login, or set ANTHROPIC_API_KEY",
+		}
+	}
+	return checkResult{name: "auth", ok: true, detail: status}
+}
+
+// checkDataDir verifies that saved logins can be written
+func checkDataDir() checkResult {
+	dir, err := auth.DefaultDataDir()
+	if err != nil {
+		return checkResult{name: "data dir", detail: err.Error(), hint: "set HOME or XDG_DATA_HOME"}
+	}
+	if err := checkWritable(dir); err != nil {
+		return checkResult{name: "data dir", detail: err.Error(), hint: "fix the permissions of " + dir + " or set XDG_DATA_HOME"}
+	}
+	return checkResult{name: "data dir", ok: true, detail: dir}
+}
+
+// checkLogDir verifies that the log files can be written
+func checkLogDir() checkResult {
+	dir := logger.Dir()
+	if err := checkWritable(dir); err != nil {
+		return checkResult{name: "log dir", detail: err.Error(), hint: "fix the permissions of " + dir + " or run reapo from another directory"}
+	}
+	return checkResult{name: "log dir", ok: true, detail: dir}
+}
+
+// checkWorkingDir verifies that the file tools can read the working directory
+func checkWorkingDir() checkResult {
+	dir, err := os.Getwd()
+	if err != nil {
+		return checkResult{name: "working dir", detail: err.Error(), hint: "run reapo from an existing directory or pass --cwd"}
+	}
+	if _, err := os.ReadDir(dir); err != nil {
+		return checkResult{name: "working dir", detail: err.Error(), hint: "run reapo from a readable directory or pass --cwd"}
+	}
+	return checkResult{name: "working dir", ok: true, detail: dir}
+}
+
+// checkAPI pings the API by looking up the configured model, which needs
+// valid credentials but costs no tokens
+func checkAPI(ctx context.Context, client anthropic.Client) checkResult {
+	result := checkResult{name: "api"}
+	model := config.Get().Model
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := client.Models.Get(ctx, model, anthropic.ModelGetParams{})
+	if err == nil {
+		result.ok = true
+		result.detail = "reachable, model " + model + " available"
+		return result
+	}
+
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		result.detail = "cannot reach the Anthropic API: " + err.Error()
+		result.hint = "check your network connection and proxy settings"
+		if baseURL := auth.BaseURL(); baseURL != "" {
+			result.hint = "check that " + baseURL + " (base_url or ANTHROPIC_BASE_URL) is reachable"
+		}
+		return result
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		result.detail = "reachable, but the credentials were rejected"
+		result.hint = "run reapo and use /login again, or check ANTHROPIC_API_KEY"
+	case http.StatusNotFound:
+		result.detail = fmt.Sprintf("reachable, but model %s was not found", model)
+		result.hint = "set \"model\" in the config file to an available model"
+	default:
+		result.detail = fmt.Sprintf("reachable, but the request failed with status %d", apiErr.StatusCode)
+		result.hint = "try again later; see https://status.anthropic.com"
+	}
+	return result
+}
+
+// checkWritable creates and removes a temporary file in dir
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
@@ -4,16 +4,25 @@ import (
 	"bufio"
 	"context"
 	_ "embed"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"reapo/internal/agent"
 	"reapo/internal/auth"
+	"reapo/internal/config"
 	"reapo/internal/logger"
+	"reapo/internal/project"
+	"reapo/internal/references"
 	"reapo/internal/tools"
 	"reapo/internal/tui"
 )
@@ -21,6 +30,14 @@ import (
 //go:embed system_prompt.txt
 var systemPromptContent string
 
+// Build information, set at build time with:
+//
+//	go build -ldflags "-X main.version=v1.2.3 -X main.commit=$(git rev-parse HEAD)" ./cmd/reapo
+var (
+	version = "dev"
+	commit  = ""
+)
+
 func main() {
 	// Initialize logger
 	if err := logger.Init(); err != nil {
@@ -29,44 +46,171 @@ func main() {
 	defer logger.Close()
 	logger.Debug("Starting reapo...")
 
-	// Create authenticated client
+	// Parse command line arguments
+	dryRun := flag.Bool("dry-run", false, "show tool calls without executing them")
+	showVersion := flag.Bool("version", false, "print version information and exit")
+	cwd := flag.String("cwd", "", "operate on `dir` instead of the current directory")
+	continueSession := flag.Bool("continue", false, "restore the conversation saved for this directory, or its latest session, without asking")
+	noAltScreen := flag.Bool("no-altscreen", false, "render inline, leaving the conversation in the terminal's scrollback on exit")
+	flag.Parse()
+	agent.SetDryRun(*dryRun)
+	tui.RestoreOnStart = *continueSession
+	tui.AltScreen = !*noAltScreen
+	args := flag.Args()
+
+	if *showVersion || (len(args) > 0 && args[0] == "version") {
+		printVersion()
+		return
+	}
+
+	// File tools, completion and the footer all work relative to the process's
+	// working directory, so --cwd only needs to change it for this process
+	if *cwd != "" {
+		if err := changeDir(*cwd); err != nil {
+			log.Printf("Error: %s\n", err.Error())
+			exit(1)
+		}
+	}
+
+	// Shut down cleanly on external termination (e.g. a multiplexer closing the pane)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	// Load user configuration (honors XDG_CONFIG_HOME)
+	if err := config.Init(""); err != nil {
+		logger.Error("Failed to load config, using defaults: %v", err)
+	}
+	cfg := config.Get()
+	logger.SetJSON(cfg.LogFormat == "json")
+
+	// Initialize auth storage (honors XDG_DATA_HOME)
+	if err := auth.InitStorage(""); err != nil {
+		// Continue without persisted auth - env var auth still works
+		logger.Error("Failed to initialize auth storage: %v", err)
+	}
+
+	// Create the authenticated client shared by the main and task agents
 	client, err := auth.NewClient()
 	if err != nil {
-		// Log warning but continue - some commands like /login should work without auth
+		// Log warning but continue - some commands like /login should work
+		// without auth, and the client picks up the login once it's done
 		logger.Debug("No authentication available: %v", err)
-		// Create a default client that might work with env vars
-		client = anthropic.NewClient()
 	}
 
+	// Detect the project type so format and verify commands have sensible
+	// defaults and the model knows what kind of project it's working in
+	project.Current = project.Detect(".")
+	logger.Debug("Detected project type: %s", project.Current)
+	systemPromptContent += project.Current.PromptNote()
+
+	// Shell commands (@!command references) are opt-in
+	tools.ShellEnabled = cfg.EnableShell || os.Getenv("REAPO_ENABLE_SHELL") == "1"
+
 	// Initialize task agent with client and system prompt
 	tools.InitializeTaskAgent(&client, systemPromptContent)
 
-	// Register all available tools
-	toolDefs := []tools.ToolDefinition{
-		tools.ReadFileDefinition,
-		tools.ListFilesDefinition,
-		tools.EditFileDefinition,
-		tools.TodoReadDefinition,
-		tools.TodoWriteDefinition,
-		tools.RunTaskDefinition,
+	// Register all available tools, filtered by config
+	allTools := tools.Definitions()
+	if err := tools.ValidateDefinitions(allTools); err != nil {
+		log.Printf("Error: %s\n", err.Error())
+		exit(1)
+	}
+	var toolDefs []tools.ToolDefinition
+	for _, toolDef := range allTools {
+		if cfg.ToolEnabled(toolDef.Name) {
+			toolDefs = append(toolDefs, toolDef)
+		}
 	}
 
-	// Parse command line arguments
-	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "doctor" {
+		// Environment self-check: reapo doctor
+		runDoctor(ctx, client)
+		return
+	}
 
 	if len(args) > 0 && args[0] == "run" {
 		// Non-interactive mode: reapo run
-		runNonInteractive(client, toolDefs, args[1:])
+		runNonInteractive(ctx, client, toolDefs, args[1:])
 	} else {
 		// Interactive TUI mode: reapo
-		runTUI(client, toolDefs)
+		runTUI(ctx, client, toolDefs)
+	}
+}
+
+// printVersion prints the build version, git commit and Go version.
+// The commit falls back to the VCS info embedded by the Go toolchain.
+func printVersion() {
+	revision := commit
+	modified := false
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if revision == "" {
+					revision = setting.Value
+				}
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+	}
+	if revision == "" {
+		revision = "unknown"
+	} else if modified && commit == "" {
+		revision += " (modified)"
+	}
+
+	fmt.Printf("reapo %s\ncommit: %s\ngo: %s\n", version, revision, runtime.Version())
+}
+
+// changeDir switches the working directory to dir after checking it is a directory
+func changeDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("invalid --cwd: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid --cwd: %s is not a directory", dir)
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("invalid --cwd: %w", err)
 	}
+	logger.Debug("Working directory set to %s", dir)
+	return nil
 }
 
-func runNonInteractive(client anthropic.Client, toolDefs []tools.ToolDefinition, args []string) {
+// exit flushes logs before terminating with the given status code
+func exit(code int) {
+	logger.Close()
+	os.Exit(code)
+}
+
+func runNonInteractive(ctx context.Context, client anthropic.Client, toolDefs []tools.ToolDefinition, args []string) {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	promptFile := flags.String("file", "", "read the prompt from `path` instead of arguments or stdin")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			exit(0)
+		}
+		exit(2)
+	}
+	args = flags.Args()
+
 	var input string
 
-	if len(args) > 0 {
+	if *promptFile != "" {
+		// Input from a prompt file
+		if len(args) > 0 {
+			log.Println("Error: --file cannot be combined with a prompt argument")
+			exit(2)
+		}
+		data, err := os.ReadFile(*promptFile)
+		if err != nil {
+			log.Printf("Error reading prompt file: %s\n", err.Error())
+			exit(1)
+		}
+		input = strings.TrimSpace(string(data))
+	} else if len(args) > 0 {
 		// Input from command line arguments
 		input = strings.Join(args, " ")
 	} else {
@@ -78,37 +222,49 @@ func runNonInteractive(client anthropic.Client, toolDefs []tools.ToolDefinition,
 		}
 		if err := scanner.Err(); err != nil {
 			log.Printf("Error reading stdin: %s\n", err.Error())
-			os.Exit(1)
+			exit(1)
 		}
 		input = strings.Join(lines, "\n")
 	}
 
 	if input == "" {
 		log.Println("Error: No input provided")
-		os.Exit(1)
+		exit(1)
 	}
 
+	// Inline @file, @directory and @!command references like the TUI does
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = "."
+	}
+	input = references.Expand(input, workingDir)
+
 	// Create agent for non-interactive mode
 	agentInstance := agent.NewAgent(&client, nil, toolDefs, systemPromptContent)
 
 	// Run the non-interactive session with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	timeout := time.Duration(config.Get().TimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	response, err := agentInstance.GenerateText(ctx, input)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			log.Printf("Request timed out after 60 seconds\n")
+			log.Printf("Request timed out after %s\n", timeout)
 		} else if ctx.Err() == context.Canceled {
 			log.Printf("Request was cancelled\n")
 		} else {
 			log.Printf("Error: %s\n", err.Error())
 		}
-		os.Exit(1)
+		exit(1)
 	}
 	fmt.Print(response)
 }
 
-func runTUI(client anthropic.Client, toolDefs []tools.ToolDefinition) {
-	tui.RunTUI(client, toolDefs, systemPromptContent)
+func runTUI(ctx context.Context, client anthropic.Client, toolDefs []tools.ToolDefinition) {
+	if err := tui.RunTUI(ctx, client, toolDefs, systemPromptContent); err != nil {
+		log.Printf("Error: %s\n", err.Error())
+		exit(1)
+	}
+	logger.Debug("reapo exited cleanly")
 }
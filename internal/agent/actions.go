@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diffContext is how many unchanged lines surround a recorded change
+const diffContext = 3
+
+// Action is a call to a tool that may change the workspace, or a shell
+// command, recorded in order so the session's changes can be exported
+type Action struct {
+	Time    time.Time
+	Tool    string // "shell" for commands
+	Input   json.RawMessage
+	Command string // The command run, for shell actions
+	Path    string // File the tool was given, if any
+	Patch   string // Unified diff of the change the tool made to Path
+	Err     string
+}
+
+var (
+	actions   []Action
+	actionsMu sync.Mutex
+)
+
+// RecordedActions returns the recorded actions, oldest first
+func RecordedActions() []Action {
+	actionsMu.Lock()
+	defer actionsMu.Unlock()
+	return append([]Action(nil), actions...)
+}
+
+// RecordCommand records a shell command that was run
+func RecordCommand(command string, err error) {
+	action := Action{Time: time.Now(), Tool: "shell", Command: command}
+	if err != nil {
+		action.Err = err.Error()
+	}
+	appendAction(action)
+}
+
+// fileSnapshot is the content of the file a tool call names, taken before
+// the tool runs
+type fileSnapshot struct {
+	path    string
+	content string
+	existed bool
+}
+
+// snapshotInput reads the file named by a tool input's "path" field
+func snapshotInput(input json.RawMessage) fileSnapshot {
+	var fields struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(input, &fields); err != nil || fields.Path == "" {
+		return fileSnapshot{}
+	}
+
+	content, err := os.ReadFile(fields.Path)
+	return fileSnapshot{path: fields.Path, content: string(content), existed: err == nil}
+}
+
+// recordAction records a call to a tool that isn't read-only, with the
+// change it made to the file in before
+func recordAction(name string, input json.RawMessage, before fileSnapshot, err error) {
+	action := Action{Time: time.Now(), Tool: name, Input: input, Path: before.path}
+	if err != nil {
+		action.Err = err.Error()
+	}
+	if before.path != "" {
+		if after, readErr := os.ReadFile(before.path); readErr == nil {
+			action.Patch = unifiedDiff(before, string(after))
+		}
+	}
+	appendAction(action)
+}
+
+func appendAction(action Action) {
+	actionsMu.Lock()
+	defer actionsMu.Unlock()
+	actions = append(actions, action)
+}
+
+// unifiedDiff returns a diff that turns the snapshot into after, or "" if
+// nothing changed. Unchanged lines are trimmed from both ends, so a diff has
+// a single hunk spanning every change.
+func unifiedDiff(before fileSnapshot, after string) string {
+	if before.existed && before.content == after {
+		return ""
+	}
+
+	a, b := splitLines(before.content), splitLines(after)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	start := max(prefix-diffContext, 0)
+	endA := min(len(a)-suffix+diffContext, len(a))
+	endB := min(len(b)-suffix+diffContext, len(b))
+
+	oldName := before.path
+	if !before.existed {
+		oldName = "/dev/null"
+	}
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %s\n+++ %s\n", oldName, before.path)
+	fmt.Fprintf(&diff, "@@ -%s +%s @@\n", hunkRange(start, endA-start), hunkRange(start, endB-start))
+	for _, line := range a[start:prefix] {
+		writeDiffLine(&diff, ' ', line)
+	}
+	for _, line := range a[prefix : len(a)-suffix] {
+		writeDiffLine(&diff, '-', line)
+	}
+	for _, line := range b[prefix : len(b)-suffix] {
+		writeDiffLine(&diff, '+', line)
+	}
+	for _, line := range a[len(a)-suffix : endA] {
+		writeDiffLine(&diff, ' ', line)
+	}
+	return diff.String()
+}
+
+// splitLines splits text into lines that keep their "\n"; only the last
+// line can lack one
+func splitLines(text string) []string {
+	var lines []string
+	for text != "" {
+		line, rest, found := strings.Cut(text, "\n")
+		if found {
+			line += "\n"
+		}
+		lines = append(lines, line)
+		text = rest
+	}
+	return lines
+}
+
+// hunkRange formats the start and length of one side of a hunk, where an
+// empty side is numbered by the line before it
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// writeDiffLine writes a diff line, marking a final line without a newline
+func writeDiffLine(diff *strings.Builder, prefix byte, line string) {
+	diff.WriteByte(prefix)
+	diff.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		diff.WriteString("\n\\ No newline at end of file\n")
+	}
+}
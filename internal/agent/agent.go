@@ -3,11 +3,15 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"reapo/internal/config"
 	"reapo/internal/logger"
 )
 
@@ -23,24 +27,47 @@ type ToolDefinition struct {
 	Name        string                         `json:"name"`
 	Description string                         `json:"description"`
 	InputSchema anthropic.ToolInputSchemaParam `json:"input_schema"`
-	Function    func(input json.RawMessage) (string, error)
+	Function    func(ctx context.Context, input json.RawMessage) (string, error)
+	// ReadOnly marks tools without side effects; their results are cached for
+	// the rest of the turn. Any other tool is assumed to mutate its path.
+	ReadOnly bool `json:"-"`
 }
 
+// ToolCancelledMessage is reported to the model when a tool is aborted before it finishes
+const ToolCancelledMessage = "Tool execution was cancelled by the user"
+
 // RunTaskInput represents the input for running a task
 type RunTaskInput struct {
 	Task    string `json:"task" jsonschema:"description=The task to execute"`
 	Context string `json:"context" jsonschema:"description=Additional context for the task"`
 }
 
-// ToolCallback represents a callback function for tool lifecycle events
+// ToolCallback represents a callback function for tool lifecycle events:
+// "start" with the tool's input, "complete" with its output and duration as
+// JSON, "error" with the error, and "text" for text the model writes between
+// tool calls in RunWithTools
 type ToolCallback func(event string, toolName, toolID, data string)
 
+// dryRun makes ExecuteTool report tool calls without running them
+var dryRun atomic.Bool
+
+// SetDryRun enables or disables dry-run mode for all agents
+func SetDryRun(enabled bool) {
+	dryRun.Store(enabled)
+}
+
+// DryRun reports whether dry-run mode is enabled
+func DryRun() bool {
+	return dryRun.Load()
+}
+
 // Agent represents an AI agent that can interact with tools
 type Agent struct {
 	client       *anthropic.Client
 	tools        []ToolDefinition
 	systemPrompt string
 	toolCallback ToolCallback
+	cache        toolCache
 }
 
 // NewAgent creates a new agent
@@ -57,6 +84,11 @@ func (a *Agent) SetToolCallback(callback ToolCallback) {
 	a.toolCallback = callback
 }
 
+// ResetToolCache forgets cached read-only tool results; call it at the start of each turn
+func (a *Agent) ResetToolCache() {
+	a.cache.reset()
+}
+
 // GenerateText runs inference and returns the text response
 func (a *Agent) GenerateText(ctx context.Context, message string) (string, error) {
 	conversation := []anthropic.MessageParam{
@@ -81,6 +113,17 @@ func (a *Agent) GenerateText(ctx context.Context, message string) (string, error
 
 // RunInference executes inference with Claude API
 func (a *Agent) RunInference(ctx context.Context, conversation []anthropic.MessageParam) (*anthropic.Message, error) {
+	return a.runInference(ctx, conversation, nil)
+}
+
+// RunInferenceStream executes inference like RunInference, but streams the
+// response and calls onText with each piece of text as it arrives
+func (a *Agent) RunInferenceStream(ctx context.Context, conversation []anthropic.MessageParam, onText func(text string)) (*anthropic.Message, error) {
+	return a.runInference(ctx, conversation, onText)
+}
+
+// runInference sends the request, streaming it when onText is set
+func (a *Agent) runInference(ctx context.Context, conversation []anthropic.MessageParam, onText func(text string)) (*anthropic.Message, error) {
 	anthropicTools := []anthropic.ToolUnionParam{}
 	for _, tool := range a.tools {
 		anthropicTools = append(anthropicTools, anthropic.ToolUnionParam{
@@ -142,35 +185,100 @@ func (a *Agent) RunInference(ctx context.Context, conversation []anthropic.Messa
 		messages = append(messages, messageInfo)
 	}
 
-	logger.Chat("REQUEST", map[string]interface{}{
-		"model":     "claude-4-sonnet-20250514",
+	cfg := config.Get()
+	id := requestID(ctx)
+
+	logger.Chat("REQUEST", id, map[string]interface{}{
+		"model":     cfg.Model,
 		"messages":  messages,
 		"toolCount": len(anthropicTools),
+		"effort":    cfg.Effort,
 	})
 
-	message, err := a.client.Messages.New(ctx, anthropic.MessageNewParams{
-		Model:     anthropic.ModelClaude4Sonnet20250514,
-		MaxTokens: int64(1024),
+	// Retrying while offline only delays the error; each new request is
+	// attempted once until one gets through again
+	var opts []option.RequestOption
+	if Offline() {
+		opts = append(opts, option.WithMaxRetries(0))
+	}
+
+	params := anthropic.MessageNewParams{
+		Model:     anthropic.Model(cfg.Model),
+		MaxTokens: cfg.MaxTokens,
 		Messages:  conversation,
 		Tools:     anthropicTools,
 		System:    []anthropic.TextBlockParam{{Type: "text", Text: a.systemPrompt}},
-	})
+	}
+
+	// The thinking budget comes on top of max_tokens, which must exceed it,
+	// so a higher effort doesn't leave less room for the answer. The SDK
+	// refuses requests that may run this long unless they're streamed.
+	if budget := ThinkingBudget(cfg.Effort, cfg.Model); budget > 0 {
+		params.Thinking = anthropic.ThinkingConfigParamOfEnabled(budget)
+		params.MaxTokens += budget
+		if onText == nil {
+			onText = func(string) {}
+		}
+	}
+
+	// Answer-only requests leave the tools out, or forbid calling them when
+	// the conversation already has tool blocks that need their definitions
+	if toolsDisabled(ctx) {
+		if hasToolBlocks(conversation) {
+			toolChoice := anthropic.NewToolChoiceNoneParam()
+			params.ToolChoice = anthropic.ToolChoiceUnionParam{OfNone: &toolChoice}
+		} else {
+			params.Tools = nil
+		}
+	}
+
+	start := time.Now()
+	var message *anthropic.Message
+	var err error
+	if onText == nil {
+		message, err = a.client.Messages.New(ctx, params, opts...)
+	} else {
+		message, err = a.stream(ctx, params, opts, onText)
+	}
+	err = updateNetworkState(ctx, err)
 
 	// Log the chat response
 	if err != nil {
-		logger.Chat("ERROR", map[string]interface{}{
+		logger.Chat("ERROR", id, map[string]interface{}{
 			"error": err.Error(),
 		})
 		logger.Error("API request failed: %v", err)
 	} else {
-		logger.Chat("RESPONSE", message)
+		logger.Chat("RESPONSE", id, message)
 	}
+	recordExchange(id, start, cfg.Model, conversation, message, err)
 
 	return message, err
 }
 
+// stream runs a streaming request and accumulates the events into the final message
+func (a *Agent) stream(ctx context.Context, params anthropic.MessageNewParams, opts []option.RequestOption, onText func(text string)) (*anthropic.Message, error) {
+	stream := a.client.Messages.NewStreaming(ctx, params, opts...)
+	defer stream.Close()
+
+	message := &anthropic.Message{}
+	for stream.Next() {
+		event := stream.Current()
+		if err := message.Accumulate(event); err != nil {
+			return nil, fmt.Errorf("failed to read response stream: %w", err)
+		}
+		if event.Type == "content_block_delta" && event.Delta.Type == "text_delta" {
+			onText(event.Delta.Text)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
 // ExecuteToolsConcurrently runs multiple tools in parallel
-func (a *Agent) ExecuteToolsConcurrently(toolUses []ToolUseInfo) []anthropic.ContentBlockParamUnion {
+func (a *Agent) ExecuteToolsConcurrently(ctx context.Context, toolUses []ToolUseInfo) []anthropic.ContentBlockParamUnion {
 	if len(toolUses) == 0 {
 		return nil
 	}
@@ -184,7 +292,7 @@ func (a *Agent) ExecuteToolsConcurrently(toolUses []ToolUseInfo) []anthropic.Con
 	// Kick off all tools concurrently
 	for i, toolUse := range toolUses {
 		go func(index int, tu ToolUseInfo) {
-			result := a.ExecuteTool(tu.ID, tu.Name, tu.Input)
+			result, _ := a.ExecuteTool(ctx, tu.ID, tu.Name, tu.Input)
 			resultChan <- struct {
 				index  int
 				result anthropic.ContentBlockParamUnion
@@ -201,8 +309,11 @@ func (a *Agent) ExecuteToolsConcurrently(toolUses []ToolUseInfo) []anthropic.Con
 	return results
 }
 
-// ExecuteTool executes a single tool
-func (a *Agent) ExecuteTool(id, name string, input json.RawMessage) anthropic.ContentBlockParamUnion {
+// ExecuteTool executes a single tool under ctx, which carries the request deadline.
+// If ctx is cancelled or times out, the tool's result is reported to the model as
+// an error so the conversation can continue. The returned error is the tool's
+// failure, if any, for callers that want to categorize it with ErrorCategory.
+func (a *Agent) ExecuteTool(ctx context.Context, id, name string, input json.RawMessage) (anthropic.ContentBlockParamUnion, error) {
 	var toolDef ToolDefinition
 	var found bool
 	for _, tool := range a.tools {
@@ -216,7 +327,7 @@ func (a *Agent) ExecuteTool(id, name string, input json.RawMessage) anthropic.Co
 		if a.toolCallback != nil {
 			a.toolCallback("error", name, id, "tool not found")
 		}
-		return anthropic.NewToolResultBlock(id, "tool not found", true)
+		return anthropic.NewToolResultBlock(id, "tool not found", true), errors.New("tool not found")
 	}
 
 	// Notify UI that tool is starting
@@ -227,9 +338,49 @@ func (a *Agent) ExecuteTool(id, name string, input json.RawMessage) anthropic.Co
 	// Log tool execution to file instead of stdout to avoid TUI corruption
 	logger.Tool(name, string(input))
 
+	// In dry-run mode, report the call back to the model without running it
+	if DryRun() {
+		response := fmt.Sprintf("Dry run: %s was not executed. Assume it succeeded and continue.", name)
+		if a.toolCallback != nil {
+			a.toolCallback("complete", name, id, fmt.Sprintf(`{"output": %q, "duration": "0s"}`, response))
+		}
+		return anthropic.NewToolResultBlock(id, response, false), nil
+	}
+
+	// Repeated read-only calls in the same turn point back at the earlier result
+	if toolDef.ReadOnly {
+		if entry, ok := a.cache.lookup(name, input); ok {
+			response := cachedResponse(name, entry)
+			if a.toolCallback != nil {
+				a.toolCallback("complete", name, id, fmt.Sprintf(`{"output": %q, "duration": "0s"}`, response))
+			}
+			return anthropic.NewToolResultBlock(id, response, false), nil
+		}
+	} else {
+		a.cache.invalidate(input)
+	}
+
+	// Note the file a tool may change so its edit can be exported
+	var before fileSnapshot
+	if !toolDef.ReadOnly {
+		before = snapshotInput(input)
+	}
+
 	startTime := time.Now()
-	response, err := toolDef.Function(input)
+	response, err := toolDef.Function(ctx, input)
 	duration := time.Since(startTime)
+	switch ctx.Err() {
+	case context.Canceled:
+		response, err = "", NewToolError(ToolErrorCancelled, errors.New(ToolCancelledMessage))
+	case context.DeadlineExceeded:
+		response, err = "", ToolErrorf(ToolErrorTimeout, "%s timed out after %s", name, duration.Round(time.Millisecond))
+	}
+	if !toolDef.ReadOnly {
+		recordAction(name, input, before, err)
+	}
+	if err == nil {
+		response = truncateToolResult(response, config.Get().MaxToolResultTokens)
+	}
 
 	// Notify UI of completion
 	if a.toolCallback != nil {
@@ -242,8 +393,12 @@ func (a *Agent) ExecuteTool(id, name string, input json.RawMessage) anthropic.Co
 	}
 
 	if err != nil {
-		return anthropic.NewToolResultBlock(id, err.Error(), true)
+		return anthropic.NewToolResultBlock(id, err.Error(), true), err
+	}
+
+	if toolDef.ReadOnly {
+		a.cache.store(id, name, input)
 	}
 
-	return anthropic.NewToolResultBlock(id, response, false)
+	return anthropic.NewToolResultBlock(id, response, false), nil
 }
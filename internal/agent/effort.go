@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"strings"
+)
+
+// effortBudgets maps each effort level to the thinking tokens the model may
+// spend before answering. With no effort set, requests don't use extended
+// thinking.
+var effortBudgets = map[string]int64{
+	"low":    2048,
+	"medium": 8192,
+	"high":   24576,
+}
+
+// thinkingUnsupported are prefixes of models without extended thinking
+var thinkingUnsupported = []string{
+	"claude-3-5-",
+	"claude-3-haiku",
+	"claude-3-opus",
+	"claude-3-sonnet",
+	"claude-2",
+	"claude-instant",
+}
+
+// SupportsThinking reports whether model accepts a thinking budget. Models
+// it doesn't recognize, e.g. behind a gateway, are assumed to.
+func SupportsThinking(model string) bool {
+	for _, prefix := range thinkingUnsupported {
+		if strings.HasPrefix(model, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// ThinkingBudget returns the thinking tokens requests to model get at the
+// given effort level, or 0 when thinking is off or model doesn't support it
+func ThinkingBudget(effort, model string) int64 {
+	if !SupportsThinking(model) {
+		return 0
+	}
+	return effortBudgets[effort]
+}
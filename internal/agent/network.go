@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// ErrNetworkUnreachable wraps request failures caused by connectivity rather than the API
+var ErrNetworkUnreachable = errors.New("network unreachable")
+
+// offline is set when the last request failed before reaching the API
+var offline atomic.Bool
+
+// Offline reports whether the last completed request failed with a network error
+func Offline() bool {
+	return offline.Load()
+}
+
+// isNetworkError reports whether err means the API could not be reached at all,
+// as opposed to the API returning an error or the request being cancelled
+func isNetworkError(err error) bool {
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ENETUNREACH) ||
+		errors.Is(err, syscall.EHOSTUNREACH)
+}
+
+// updateNetworkState records the outcome of a request and marks network
+// failures with ErrNetworkUnreachable
+func updateNetworkState(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		offline.Store(false)
+	case isNetworkError(err):
+		offline.Store(true)
+		return fmt.Errorf("%w: %w", ErrNetworkUnreachable, err)
+	case ctx.Err() == nil:
+		// The API answered, so the network is fine
+		offline.Store(false)
+	}
+	return err
+}
@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+type withoutToolsKey struct{}
+
+// WithoutTools marks requests made under ctx as answer-only: the model is
+// not offered any tools and replies directly
+func WithoutTools(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutToolsKey{}, true)
+}
+
+// toolsDisabled reports whether ctx was marked with WithoutTools
+func toolsDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(withoutToolsKey{}).(bool)
+	return disabled
+}
+
+// hasToolBlocks reports whether the conversation contains tool calls or
+// results, e.g. from @-referenced files. The API rejects those unless the
+// tools are defined.
+func hasToolBlocks(conversation []anthropic.MessageParam) bool {
+	for _, msg := range conversation {
+		for _, content := range msg.Content {
+			if content.OfToolUse != nil || content.OfToolResult != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"strings"
+)
+
+// modelPrice is what a model costs in USD per million tokens
+type modelPrice struct {
+	prefix string
+	input  float64
+	output float64
+}
+
+// modelPrices lists known prices by model name prefix, most specific first
+var modelPrices = []modelPrice{
+	{prefix: "claude-opus-4", input: 15, output: 75},
+	{prefix: "claude-sonnet-4", input: 3, output: 15},
+	{prefix: "claude-3-7-sonnet", input: 3, output: 15},
+	{prefix: "claude-3-5-sonnet", input: 3, output: 15},
+	{prefix: "claude-3-5-haiku", input: 0.8, output: 4},
+	{prefix: "claude-3-opus", input: 15, output: 75},
+	{prefix: "claude-3-haiku", input: 0.25, output: 1.25},
+}
+
+// EstimateCost returns the cost in USD of a request to model with the given
+// token counts. It reports false for models without a known price.
+func EstimateCost(model string, inputTokens, outputTokens int64) (float64, bool) {
+	for _, price := range modelPrices {
+		if strings.HasPrefix(model, price.prefix) {
+			return (float64(inputTokens)*price.input + float64(outputTokens)*price.output) / 1e6, true
+		}
+	}
+	return 0, false
+}
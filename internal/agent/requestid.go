@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID tags ctx with the ID logged on every request made under it,
+// so the requests and responses of one turn can be matched in the chat log
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// requestID returns the ID set with WithRequestID, or a new UUIDv7 for
+// requests made outside a turn
+func requestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Sprintf("req_fallback_%d", time.Now().UnixNano())
+	}
+	return id.String()
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// maxToolRounds bounds how many rounds of tool calls RunWithTools makes
+// before giving up on an answer
+const maxToolRounds = 25
+
+type toolCallbackKey struct{}
+
+// WithToolCallback attaches callback to ctx so an agent started by a tool,
+// like the run_task sub-agent, can report its own tool calls
+func WithToolCallback(ctx context.Context, callback ToolCallback) context.Context {
+	return context.WithValue(ctx, toolCallbackKey{}, callback)
+}
+
+// ToolCallbackFrom returns the callback attached with WithToolCallback, or nil
+func ToolCallbackFrom(ctx context.Context) ToolCallback {
+	callback, _ := ctx.Value(toolCallbackKey{}).(ToolCallback)
+	return callback
+}
+
+// RunWithTools works like GenerateText, but runs the tools the model calls
+// and sends their results back until it answers without calling any. Tool
+// calls are reported to the tool callback, along with any text the model
+// writes alongside them as a "text" event.
+func (a *Agent) RunWithTools(ctx context.Context, message string) (string, error) {
+	conversation := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock(message)),
+	}
+
+	for round := 0; ; round++ {
+		response, err := a.RunInference(ctx, conversation)
+		if err != nil {
+			return "", err
+		}
+
+		var text strings.Builder
+		var toolUses []ToolUseInfo
+		for _, content := range response.Content {
+			switch content.Type {
+			case "text":
+				text.WriteString(content.Text)
+			case "tool_use":
+				toolUses = append(toolUses, ToolUseInfo{ID: content.ID, Name: content.Name, Input: content.Input})
+			}
+		}
+		if len(toolUses) == 0 {
+			return text.String(), nil
+		}
+		if round == maxToolRounds {
+			return "", fmt.Errorf("gave up after %d rounds of tool calls", maxToolRounds)
+		}
+
+		if a.toolCallback != nil && strings.TrimSpace(text.String()) != "" {
+			a.toolCallback("text", "", "", text.String())
+		}
+		conversation = append(conversation,
+			response.ToParam(),
+			anthropic.NewUserMessage(a.ExecuteToolsConcurrently(ctx, toolUses)...),
+		)
+	}
+}
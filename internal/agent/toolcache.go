@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// toolCache remembers the results of read-only tools for the rest of a turn,
+// so repeated reads of the same path skip the disk and don't resend the content
+type toolCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedCall
+}
+
+// cachedCall is the earlier call that produced a read-only tool result
+type cachedCall struct {
+	toolID string
+	path   string
+}
+
+// cacheKey identifies a tool call by name and normalized input. Re-encoding
+// the input sorts its keys so equivalent JSON produces the same key.
+func cacheKey(name string, input json.RawMessage) (string, string, bool) {
+	var fields map[string]any
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return "", "", false
+	}
+
+	path := "."
+	if p, ok := fields["path"].(string); ok && p != "" {
+		path = filepath.Clean(p)
+	}
+	fields["path"] = path
+
+	normalized, err := json.Marshal(fields)
+	if err != nil {
+		return "", "", false
+	}
+	return name + ":" + string(normalized), path, true
+}
+
+// lookup returns the cached call for a read-only tool, if any
+func (c *toolCache) lookup(name string, input json.RawMessage) (cachedCall, bool) {
+	key, _, ok := cacheKey(name, input)
+	if !ok {
+		return cachedCall{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+// store records a successful read-only tool call
+func (c *toolCache) store(toolID, name string, input json.RawMessage) {
+	key, path, ok := cacheKey(name, input)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedCall)
+	}
+	c.entries[key] = cachedCall{toolID: toolID, path: path}
+}
+
+// invalidate drops cached results affected by a mutating tool call: reads of
+// the touched path and listings of any directory containing it. Tools without
+// a path, such as run_task, may touch anything and clear the whole cache.
+func (c *toolCache) invalidate(input json.RawMessage) {
+	var fields struct {
+		Path string `json:"path"`
+	}
+	_ = json.Unmarshal(input, &fields)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if fields.Path == "" {
+		c.entries = nil
+		return
+	}
+
+	touched := filepath.Clean(fields.Path)
+	for key, entry := range c.entries {
+		if entry.path == touched || entry.path == "." || strings.HasPrefix(touched, entry.path+string(filepath.Separator)) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// reset forgets all cached results
+func (c *toolCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+// cachedResponse tells the model to reuse an earlier, identical tool result
+func cachedResponse(name string, entry cachedCall) string {
+	return fmt.Sprintf("Unchanged since the earlier %s call with the same input in this turn (tool_use_id %s); refer to that result.", name, entry.toolID)
+}
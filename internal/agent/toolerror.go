@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ToolErrorCategory classifies why a tool failed
+type ToolErrorCategory string
+
+const (
+	ToolErrorFailed           ToolErrorCategory = "failed"            // Uncategorized failure
+	ToolErrorInvalidInput     ToolErrorCategory = "invalid_input"     // The input didn't parse or was missing fields
+	ToolErrorNotFound         ToolErrorCategory = "not_found"         // The path doesn't exist
+	ToolErrorPermissionDenied ToolErrorCategory = "permission_denied" // The path isn't readable or writable
+	ToolErrorNoMatch          ToolErrorCategory = "no_match"          // The text to replace wasn't in the file
+	ToolErrorCancelled        ToolErrorCategory = "cancelled"         // The user aborted the tool
+	ToolErrorTimeout          ToolErrorCategory = "timeout"           // The tool ran past the request deadline
+)
+
+// ToolError is a tool failure tagged with a category, so the UI can explain it.
+// Its message is the wrapped error's, so the model sees the same text as before.
+type ToolError struct {
+	Category ToolErrorCategory
+	Err      error
+}
+
+func (e *ToolError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// NewToolError tags err with a category
+func NewToolError(category ToolErrorCategory, err error) error {
+	return &ToolError{Category: category, Err: err}
+}
+
+// ToolErrorf formats a new error tagged with a category
+func ToolErrorf(category ToolErrorCategory, format string, args ...any) error {
+	return &ToolError{Category: category, Err: fmt.Errorf(format, args...)}
+}
+
+// ErrorCategory returns the category of a tool error. Plain errors are
+// ToolErrorFailed, and nil has no category.
+func ErrorCategory(err error) ToolErrorCategory {
+	if err == nil {
+		return ""
+	}
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.Category
+	}
+	return ToolErrorFailed
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"sync"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// maxExchanges is how many recent API exchanges are kept for /debug
+const maxExchanges = 10
+
+// Exchange summarizes a single API request and its response
+type Exchange struct {
+	RequestID    string // Matches the request_id of the chat log entries
+	Time         time.Time
+	Duration     time.Duration
+	Model        string
+	Roles        []string // Role of each message in the request
+	ToolUses     []string // Tools the model asked to run
+	StopReason   string
+	InputTokens  int64
+	OutputTokens int64
+	Err          string
+}
+
+var (
+	exchanges   []Exchange
+	exchangesMu sync.Mutex
+)
+
+// RecentExchanges returns the most recent API exchanges, oldest first
+func RecentExchanges() []Exchange {
+	exchangesMu.Lock()
+	defer exchangesMu.Unlock()
+	return append([]Exchange(nil), exchanges...)
+}
+
+// recordExchange adds a request/response summary to the in-memory transcript
+func recordExchange(id string, start time.Time, model string, conversation []anthropic.MessageParam, message *anthropic.Message, err error) {
+	exchange := Exchange{
+		RequestID: id,
+		Time:      start,
+		Duration:  time.Since(start),
+		Model:     model,
+	}
+	for _, msg := range conversation {
+		exchange.Roles = append(exchange.Roles, string(msg.Role))
+	}
+	if err != nil {
+		exchange.Err = err.Error()
+	} else if message != nil {
+		exchange.StopReason = string(message.StopReason)
+		exchange.InputTokens = message.Usage.InputTokens
+		exchange.OutputTokens = message.Usage.OutputTokens
+		for _, content := range message.Content {
+			if content.Type == "tool_use" {
+				exchange.ToolUses = append(exchange.ToolUses, content.Name)
+			}
+		}
+	}
+
+	exchangesMu.Lock()
+	defer exchangesMu.Unlock()
+	exchanges = append(exchanges, exchange)
+	if len(exchanges) > maxExchanges {
+		exchanges = exchanges[len(exchanges)-maxExchanges:]
+	}
+}
@@ -0,0 +1,22 @@
+package agent
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// truncateToolResult caps a tool result at roughly maxTokens, using the usual
+// approximation of 4 characters per token, and marks where it was cut.
+// A maxTokens of 0 or less leaves the result unchanged.
+func truncateToolResult(response string, maxTokens int) string {
+	if maxTokens <= 0 || len(response) <= maxTokens*4 {
+		return response
+	}
+
+	// Back up to a rune boundary so the result stays valid UTF-8
+	cut := maxTokens * 4
+	for cut > 0 && !utf8.RuneStart(response[cut]) {
+		cut--
+	}
+	return response[:cut] + fmt.Sprintf("\n\n[Output truncated: showing the first ~%d of ~%d tokens. Request a smaller range or a narrower path to see the rest.]", maxTokens, len(response)/4)
+}
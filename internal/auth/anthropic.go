@@ -5,10 +5,9 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
+	"sync"
 	"time"
 )
 
@@ -96,7 +95,9 @@ func Authorize() (*AuthorizeResult, error) {
 	}, nil
 }
 
-// Exchange exchanges the authorization code for tokens
+// Exchange exchanges the authorization code for tokens. Failures are a
+// *NetworkError when the endpoint can't be reached and a *StatusError when
+// it rejects the code.
 func Exchange(code, verifier string) (*OAuthInfo, error) {
 	// Split code and state if they're combined with #
 	parts := bytes.Split([]byte(code), []byte("#"))
@@ -117,25 +118,9 @@ func Exchange(code, verifier string) (*OAuthInfo, error) {
 		"code_verifier": verifier,
 	}
 	
-	jsonBody, err := json.Marshal(reqBody)
+	tokenResp, err := requestToken("token exchange", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-	
-	// Make token exchange request
-	resp, err := http.Post(tokenURL, "application/json", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to exchange code: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token exchange failed with status %d", resp.StatusCode)
-	}
-	
-	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return nil, fmt.Errorf("failed to decode token response: %w", err)
+		return nil, err
 	}
 	
 	return &OAuthInfo{
@@ -146,7 +131,7 @@ func Exchange(code, verifier string) (*OAuthInfo, error) {
 	}, nil
 }
 
-// RefreshToken refreshes an OAuth token
+// RefreshToken refreshes an OAuth token, failing with the same error types as Exchange
 func RefreshToken(refreshToken string) (*OAuthInfo, error) {
 	reqBody := map[string]string{
 		"grant_type":    "refresh_token",
@@ -154,24 +139,9 @@ func RefreshToken(refreshToken string) (*OAuthInfo, error) {
 		"client_id":     clientID,
 	}
 	
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-	
-	resp, err := http.Post(tokenURL, "application/json", bytes.NewBuffer(jsonBody))
+	tokenResp, err := requestToken("token refresh", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to refresh token: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token refresh failed with status %d", resp.StatusCode)
-	}
-	
-	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return nil, fmt.Errorf("failed to decode token response: %w", err)
+		return nil, err
 	}
 	
 	return &OAuthInfo{
@@ -182,8 +152,15 @@ func RefreshToken(refreshToken string) (*OAuthInfo, error) {
 	}, nil
 }
 
+// accessTokenMu serializes GetAccessToken so concurrent requests, e.g. from
+// the main agent and the task agent, don't both spend the refresh token
+var accessTokenMu sync.Mutex
+
 // GetAccessToken retrieves a valid access token, refreshing if necessary
 func GetAccessToken(provider string) (string, error) {
+	accessTokenMu.Lock()
+	defer accessTokenMu.Unlock()
+
 	info, err := Get(provider)
 	if err != nil {
 		return "", err
@@ -2,31 +2,63 @@ package auth
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
+	"reapo/internal/config"
 )
 
 // NewClient creates an authenticated Anthropic client
 // Priority: Claude Max (OAuth) > Environment variable
+//
+// The OAuth token is looked up for every request, so the client keeps
+// working across token refreshes and picks up a later /login. The main
+// agent and the task agent share it. When no authentication is available
+// the client is still returned along with the error, so /login can be used.
 func NewClient() (anthropic.Client, error) {
-	// First, try to get OAuth token for Claude Max
-	token, err := GetAccessToken("anthropic")
-	if err == nil && token != "" {
-		// Use OAuth token
-		return anthropic.NewClient(
-			option.WithHeader("Authorization", fmt.Sprintf("Bearer %s", token)),
-		), nil
+	opts := append(ClientOptions(), option.WithMiddleware(withAccessToken))
+	client := anthropic.NewClient(opts...) // SDK will use ANTHROPIC_API_KEY automatically
+
+	if !IsAuthenticated() {
+		return client, fmt.Errorf("no authentication method available. Please run /login or set ANTHROPIC_API_KEY")
 	}
-	
-	// Fall back to environment variable
-	apiKey := os.Getenv("ANTHROPIC_API_KEY")
-	if apiKey != "" {
-		return anthropic.NewClient(), nil // SDK will use env var automatically
+	return client, nil
+}
+
+// withAccessToken sends the current Claude Max token with a request. Without
+// a saved login the request is left to the API key.
+func withAccessToken(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	if token, err := GetAccessToken("anthropic"); err == nil && token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
-	
-	return anthropic.Client{}, fmt.Errorf("no authentication method available. Please run /login or set ANTHROPIC_API_KEY")
+	return next(req)
+}
+
+// ClientOptions returns the endpoint and extra headers configured with
+// base_url and api_headers, for every client that talks to the API.
+// ANTHROPIC_BASE_URL is read by the SDK itself and wins over base_url.
+func ClientOptions() []option.RequestOption {
+	cfg := config.Get()
+
+	var opts []option.RequestOption
+	if _, ok := os.LookupEnv("ANTHROPIC_BASE_URL"); !ok && cfg.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(cfg.BaseURL))
+	}
+	for name, value := range cfg.APIHeaders {
+		opts = append(opts, option.WithHeader(name, value))
+	}
+	return opts
+}
+
+// BaseURL returns the custom API endpoint in use, or an empty string for
+// the default one
+func BaseURL() string {
+	if baseURL := os.Getenv("ANTHROPIC_BASE_URL"); baseURL != "" {
+		return baseURL
+	}
+	return config.Get().BaseURL
 }
 
 // GetAuthStatus returns the current authentication status
@@ -43,4 +75,9 @@ func GetAuthStatus() string {
 	}
 	
 	return "Not authenticated"
-}
\ No newline at end of file
+}
+
+// IsAuthenticated reports whether any authentication method is available
+func IsAuthenticated() bool {
+	return GetAuthStatus() != "Not authenticated"
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,20 +17,53 @@ var (
 	storageFile  string
 )
 
-func init() {
+var errStorageNotInitialized = errors.New("auth storage not initialized")
+
+// DefaultDataDir returns the directory used for reapo's persistent data.
+// It honors $XDG_DATA_HOME and falls back to ~/.local/share/reapo.
+func DefaultDataDir() (string, error) {
+	if xdgDataHome := os.Getenv("XDG_DATA_HOME"); xdgDataHome != "" {
+		return filepath.Join(xdgDataHome, "reapo"), nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		panic(fmt.Sprintf("failed to get home directory: %v", err))
+		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
-	
-	// Create ~/.local/share/reapo directory if it doesn't exist
-	dataDir := filepath.Join(homeDir, ".local", "share", "reapo")
+
+	return filepath.Join(homeDir, ".local", "share", "reapo"), nil
+}
+
+// InitStorage sets up auth storage under the given base directory.
+// If dataDir is empty, DefaultDataDir is used.
+func InitStorage(dataDir string) error {
+	if dataDir == "" {
+		var err error
+		dataDir, err = DefaultDataDir()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the data directory if it doesn't exist
 	if err := os.MkdirAll(dataDir, 0700); err != nil {
-		panic(fmt.Sprintf("failed to create reapo data directory: %v", err))
+		return fmt.Errorf("failed to create reapo data directory: %w", err)
 	}
-	
+
+	storageMutex.Lock()
 	storageFile = filepath.Join(dataDir, "auth.json")
-	logger.Info("Auth storage initialized", "storage_file", storageFile, "data_dir", dataDir)
+	storageMutex.Unlock()
+
+	logger.Infow("Auth storage initialized", "storage_file", storageFile, "data_dir", dataDir)
+	return nil
+}
+
+// StorageFile returns the path of the auth storage file, or an empty
+// string if storage has not been initialized
+func StorageFile() string {
+	storageMutex.RLock()
+	defer storageMutex.RUnlock()
+	return storageFile
 }
 
 // Get retrieves auth info for a specific provider
@@ -55,11 +89,11 @@ func Set(provider string, info AuthInfo) error {
 	storageMutex.Lock()
 	defer storageMutex.Unlock()
 	
-	logger.Info("Set auth info called", "provider", provider, "storage_file", storageFile)
+	logger.Infow("Set auth info called", "provider", provider, "storage_file", storageFile)
 	
 	data, err := readStorage()
 	if err != nil && !os.IsNotExist(err) {
-		logger.Error("Failed to read storage", "error", err)
+		logger.Errorw("Failed to read storage", "error", err)
 		return err
 	}
 	
@@ -78,14 +112,14 @@ func Set(provider string, info AuthInfo) error {
 			"access":  auth.AccessToken,
 			"expires": auth.ExpiresAt.Unix(),
 		}
-		logger.Info("Created auth map for OAuth", "auth_type", auth.AuthType)
+		logger.Infow("Created auth map for OAuth", "auth_type", auth.AuthType)
 	default:
 		return fmt.Errorf("unknown auth type")
 	}
 	
 	data[provider] = authMap
 	
-	logger.Info("Writing storage data", "providers", len(data))
+	logger.Infow("Writing storage data", "providers", len(data))
 	return writeStorage(data)
 }
 
@@ -129,6 +163,10 @@ func All() (map[string]AuthInfo, error) {
 // Helper functions
 
 func readStorage() (Storage, error) {
+	if storageFile == "" {
+		return nil, errStorageNotInitialized
+	}
+
 	file, err := os.Open(storageFile)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -148,28 +186,32 @@ func readStorage() (Storage, error) {
 }
 
 func writeStorage(data Storage) error {
+	if storageFile == "" {
+		return errStorageNotInitialized
+	}
+
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		logger.Error("Failed to marshal storage data", "error", err)
+		logger.Errorw("Failed to marshal storage data", "error", err)
 		return err
 	}
 	
-	logger.Info("Writing auth data", "size", len(jsonData), "file", storageFile)
+	logger.Infow("Writing auth data", "size", len(jsonData), "file", storageFile)
 	
 	// Write to temp file first
 	tempFile := storageFile + ".tmp"
 	if err := os.WriteFile(tempFile, jsonData, 0600); err != nil {
-		logger.Error("Failed to write temp file", "tempFile", tempFile, "error", err)
+		logger.Errorw("Failed to write temp file", "tempFile", tempFile, "error", err)
 		return err
 	}
 	
 	// Rename to actual file (atomic operation)
 	if err := os.Rename(tempFile, storageFile); err != nil {
-		logger.Error("Failed to rename temp file", "tempFile", tempFile, "targetFile", storageFile, "error", err)
+		logger.Errorw("Failed to rename temp file", "tempFile", tempFile, "targetFile", storageFile, "error", err)
 		return err
 	}
 	
-	logger.Info("Successfully wrote auth file", "file", storageFile)
+	logger.Infow("Successfully wrote auth file", "file", storageFile)
 	return nil
 }
 
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// resetStorage restores the storage location once a test is done with it
+func resetStorage(t *testing.T) {
+	t.Helper()
+	previous := StorageFile()
+	t.Cleanup(func() {
+		storageMutex.Lock()
+		storageFile = previous
+		storageMutex.Unlock()
+	})
+}
+
+func TestDefaultDataDir(t *testing.T) {
+	tests := []struct {
+		name        string
+		xdgDataHome string
+		home        string
+		want        string
+		wantErr     bool
+	}{
+		{name: "XDG_DATA_HOME", xdgDataHome: "/xdg/data", home: "/home/user", want: "/xdg/data/reapo"},
+		{name: "home directory", home: "/home/user", want: "/home/user/.local/share/reapo"},
+		{name: "no home directory", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_DATA_HOME", tt.xdgDataHome)
+			t.Setenv("HOME", tt.home)
+
+			got, err := DefaultDataDir()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("DefaultDataDir() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DefaultDataDir() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DefaultDataDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitStorage(t *testing.T) {
+	t.Run("explicit directory", func(t *testing.T) {
+		resetStorage(t)
+		dir := filepath.Join(t.TempDir(), "nested", "reapo")
+
+		if err := InitStorage(dir); err != nil {
+			t.Fatalf("InitStorage() error = %v", err)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Fatalf("InitStorage() didn't create %s: %v", dir, err)
+		}
+		if got, want := StorageFile(), filepath.Join(dir, "auth.json"); got != want {
+			t.Errorf("StorageFile() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("XDG_DATA_HOME", func(t *testing.T) {
+		resetStorage(t)
+		dataHome := t.TempDir()
+		t.Setenv("XDG_DATA_HOME", dataHome)
+
+		if err := InitStorage(""); err != nil {
+			t.Fatalf("InitStorage() error = %v", err)
+		}
+		if got, want := StorageFile(), filepath.Join(dataHome, "reapo", "auth.json"); got != want {
+			t.Errorf("StorageFile() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("no home directory", func(t *testing.T) {
+		resetStorage(t)
+		t.Setenv("XDG_DATA_HOME", "")
+		t.Setenv("HOME", "")
+
+		if err := InitStorage(""); err == nil {
+			t.Fatal("InitStorage() succeeded without a home directory")
+		}
+	})
+}
+
+func TestStorageRoundTrip(t *testing.T) {
+	resetStorage(t)
+	if err := InitStorage(t.TempDir()); err != nil {
+		t.Fatalf("InitStorage() error = %v", err)
+	}
+
+	want := &OAuthInfo{
+		AuthType:     AuthTypeOAuth,
+		RefreshToken: "refresh",
+		AccessToken:  "access",
+		ExpiresAt:    time.Unix(1700000000, 0),
+	}
+	if err := Set("anthropic", want); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	got, err := Get("anthropic")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	oauth, ok := got.(*OAuthInfo)
+	if !ok {
+		t.Fatalf("Get() = %T, want *OAuthInfo", got)
+	}
+	if oauth.RefreshToken != want.RefreshToken || oauth.AccessToken != want.AccessToken || !oauth.ExpiresAt.Equal(want.ExpiresAt) {
+		t.Errorf("Get() = %+v, want %+v", oauth, want)
+	}
+
+	info, err := os.Stat(StorageFile())
+	if err != nil {
+		t.Fatalf("auth.json wasn't written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("auth.json mode = %v, want 0600", info.Mode().Perm())
+	}
+}
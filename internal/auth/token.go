@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// tokenTimeout bounds each request to the token endpoint
+const tokenTimeout = 30 * time.Second
+
+// tokenAttempts is how many times a token request is tried before giving up
+// on a network error, rate limit or server error
+const tokenAttempts = 3
+
+// tokenRetryDelay is the wait before the first retry; it doubles after each attempt
+const tokenRetryDelay = time.Second
+
+// tokenClient makes the token requests; unlike http.DefaultClient it never
+// waits forever on a hung connection
+var tokenClient = &http.Client{Timeout: tokenTimeout}
+
+// NetworkError reports that the token endpoint could not be reached
+type NetworkError struct {
+	Op  string // "token exchange" or "token refresh"
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Op, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError reports that the token endpoint answered with an error status
+type StatusError struct {
+	Op         string // "token exchange" or "token refresh"
+	StatusCode int
+	Body       string // Start of the response body, for the logs
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed with status %d", e.Op, e.StatusCode)
+}
+
+// retryable reports whether a failed token request is worth trying again
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var networkErr *NetworkError
+	return errors.As(err, &networkErr)
+}
+
+// requestToken posts reqBody to the token endpoint, retrying transient failures
+func requestToken(op string, reqBody map[string]string) (*TokenResponse, error) {
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	delay := tokenRetryDelay
+	for attempt := 1; ; attempt++ {
+		tokenResp, err := postToken(op, jsonBody)
+		if err == nil || attempt == tokenAttempts || !retryable(err) {
+			return tokenResp, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// postToken makes a single request to the token endpoint
+func postToken(op string, jsonBody []byte) (*TokenResponse, error) {
+	resp, err := tokenClient.Post(tokenURL, "application/json", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, &NetworkError{Op: op, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &StatusError{Op: op, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	var tokenResp TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	return &tokenResp, nil
+}
@@ -0,0 +1,449 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Config holds user-configurable options
+type Config struct {
+	Model               string            `json:"model"`
+	MaxTokens           int64             `json:"max_tokens"`
+	TimeoutSeconds      int               `json:"timeout_seconds"`
+	EnabledTools        []string          `json:"enabled_tools,omitempty"` // Empty means all tools
+	EnableShell         bool              `json:"enable_shell"`
+	TabWidth            int               `json:"tab_width"`                 // Display width of a tab in the input
+	ExpandTab           bool              `json:"expand_tab"`                // Insert spaces instead of a tab in the input
+	AutoIndent          bool              `json:"auto_indent"`               // Carry indentation over to new lines in the input
+	PlainInput          bool              `json:"plain_input"`               // Enter sends and the input has no vim modes
+	KeyHints            bool              `json:"key_hints"`                 // Show the keys for the current input mode below the input
+	InterruptOnType     bool              `json:"interrupt_on_type"`         // Typing while a response is generated cancels it
+	ConfirmRequests     bool              `json:"confirm_requests"`          // Ask before each API request, showing its estimated tokens and cost
+	Keymap              Keymap            `json:"keymap,omitempty"`          // Overrides for DefaultKeymap
+	LogFormat           string            `json:"log_format,omitempty"`      // "text" (default) or "json" for the main log
+	MaxToolResultTokens int               `json:"max_tool_result_tokens"`    // Longer tool results are truncated; 0 disables the cap
+	AutosaveSeconds     int               `json:"autosave_seconds"`          // How often the conversation is saved for crash recovery; 0 disables it
+	FormatCommands      map[string]string `json:"format_commands,omitempty"` // Formatter run on edited files by extension, e.g. {".go": "gofmt -w"}
+	VerifyCommand       string            `json:"verify_command,omitempty"`  // Run after a turn with edits, e.g. "go build ./..."; failures are sent back to the model
+	VerifyMaxAttempts   int               `json:"verify_max_attempts"`       // Automatic fix attempts per message when verify_command fails
+	InterpolateEnv      bool              `json:"interpolate_env"`           // Replace ${VAR} in @-referenced files with environment variables
+	ContextBudgetTokens int               `json:"context_budget_tokens"`     // The oldest unpinned messages are left out of requests estimated above this; 0 disables trimming
+	BaseURL             string            `json:"base_url,omitempty"`        // API endpoint, e.g. a proxy or gateway; ANTHROPIC_BASE_URL takes precedence
+	APIHeaders          map[string]string `json:"api_headers,omitempty"`     // Extra headers sent with every API request
+	CommandAliases      map[string]string `json:"command_aliases,omitempty"` // Extra names for slash commands, e.g. {"/x": "/quit"}
+	Effort              string            `json:"effort,omitempty"`          // Reasoning effort: "low", "medium" or "high" thinking budget; empty for no extended thinking
+	ProjectDefaults     bool              `json:"project_defaults"`          // Opt in to format and verify commands suited to the detected project type when none are configured
+}
+
+// Entry is a single displayable configuration key/value pair
+type Entry struct {
+	Key      string
+	Value    string
+	Settable bool // Whether the option can be changed at runtime
+}
+
+// EffortLevels are the values the effort option accepts besides "" (off)
+var EffortLevels = []string{"low", "medium", "high"}
+
+// VerifyOff is the verify_command value that turns verification off, even
+// where project_defaults would pick a command for an unset one
+const VerifyOff = "off"
+
+var (
+	current    = Default()
+	configPath string
+	mu         sync.RWMutex
+)
+
+// Default returns the built-in configuration
+func Default() Config {
+	return Config{
+		Model:               "claude-sonnet-4-20250514",
+		MaxTokens:           8192,
+		TimeoutSeconds:      60,
+		TabWidth:            4,
+		KeyHints:            true,
+		MaxToolResultTokens: 25000,
+		AutosaveSeconds:     30,
+		VerifyMaxAttempts:   3,
+	}
+}
+
+// DefaultPath returns the config file location.
+// It honors $XDG_CONFIG_HOME and falls back to ~/.config/reapo/config.json.
+func DefaultPath() (string, error) {
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		return filepath.Join(xdgConfigHome, "reapo", "config.json"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".config", "reapo", "config.json"), nil
+}
+
+// Init loads the configuration from path (DefaultPath if empty).
+// A missing file is not an error; defaults are used instead.
+func Init(path string) error {
+	if path == "" {
+		var err error
+		path, err = DefaultPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if err := validateKeymap(cfg.Keymap); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		if cfg.LogFormat != "" && cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+			return fmt.Errorf("invalid config file %s: log_format must be \"text\" or \"json\"", path)
+		}
+		if cfg.Effort != "" && !slices.Contains(EffortLevels, cfg.Effort) {
+			return fmt.Errorf("invalid config file %s: effort must be \"low\", \"medium\" or \"high\"", path)
+		}
+		if cfg.VerifyMaxAttempts < 0 {
+			return fmt.Errorf("invalid config file %s: verify_max_attempts must not be negative", path)
+		}
+		if cfg.ContextBudgetTokens < 0 {
+			return fmt.Errorf("invalid config file %s: context_budget_tokens must not be negative", path)
+		}
+		if cfg.AutosaveSeconds < 0 {
+			return fmt.Errorf("invalid config file %s: autosave_seconds must not be negative", path)
+		}
+		if cfg.BaseURL != "" {
+			if u, err := url.Parse(cfg.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid config file %s: base_url must be an http or https URL", path)
+			}
+		}
+		for name := range cfg.APIHeaders {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("invalid config file %s: api_headers cannot have an empty header name", path)
+			}
+		}
+		for alias, command := range cfg.CommandAliases {
+			if !validCommandName(alias) || !validCommandName(command) {
+				return fmt.Errorf("invalid config file %s: command_aliases maps a name like \"/x\" to a command like \"/quit\"", path)
+			}
+		}
+		for ext, command := range cfg.FormatCommands {
+			if !strings.HasPrefix(ext, ".") || strings.TrimSpace(command) == "" {
+				return fmt.Errorf("invalid config file %s: format_commands maps an extension like \".go\" to a command", path)
+			}
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	current = cfg
+	configPath = path
+	return nil
+}
+
+// Get returns a copy of the current configuration
+func Get() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	cfg := current
+	cfg.EnabledTools = append([]string(nil), current.EnabledTools...)
+	cfg.Keymap = make(Keymap, len(current.Keymap))
+	for action, keys := range current.Keymap {
+		cfg.Keymap[action] = append([]string(nil), keys...)
+	}
+	cfg.FormatCommands = make(map[string]string, len(current.FormatCommands))
+	for ext, command := range current.FormatCommands {
+		cfg.FormatCommands[ext] = command
+	}
+	cfg.APIHeaders = make(map[string]string, len(current.APIHeaders))
+	for name, value := range current.APIHeaders {
+		cfg.APIHeaders[name] = value
+	}
+	cfg.CommandAliases = make(map[string]string, len(current.CommandAliases))
+	for alias, command := range current.CommandAliases {
+		cfg.CommandAliases[alias] = command
+	}
+	return cfg
+}
+
+// Path returns the config file path, or an empty string if Init was not called
+func Path() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return configPath
+}
+
+// Set overrides a runtime-settable option for the current session
+func Set(key, value string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	switch key {
+	case "model":
+		if value == "" {
+			return fmt.Errorf("model cannot be empty")
+		}
+		current.Model = value
+	case "max_tokens":
+		maxTokens, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || maxTokens <= 0 {
+			return fmt.Errorf("max_tokens must be a positive integer")
+		}
+		current.MaxTokens = maxTokens
+	case "timeout_seconds":
+		timeout, err := strconv.Atoi(value)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("timeout_seconds must be a positive integer")
+		}
+		current.TimeoutSeconds = timeout
+	case "tab_width":
+		tabWidth, err := strconv.Atoi(value)
+		if err != nil || tabWidth <= 0 {
+			return fmt.Errorf("tab_width must be a positive integer")
+		}
+		current.TabWidth = tabWidth
+	case "expand_tab":
+		expandTab, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("expand_tab must be true or false")
+		}
+		current.ExpandTab = expandTab
+	case "auto_indent":
+		autoIndent, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("auto_indent must be true or false")
+		}
+		current.AutoIndent = autoIndent
+	case "plain_input":
+		plainInput, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("plain_input must be true or false")
+		}
+		current.PlainInput = plainInput
+	case "interrupt_on_type":
+		interrupt, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("interrupt_on_type must be true or false")
+		}
+		current.InterruptOnType = interrupt
+	case "project_defaults":
+		projectDefaults, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("project_defaults must be true or false")
+		}
+		current.ProjectDefaults = projectDefaults
+	case "confirm_requests":
+		confirm, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("confirm_requests must be true or false")
+		}
+		current.ConfirmRequests = confirm
+	case "key_hints":
+		keyHints, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("key_hints must be true or false")
+		}
+		current.KeyHints = keyHints
+	case "max_tool_result_tokens":
+		maxTokens, err := strconv.Atoi(value)
+		if err != nil || maxTokens < 0 {
+			return fmt.Errorf("max_tool_result_tokens must be a non-negative integer (0 disables the cap)")
+		}
+		current.MaxToolResultTokens = maxTokens
+	case "context_budget_tokens":
+		budget, err := strconv.Atoi(value)
+		if err != nil || budget < 0 {
+			return fmt.Errorf("context_budget_tokens must be a non-negative integer (0 disables trimming)")
+		}
+		current.ContextBudgetTokens = budget
+	case "interpolate_env":
+		interpolateEnv, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("interpolate_env must be true or false")
+		}
+		current.InterpolateEnv = interpolateEnv
+	case "verify_command":
+		current.VerifyCommand = value
+	case "effort":
+		if value == "off" {
+			value = ""
+		}
+		if value != "" && !slices.Contains(EffortLevels, value) {
+			return fmt.Errorf("effort must be low, medium, high or off")
+		}
+		current.Effort = value
+	case "verify_max_attempts":
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts < 0 {
+			return fmt.Errorf("verify_max_attempts must be a non-negative integer")
+		}
+		current.VerifyMaxAttempts = attempts
+	default:
+		return fmt.Errorf("unknown or read-only option: %s", key)
+	}
+	return nil
+}
+
+// Save writes the current configuration back to the config file
+func Save() error {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if configPath == "" {
+		return fmt.Errorf("config not initialized")
+	}
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return os.WriteFile(configPath, data, 0644)
+}
+
+// Entries returns the effective configuration as displayable key/value pairs
+func Entries() []Entry {
+	cfg := Get()
+
+	enabledTools := "all"
+	if len(cfg.EnabledTools) > 0 {
+		enabledTools = strings.Join(cfg.EnabledTools, ", ")
+	}
+
+	logFormat := cfg.LogFormat
+	if logFormat == "" {
+		logFormat = "text"
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "default"
+	}
+
+	effort := cfg.Effort
+	if effort == "" {
+		effort = "off"
+	}
+
+	verifyCommand := cfg.VerifyCommand
+	if verifyCommand == "" && cfg.ProjectDefaults {
+		verifyCommand = "project default"
+	} else if verifyCommand == "" {
+		verifyCommand = VerifyOff
+	}
+
+	return []Entry{
+		{Key: "model", Value: cfg.Model, Settable: true},
+		{Key: "max_tokens", Value: strconv.FormatInt(cfg.MaxTokens, 10), Settable: true},
+		{Key: "timeout_seconds", Value: strconv.Itoa(cfg.TimeoutSeconds), Settable: true},
+		{Key: "tab_width", Value: strconv.Itoa(cfg.TabWidth), Settable: true},
+		{Key: "expand_tab", Value: strconv.FormatBool(cfg.ExpandTab), Settable: true},
+		{Key: "auto_indent", Value: strconv.FormatBool(cfg.AutoIndent), Settable: true},
+		{Key: "plain_input", Value: strconv.FormatBool(cfg.PlainInput), Settable: true},
+		{Key: "key_hints", Value: strconv.FormatBool(cfg.KeyHints), Settable: true},
+		{Key: "interrupt_on_type", Value: strconv.FormatBool(cfg.InterruptOnType), Settable: true},
+		{Key: "confirm_requests", Value: strconv.FormatBool(cfg.ConfirmRequests), Settable: true},
+		{Key: "max_tool_result_tokens", Value: strconv.Itoa(cfg.MaxToolResultTokens), Settable: true},
+		{Key: "interpolate_env", Value: strconv.FormatBool(cfg.InterpolateEnv), Settable: true},
+		{Key: "context_budget_tokens", Value: strconv.Itoa(cfg.ContextBudgetTokens), Settable: true},
+		{Key: "effort", Value: effort, Settable: true},
+		{Key: "verify_command", Value: verifyCommand, Settable: true},
+		{Key: "verify_max_attempts", Value: strconv.Itoa(cfg.VerifyMaxAttempts), Settable: true},
+		{Key: "project_defaults", Value: strconv.FormatBool(cfg.ProjectDefaults), Settable: true},
+		{Key: "enabled_tools", Value: enabledTools},
+		{Key: "enable_shell", Value: strconv.FormatBool(cfg.EnableShell)},
+		{Key: "keymap", Value: formatKeymap(cfg.Keymap)},
+		{Key: "log_format", Value: logFormat},
+		{Key: "autosave_seconds", Value: strconv.Itoa(cfg.AutosaveSeconds)},
+		{Key: "format_commands", Value: formatCommands(cfg.FormatCommands)},
+		{Key: "base_url", Value: baseURL},
+		{Key: "api_headers", Value: headerNames(cfg.APIHeaders)},
+		{Key: "command_aliases", Value: formatCommands(cfg.CommandAliases)},
+	}
+}
+
+// ToolEnabled reports whether the named tool is enabled by the configuration
+func (c Config) ToolEnabled(name string) bool {
+	if len(c.EnabledTools) == 0 {
+		return true
+	}
+	for _, tool := range c.EnabledTools {
+		if tool == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fileNamePattern matches names made of letters, digits, '.', '_' and '-'
+// that don't start with punctuation
+var fileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidFileName reports whether name can name a file kept next to the config
+// file, like a prompt template or persona, without leaving its directory
+func ValidFileName(name string) bool {
+	return fileNamePattern.MatchString(name)
+}
+
+// validCommandName reports whether name looks like a slash command: a slash
+// followed by a word with no spaces
+func validCommandName(name string) bool {
+	return len(name) > 1 && strings.HasPrefix(name, "/") && !strings.ContainsAny(name, " \t\n")
+}
+
+// formatCommands renders a map option like format_commands for display
+func formatCommands(commands map[string]string) string {
+	if len(commands) == 0 {
+		return "none"
+	}
+
+	exts := make([]string, 0, len(commands))
+	for ext := range commands {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	entries := make([]string, 0, len(exts))
+	for _, ext := range exts {
+		entries = append(entries, ext+"="+commands[ext])
+	}
+	return strings.Join(entries, ", ")
+}
+
+// headerNames lists the configured API headers by name only, since their
+// values are often credentials
+func headerNames(headers map[string]string) string {
+	if len(headers) == 0 {
+		return "none"
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Logical actions that can be bound to keys in the "keymap" config option
+const (
+	ActionSend           = "send"            // Send the input from Normal mode
+	ActionSendInsert     = "send_insert"     // Send the input from Insert or Visual mode
+	ActionQuit           = "quit"            // Exit the application
+	ActionCommandPalette = "command_palette" // Open the command palette
+	ActionSwitchModel    = "switch_model"    // Switch to the previously used model
+	ActionSelectMessage  = "select_message"  // Select an earlier message to quote
+	ActionUndoClear      = "undo_clear"      // Restore the conversation removed by /clear
+	ActionAbortTool      = "abort_tool"      // Abort the longest-running tool call
+	ActionRedraw         = "redraw"          // Clear the terminal and redraw the screen
+	ActionFind           = "find"            // Search the chat for text
+	ActionCycleFocus     = "cycle_focus"     // Move the keyboard focus between the input (in Normal mode) and the chat
+	ActionToggleVerbose  = "toggle_verbose"  // Show or hide full tool inputs and outputs
+	ActionToggleScratch  = "toggle_scratch"  // Switch between the input and the scratch buffer
+)
+
+// Keymap maps logical actions to the keys that trigger them.
+// Keys use Bubble Tea's names, e.g. "enter", "ctrl+s" or "f2".
+type Keymap map[string][]string
+
+// DefaultKeymap returns the built-in key bindings
+func DefaultKeymap() Keymap {
+	return Keymap{
+		ActionSend:           {"enter"},
+		ActionSendInsert:     {"ctrl+s"},
+		ActionQuit:           {"ctrl+c"},
+		ActionCommandPalette: {"ctrl+p"},
+		ActionSwitchModel:    {"f2"},
+		ActionSelectMessage:  {"ctrl+k"},
+		ActionUndoClear:      {"ctrl+z"},
+		ActionAbortTool:      {"ctrl+x"},
+		ActionRedraw:         {"ctrl+l"},
+		ActionFind:           {"ctrl+f"},
+		ActionCycleFocus:     {"tab"},
+		ActionToggleVerbose:  {"ctrl+o"},
+		ActionToggleScratch:  {"ctrl+n"},
+	}
+}
+
+// ResolvedKeymap returns the default bindings with the configured overrides applied.
+// An action bound in the config replaces its default keys rather than adding to them.
+func (c Config) ResolvedKeymap() Keymap {
+	keymap := DefaultKeymap()
+	for action, keys := range c.Keymap {
+		if _, ok := keymap[action]; ok {
+			keymap[action] = append([]string(nil), keys...)
+		}
+	}
+	return keymap
+}
+
+// Matches reports whether key is bound to action
+func (k Keymap) Matches(action, key string) bool {
+	for _, bound := range k[action] {
+		if bound == key {
+			return true
+		}
+	}
+	return false
+}
+
+// validateKeymap rejects bindings for actions that don't exist
+func validateKeymap(keymap map[string][]string) error {
+	defaults := DefaultKeymap()
+	for action := range keymap {
+		if _, ok := defaults[action]; !ok {
+			return fmt.Errorf("unknown keymap action: %s", action)
+		}
+	}
+	return nil
+}
+
+// formatKeymap describes the configured overrides for display
+func formatKeymap(keymap map[string][]string) string {
+	if len(keymap) == 0 {
+		return "default"
+	}
+
+	actions := make([]string, 0, len(keymap))
+	for action := range keymap {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	bindings := make([]string, 0, len(actions))
+	for _, action := range actions {
+		bindings = append(bindings, action+"="+strings.Join(keymap[action], "|"))
+	}
+	return strings.Join(bindings, ", ")
+}
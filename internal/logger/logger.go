@@ -1,17 +1,21 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 )
 
 var (
 	instance *Logger
 	once     sync.Once
+	logsDir  string // Absolute path of the logs directory, set by Init
 )
 
 // Logger provides TUI-safe logging functionality
@@ -20,9 +24,21 @@ type Logger struct {
 	chatLogger *log.Logger
 	logFile    *os.File
 	chatFile   *os.File
+	json       bool // Write the main log as JSON lines instead of "[LEVEL] message"
 	mu         sync.Mutex
 }
 
+// logFlags are the standard log flags used by the text format
+const logFlags = log.LstdFlags | log.Lshortfile
+
+// logEntry is one line of the main log in JSON format
+type logEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
 // Init initializes the global logger instance
 func Init() error {
 	var err error
@@ -34,8 +50,13 @@ func Init() error {
 
 // newLogger creates a new logger instance
 func newLogger() (*Logger, error) {
-	// Create logs directory if it doesn't exist
-	logsDir := "logs"
+	// Create logs directory if it doesn't exist. It is resolved now so that
+	// changing the working directory later (--cwd) doesn't move it.
+	dir, err := filepath.Abs("logs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve logs directory: %w", err)
+	}
+	logsDir = dir
 	if err := os.MkdirAll(logsDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create logs directory: %w", err)
 	}
@@ -56,8 +77,8 @@ func newLogger() (*Logger, error) {
 	}
 
 	// Create loggers
-	fileLogger := log.New(logFile, "", log.LstdFlags|log.Lshortfile)
-	chatLogger := log.New(chatFile, "", log.LstdFlags)
+	fileLogger := log.New(logFile, "", logFlags)
+	chatLogger := log.New(chatFile, "", 0) // Entries are JSON lines with their own timestamp
 
 	return &Logger{
 		fileLogger: fileLogger,
@@ -67,6 +88,11 @@ func newLogger() (*Logger, error) {
 	}, nil
 }
 
+// Dir returns the directory the log files are written to, or an empty string if Init was not called
+func Dir() string {
+	return logsDir
+}
+
 // Info logs an info message
 func Info(format string, args ...interface{}) {
 	if instance != nil {
@@ -88,6 +114,42 @@ func Debug(format string, args ...interface{}) {
 	}
 }
 
+// Infow logs an info message with key/value fields, e.g. Infow("Saved", "path", path)
+func Infow(message string, keyvals ...interface{}) {
+	if instance != nil {
+		instance.write("INFO", message, fieldsFrom(keyvals))
+	}
+}
+
+// Errorw logs an error message with key/value fields
+func Errorw(message string, keyvals ...interface{}) {
+	if instance != nil {
+		instance.write("ERROR", message, fieldsFrom(keyvals))
+	}
+}
+
+// Debugw logs a debug message with key/value fields
+func Debugw(message string, keyvals ...interface{}) {
+	if instance != nil {
+		instance.write("DEBUG", message, fieldsFrom(keyvals))
+	}
+}
+
+// SetJSON switches the main log between "[LEVEL] message" lines and JSON lines
+// with time, level, message and fields, for shipping to a log collector
+func SetJSON(enabled bool) {
+	if instance != nil {
+		instance.mu.Lock()
+		defer instance.mu.Unlock()
+		instance.json = enabled
+		if enabled {
+			instance.fileLogger.SetFlags(0) // Entries carry their own timestamp
+		} else {
+			instance.fileLogger.SetFlags(logFlags)
+		}
+	}
+}
+
 // Tool logs a tool execution message
 func Tool(name string, input string) {
 	if instance != nil {
@@ -95,39 +157,105 @@ func Tool(name string, input string) {
 	}
 }
 
-// Chat logs conversation data to the dedicated chat log file
-func Chat(event string, data interface{}) {
+// chatEntry is one line of the chat log
+type chatEntry struct {
+	Time      time.Time   `json:"time"`
+	Event     string      `json:"event"`
+	RequestID string      `json:"request_id,omitempty"`
+	Data      interface{} `json:"data"`
+}
+
+// Chat logs conversation data to the dedicated chat log file as a JSON line.
+// requestID ties a response or error to the request it answers.
+func Chat(event, requestID string, data interface{}) {
 	if instance != nil {
-		instance.chatLog(event, data)
+		instance.chatLog(event, requestID, data)
 	}
 }
 
 // log writes a formatted message to the main log file
 func (l *Logger) log(level, format string, args ...interface{}) {
+	l.write(level, fmt.Sprintf(format, args...), nil)
+}
+
+// write writes a message and its fields to the main log file in the configured format
+func (l *Logger) write(level, message string, fields map[string]interface{}) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	message := fmt.Sprintf(format, args...)
+	if l.json {
+		line, err := json.Marshal(logEntry{Time: time.Now(), Level: level, Message: message, Fields: fields})
+		if err == nil {
+			l.fileLogger.Print(string(line))
+			return
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		message += fmt.Sprintf(" %s=%v", key, fields[key])
+	}
 	l.fileLogger.Printf("[%s] %s", level, message)
 }
 
+// fieldsFrom pairs up alternating keys and values. Errors are stored as their
+// message so they survive JSON encoding; a trailing key without a value is kept.
+func fieldsFrom(keyvals []interface{}) map[string]interface{} {
+	if len(keyvals) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 >= len(keyvals) {
+			fields[key] = nil
+			break
+		}
+		value := keyvals[i+1]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
 // chatLog writes conversation data to the chat log file
-func (l *Logger) chatLog(event string, data interface{}) {
+func (l *Logger) chatLog(event, requestID string, data interface{}) {
+	entry := chatEntry{Time: time.Now(), Event: event, RequestID: requestID, Data: data}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		// Keep the entry even if the data can't be encoded
+		entry.Data = fmt.Sprintf("%+v", data)
+		line, _ = json.Marshal(entry)
+	}
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
-
-	l.chatLogger.Printf("[%s] %+v", event, data)
+	l.chatLogger.Print(string(line))
 }
 
-// Close closes both log files
+// Close flushes and closes both log files. It is safe to call more than once.
 func Close() error {
 	if instance != nil {
+		instance.mu.Lock()
+		defer instance.mu.Unlock()
+
 		var err1, err2 error
 		if instance.logFile != nil {
+			instance.logFile.Sync()
 			err1 = instance.logFile.Close()
+			instance.logFile = nil
 		}
 		if instance.chatFile != nil {
+			instance.chatFile.Sync()
 			err2 = instance.chatFile.Close()
+			instance.chatFile = nil
 		}
 		if err1 != nil {
 			return err1
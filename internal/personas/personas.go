@@ -0,0 +1,82 @@
+package personas
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"reapo/internal/config"
+)
+
+// Default is the persona that uses the built-in system prompt
+const Default = "default"
+
+// fileExt is the extension used for persona prompt files
+const fileExt = ".md"
+
+// Dir returns the persona directory, a "personas" directory next to the config file
+// (~/.config/reapo/personas by default). Each <name>.md file holds a system prompt.
+func Dir() (string, error) {
+	path := config.Path()
+	if path == "" {
+		var err error
+		path, err = config.DefaultPath()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(filepath.Dir(path), "personas"), nil
+}
+
+// Load returns the system prompt of the named persona
+func Load(name string) (string, error) {
+	if !config.ValidFileName(name) {
+		return "", fmt.Errorf("invalid persona name %q", name)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name+fileExt))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no persona named %q (add %s)", name, filepath.Join(dir, name+fileExt))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read persona: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("persona %q is empty", name)
+	}
+	return string(data), nil
+}
+
+// List returns the names of all personas, including the default, in alphabetical order
+func List() ([]string, error) {
+	names := []string{Default}
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read personas directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), fileExt)
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), fileExt) && name != Default {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,91 @@
+package project
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Type is a kind of project, named after its primary language, with the
+// commands that suit it when format_commands and verify_command aren't set
+type Type struct {
+	Name           string            // e.g. "Go"; empty when the type is unknown
+	Marker         string            // File that identified the project, e.g. "go.mod"
+	FormatCommands map[string]string // Formatter by file extension, like format_commands
+	VerifyCommand  string            // Checks the project builds, like verify_command
+}
+
+// Current is the type of the project in the working directory, set at startup
+var Current Type
+
+// known lists the project types in detection order. Markers of the more
+// specific types come first, so a TypeScript project isn't taken for plain
+// JavaScript.
+var known = []struct {
+	name           string
+	markers        []string
+	formatCommands map[string]string
+	verifyCommand  string
+}{
+	{name: "Go", markers: []string{"go.mod"}, formatCommands: map[string]string{".go": "gofmt -w"}, verifyCommand: "go build ./..."},
+	{name: "Rust", markers: []string{"Cargo.toml"}, formatCommands: map[string]string{".rs": "rustfmt"}, verifyCommand: "cargo check --quiet"},
+	{name: "TypeScript", markers: []string{"tsconfig.json"}, formatCommands: map[string]string{".ts": "prettier --write", ".tsx": "prettier --write"}, verifyCommand: "tsc --noEmit"},
+	{name: "JavaScript", markers: []string{"package.json"}, formatCommands: map[string]string{".js": "prettier --write", ".jsx": "prettier --write"}},
+	{name: "Python", markers: []string{"pyproject.toml", "setup.py", "requirements.txt"}, formatCommands: map[string]string{".py": "black -q"}},
+	{name: "Java", markers: []string{"pom.xml", "build.gradle", "build.gradle.kts"}},
+	{name: "Ruby", markers: []string{"Gemfile"}},
+	{name: "Elixir", markers: []string{"mix.exs"}, formatCommands: map[string]string{".ex": "mix format", ".exs": "mix format"}, verifyCommand: "mix compile"},
+}
+
+// Detect identifies the project in dir from the build files at its top
+// level. Commands whose programs aren't installed are left out, so a default
+// never fails just for being missing.
+func Detect(dir string) Type {
+	for _, kind := range known {
+		for _, marker := range kind.markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err != nil {
+				continue
+			}
+
+			detected := Type{Name: kind.name, Marker: marker, FormatCommands: map[string]string{}}
+			for ext, command := range kind.formatCommands {
+				if installed(command) {
+					detected.FormatCommands[ext] = command
+				}
+			}
+			if installed(kind.verifyCommand) {
+				detected.VerifyCommand = kind.verifyCommand
+			}
+			return detected
+		}
+	}
+	return Type{}
+}
+
+// installed reports whether the program a command runs is on the PATH
+func installed(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return false
+	}
+	_, err := exec.LookPath(fields[0])
+	return err == nil
+}
+
+// String describes the project type for display, e.g. "Go (go.mod)"
+func (t Type) String() string {
+	if t.Name == "" {
+		return "unknown"
+	}
+	return t.Name + " (" + t.Marker + ")"
+}
+
+// PromptNote tells the model what kind of project it is working in, to add
+// to the system prompt, or returns "" when the type is unknown
+func (t Type) PromptNote() string {
+	if t.Name == "" {
+		return ""
+	}
+	return "\n\nThe project in the working directory is written in " + t.Name + " (it has " + t.Marker + ")."
+}
@@ -0,0 +1,91 @@
+package prompts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"reapo/internal/config"
+)
+
+// fileExt is the extension used for template files
+const fileExt = ".md"
+
+// Dir returns the template directory, a "prompts" directory next to the config file
+// (~/.config/reapo/prompts by default)
+func Dir() (string, error) {
+	path := config.Path()
+	if path == "" {
+		var err error
+		path, err = config.DefaultPath()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(filepath.Dir(path), "prompts"), nil
+}
+
+// Save writes content as the named template, replacing any existing one
+func Save(name, content string) error {
+	if !config.ValidFileName(name) {
+		return fmt.Errorf("invalid template name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create prompts directory: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(dir, name+fileExt), []byte(content), 0644)
+}
+
+// Load returns the contents of the named template
+func Load(name string) (string, error) {
+	if !config.ValidFileName(name) {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name+fileExt))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no prompt template named %q", name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt template: %w", err)
+	}
+	return string(data), nil
+}
+
+// List returns the names of all saved templates in alphabetical order
+func List() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompts directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), fileExt) {
+			names = append(names, strings.TrimSuffix(entry.Name(), fileExt))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,70 @@
+package references
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// MaxDocumentSize caps the size of a file attached as a document
+const MaxDocumentSize = 10 << 20 // 10 MiB
+
+// IsDocument reports whether a path reference is attached as a document
+// block instead of being read as text. Only PDFs are supported.
+func (r Reference) IsDocument() bool {
+	return !r.IsCommand && strings.EqualFold(filepath.Ext(r.Target), ".pdf")
+}
+
+// Document reads a referenced PDF relative to workingDir as a base64 document block
+func (r Reference) Document(workingDir string) (anthropic.ContentBlockParamUnion, error) {
+	fullPath := filepath.Join(workingDir, r.Target)
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return anthropic.ContentBlockParamUnion{}, err
+	}
+	if info.Size() > MaxDocumentSize {
+		return anthropic.ContentBlockParamUnion{}, fmt.Errorf("%s is %d KiB, over the %d KiB document limit", r.Target, info.Size()>>10, MaxDocumentSize>>10)
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return anthropic.ContentBlockParamUnion{}, err
+	}
+
+	block := anthropic.NewDocumentBlock(anthropic.Base64PDFSourceParam{
+		Data: base64.StdEncoding.EncodeToString(data),
+	})
+	block.OfDocument.Title = anthropic.String(r.Target)
+	return block, nil
+}
+
+// IsBinary reports whether a referenced file looks like binary data that
+// can't usefully be read as text. Only the start of the file is checked.
+func (r Reference) IsBinary(workingDir string) bool {
+	file, err := os.Open(filepath.Join(workingDir, r.Target))
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	head := make([]byte, 8000)
+	n, _ := file.Read(head)
+	head = head[:n]
+
+	// Trim a multi-byte rune cut off at the end so it isn't mistaken for invalid UTF-8
+	for i := 0; i < utf8.UTFMax && len(head) > 0 && !utf8.Valid(head); i++ {
+		head = head[:len(head)-1]
+	}
+	return bytes.IndexByte(head, 0) >= 0 || !utf8.Valid(head)
+}
+
+// UnsupportedMessage explains to the model why a referenced file was not included
+func UnsupportedMessage(path string) string {
+	return fmt.Sprintf("%s was not attached: it is a binary file, and only text files and PDFs are supported", path)
+}
@@ -0,0 +1,27 @@
+package references
+
+import (
+	"os"
+	"regexp"
+
+	"reapo/internal/logger"
+)
+
+// envPlaceholder matches ${NAME} placeholders. The bare $NAME form is left
+// alone since it is too common in shell scripts and templates.
+var envPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Interpolate replaces ${NAME} placeholders in the contents of a referenced
+// file with environment variables. Undefined variables are left intact and
+// logged, since they may be literal text rather than placeholders.
+func Interpolate(text, path string) string {
+	return envPlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := envPlaceholder.FindStringSubmatch(placeholder)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			logger.Infow("Leaving undefined environment variable in referenced file", "variable", name, "path", path)
+			return placeholder
+		}
+		return value
+	})
+}
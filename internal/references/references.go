@@ -0,0 +1,287 @@
+package references
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"reapo/internal/config"
+	"reapo/internal/tools"
+)
+
+// Reference is a single @path or @!command reference found in text
+type Reference struct {
+	Target    string // File or directory path, or the command for @!command
+	IsCommand bool   // Whether this is an @!command reference
+	StartLine int    // First line of a @path:start-end range, 0 for the whole file
+	EndLine   int    // Last line of the range, inclusive
+	badRange  string // A :start-end suffix that isn't a valid range
+	start     int    // Rune offset of the @
+	end       int    // Rune offset just past the reference
+}
+
+// lineRangeSuffix matches a path ending in a :start-end line range
+var lineRangeSuffix = regexp.MustCompile(`^(.+):(\d+)-(\d+)$`)
+
+// Kind is what a reference points at
+type Kind int
+
+const (
+	File Kind = iota
+	Directory
+	Command
+)
+
+// Parse returns the references in text, in order. A path reference runs to
+// the next whitespace and may end in a :start-end line range, an @!command
+// reference runs to the end of the line, and an escaped \@ is not a reference.
+func Parse(text string) []Reference {
+	var refs []Reference
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == '@':
+			// Skip escaped @
+			i++
+		case runes[i] == '@':
+			start := i + 1
+			end := start
+
+			if end < len(runes) && runes[end] == '!' {
+				// @!command runs to the end of the line
+				for end < len(runes) && runes[end] != '\n' {
+					end++
+				}
+			} else {
+				// Find word boundary or whitespace (but allow / and . in filenames)
+				for end < len(runes) && !isWhitespace(runes[end]) {
+					end++
+				}
+			}
+
+			if end > start {
+				ref := Reference{Target: string(runes[start:end]), start: i, end: end}
+				if command, ok := strings.CutPrefix(ref.Target, "!"); ok {
+					ref.Target = command
+					ref.IsCommand = true
+				} else {
+					ref = ref.withLineRange()
+				}
+				refs = append(refs, ref)
+
+				// Skip past the reference
+				i = end - 1
+			}
+		}
+	}
+
+	return refs
+}
+
+// withLineRange splits a :start-end line range off the reference's path.
+// A range that can't be valid is remembered so LineRange can warn about it.
+func (r Reference) withLineRange() Reference {
+	match := lineRangeSuffix.FindStringSubmatch(r.Target)
+	if match == nil {
+		return r
+	}
+
+	r.Target = match[1]
+	start, startErr := strconv.Atoi(match[2])
+	end, endErr := strconv.Atoi(match[3])
+	if startErr != nil || endErr != nil || start < 1 || end < start {
+		r.badRange = match[2] + "-" + match[3]
+		return r
+	}
+	r.StartLine, r.EndLine = start, end
+	return r
+}
+
+// LineRange returns the lines of the referenced file to read, or 0, 0 for
+// the whole file. A range that is invalid, or starts past the end of the
+// file, is ignored with a warning; one that ends past it reads to the end.
+func (r Reference) LineRange(workingDir string) (start, end int, warning string) {
+	if r.badRange != "" {
+		return 0, 0, fmt.Sprintf("Invalid line range %s in @%s, including the whole file", r.badRange, r.Target)
+	}
+	if r.StartLine == 0 {
+		return 0, 0, ""
+	}
+
+	content, err := os.ReadFile(filepath.Join(workingDir, r.Target))
+	if err != nil {
+		// Reading the file reports the error
+		return r.StartLine, r.EndLine, ""
+	}
+	if _, err := tools.LineRange(string(content), r.StartLine, r.EndLine); err != nil {
+		// The range itself was checked by Parse, so it starts past the end
+		return 0, 0, fmt.Sprintf("Line range %d-%d is past the end of @%s, including the whole file", r.StartLine, r.EndLine, r.Target)
+	}
+	return r.StartLine, r.EndLine, ""
+}
+
+// Resolve reports what the reference points at relative to workingDir.
+// It returns an error if a path reference cannot be accessed.
+func (r Reference) Resolve(workingDir string) (Kind, error) {
+	if r.IsCommand {
+		return Command, nil
+	}
+
+	info, err := os.Stat(filepath.Join(workingDir, r.Target))
+	if err != nil {
+		return File, err
+	}
+	if info.IsDir() {
+		return Directory, nil
+	}
+	return File, nil
+}
+
+// Size returns the size in bytes of a referenced file, or 0 for directories,
+// commands and paths that cannot be accessed. It lets callers estimate how
+// much a reference will add without reading it.
+func (r Reference) Size(workingDir string) int64 {
+	if r.IsCommand {
+		return 0
+	}
+
+	info, err := os.Stat(filepath.Join(workingDir, r.Target))
+	if err != nil || info.IsDir() {
+		return 0
+	}
+
+	if start, end, _ := r.LineRange(workingDir); start != 0 {
+		content, err := os.ReadFile(filepath.Join(workingDir, r.Target))
+		if err != nil {
+			return 0
+		}
+		lines, _ := tools.LineRange(string(content), start, end)
+		return int64(len(lines))
+	}
+	return info.Size()
+}
+
+// Expand replaces @path references with the contents of the file (or the
+// listing of the directory) relative to workingDir, and @!command references
+// with the command's output. An escaped \@ becomes a literal @.
+func Expand(text, workingDir string) string {
+	var result strings.Builder
+	runes := []rune(text)
+	refs := Parse(text)
+
+	for i := 0; i < len(runes); i++ {
+		if len(refs) > 0 && i == refs[0].start {
+			result.WriteString(refs[0].contents(workingDir))
+			i = refs[0].end - 1
+			refs = refs[1:]
+			continue
+		}
+
+		if runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == '@' {
+			// Handle escaped @: \@ becomes @
+			i++
+		}
+		result.WriteRune(runes[i])
+	}
+
+	return result.String()
+}
+
+// contents returns the text a reference expands to
+func (r Reference) contents(workingDir string) string {
+	kind, err := r.Resolve(workingDir)
+	if err != nil {
+		return fmt.Sprintf("Error accessing %s: %v", r.Target, err)
+	}
+
+	fullPath := filepath.Join(workingDir, r.Target)
+	switch kind {
+	case Command:
+		return CommandOutput(context.Background(), r.Target)
+	case Directory:
+		return readDirectoryContents(fullPath, r.Target)
+	default:
+		// Inline expansion is text-only; documents are attached by the TUI
+		if r.IsDocument() {
+			return fmt.Sprintf("%s was not attached: PDF documents can only be attached in the interactive TUI", r.Target)
+		}
+		if r.IsBinary(workingDir) {
+			return UnsupportedMessage(r.Target)
+		}
+		start, end, warning := r.LineRange(workingDir)
+		contents := readFileContents(fullPath, r.Target, start, end)
+		if warning != "" {
+			contents = fmt.Sprintf("(%s)\n%s", warning, contents)
+		}
+		if config.Get().InterpolateEnv {
+			contents = Interpolate(contents, r.Target)
+		}
+		return contents
+	}
+}
+
+// CommandOutput runs an @!command reference through the shell executor and
+// formats its output for the model. Cancelling ctx stops the command.
+func CommandOutput(ctx context.Context, command string) string {
+	output, err := tools.RunShellCommand(ctx, command)
+	if err != nil {
+		return fmt.Sprintf("Output of `%s` (%v):\n```\n%s\n```", command, err, output)
+	}
+	return fmt.Sprintf("Output of `%s`:\n```\n%s\n```", command, output)
+}
+
+// readFileContents reads a file, or lines start to end of it when start
+// isn't 0, as a code block headed by its path
+func readFileContents(fullPath, relativePath string, start, end int) string {
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return fmt.Sprintf("Error reading file %s: %v", relativePath, err)
+	}
+
+	if start != 0 {
+		lines, err := tools.LineRange(string(content), start, end)
+		if err != nil {
+			return fmt.Sprintf("Error reading file %s: %v", relativePath, err)
+		}
+		end = start + strings.Count(strings.TrimSuffix(lines, "\n"), "\n")
+		return fmt.Sprintf("Lines %d-%d of %s:\n```\n%s\n```", start, end, relativePath, lines)
+	}
+
+	// Format as a code block with file path
+	return fmt.Sprintf("Contents of %s:\n```\n%s\n```", relativePath, string(content))
+}
+
+func readDirectoryContents(fullPath, relativePath string) string {
+	entries, err := os.ReadDir(fullPath)
+	if err != nil {
+		return fmt.Sprintf("Error reading directory %s: %v", relativePath, err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Contents of directory %s:\n", relativePath))
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			result.WriteString(fmt.Sprintf("- %s/ (directory)\n", entry.Name()))
+		} else {
+			// Get file info for size
+			info, err := entry.Info()
+			if err == nil {
+				result.WriteString(fmt.Sprintf("- %s (%d bytes)\n", entry.Name(), info.Size()))
+			} else {
+				result.WriteString(fmt.Sprintf("- %s\n", entry.Name()))
+			}
+		}
+	}
+
+	return result.String()
+}
+
+func isWhitespace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
@@ -0,0 +1,213 @@
+package references
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	type ref struct {
+		target    string
+		isCommand bool
+		start     int
+		end       int
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []ref
+	}{
+		{name: "none", text: "no references here", want: nil},
+		{name: "file", text: "look at @main.go please", want: []ref{{target: "main.go"}}},
+		{name: "nested path", text: "@internal/tools/file.go", want: []ref{{target: "internal/tools/file.go"}}},
+		{name: "several", text: "@a.go and @b/ then @c.txt", want: []ref{{target: "a.go"}, {target: "b/"}, {target: "c.txt"}}},
+		{name: "escaped", text: `mail me \@home or see @notes.md`, want: []ref{{target: "notes.md"}}},
+		{name: "only escaped", text: `\@a \@b`, want: nil},
+		{name: "lone @", text: "an @ sign", want: nil},
+		{name: "command", text: "@!git status", want: []ref{{target: "git status", isCommand: true}}},
+		{name: "command ends at newline", text: "@!ls -la\nand @x.go", want: []ref{{target: "ls -la", isCommand: true}, {target: "x.go"}}},
+		{name: "command keeps its own @", text: "@!git log @{u}", want: []ref{{target: "git log @{u}", isCommand: true}}},
+		{name: "line range", text: "@main.go:10-40", want: []ref{{target: "main.go", start: 10, end: 40}}},
+		{name: "single line range", text: "@main.go:7-7", want: []ref{{target: "main.go", start: 7, end: 7}}},
+		{name: "range on a path with colons", text: "@a:b.go:1-2", want: []ref{{target: "a:b.go", start: 1, end: 2}}},
+		{name: "not a range", text: "@main.go:10", want: []ref{{target: "main.go:10"}}},
+		{name: "backwards range", text: "@main.go:40-10", want: []ref{{target: "main.go"}}},
+		{name: "zero start", text: "@main.go:0-5", want: []ref{{target: "main.go"}}},
+		{name: "command with a range-like suffix", text: "@!head file:1-2", want: []ref{{target: "head file:1-2", isCommand: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs := Parse(tt.text)
+			if len(refs) != len(tt.want) {
+				t.Fatalf("Parse(%q) = %+v, want %d references", tt.text, refs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := ref{target: refs[i].Target, isCommand: refs[i].IsCommand, start: refs[i].StartLine, end: refs[i].EndLine}
+				if got != want {
+					t.Errorf("Parse(%q)[%d] = %+v, want %+v", tt.text, i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// writeTree creates files, keyed by slash-separated path, in a new temporary
+// directory and returns it
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestResolve(t *testing.T) {
+	dir := writeTree(t, map[string]string{"main.go": "package main\n", "pkg/util.go": "package pkg\n"})
+
+	tests := []struct {
+		text    string
+		want    Kind
+		wantErr bool
+	}{
+		{text: "@main.go", want: File},
+		{text: "@pkg/util.go", want: File},
+		{text: "@pkg", want: Directory},
+		{text: "@pkg/", want: Directory},
+		{text: "@main.go:1-1", want: File},
+		{text: "@missing.go", want: File, wantErr: true},
+		{text: "@!ls", want: Command},
+	}
+
+	for _, tt := range tests {
+		ref := Parse(tt.text)[0]
+		got, err := ref.Resolve(dir)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Resolve(%q) error = %v, want error %v", tt.text, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLineRange(t *testing.T) {
+	dir := writeTree(t, map[string]string{"five.txt": "1\n2\n3\n4\n5\n"})
+
+	tests := []struct {
+		text        string
+		start, end  int
+		wantWarning string
+	}{
+		{text: "@five.txt"},
+		{text: "@five.txt:2-4", start: 2, end: 4},
+		{text: "@five.txt:4-99", start: 4, end: 99},
+		{text: "@five.txt:9-12", wantWarning: "past the end"},
+		{text: "@five.txt:4-2", wantWarning: "Invalid line range 4-2"},
+	}
+
+	for _, tt := range tests {
+		start, end, warning := Parse(tt.text)[0].LineRange(dir)
+		if start != tt.start || end != tt.end {
+			t.Errorf("LineRange(%q) = %d-%d, want %d-%d", tt.text, start, end, tt.start, tt.end)
+		}
+		if (tt.wantWarning == "") != (warning == "") || !strings.Contains(warning, tt.wantWarning) {
+			t.Errorf("LineRange(%q) warning = %q, want one containing %q", tt.text, warning, tt.wantWarning)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"hello.txt":   "hello world\n",
+		"lines.txt":   "one\ntwo\nthree\n",
+		"pkg/util.go": "package pkg\n",
+	})
+
+	tests := []struct {
+		name string
+		text string
+		want []string // Substrings of the expansion
+		not  []string // Substrings it mustn't contain
+	}{
+		{
+			name: "file",
+			text: "read @hello.txt now",
+			want: []string{"read Contents of hello.txt:", "hello world", "now"},
+		},
+		{
+			name: "directory",
+			text: "@pkg",
+			want: []string{"Contents of directory pkg:", "- util.go (12 bytes)"},
+		},
+		{
+			name: "line range",
+			text: "@lines.txt:2-3",
+			want: []string{"Lines 2-3 of lines.txt:", "two\nthree"},
+			not:  []string{"one"},
+		},
+		{
+			name: "line range past the end",
+			text: "@lines.txt:7-9",
+			want: []string{"past the end of @lines.txt", "one\ntwo\nthree"},
+		},
+		{
+			name: "escaped",
+			text: `write to \@hello.txt`,
+			want: []string{"write to @hello.txt"},
+			not:  []string{"hello world"},
+		},
+		{
+			name: "missing file",
+			text: "@nope.txt",
+			want: []string{"Error accessing nope.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Expand(tt.text, dir)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expand(%q) = %q, want it to contain %q", tt.text, got, want)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(got, not) {
+					t.Errorf("Expand(%q) = %q, want it not to contain %q", tt.text, got, not)
+				}
+			}
+		})
+	}
+}
+
+func TestSize(t *testing.T) {
+	dir := writeTree(t, map[string]string{"lines.txt": "one\ntwo\nthree\n"})
+
+	tests := []struct {
+		text string
+		want int64
+	}{
+		{text: "@lines.txt", want: 14},
+		{text: "@lines.txt:2-2", want: 4},
+		{text: "@missing.txt", want: 0},
+		{text: "@!ls", want: 0},
+		{text: "@.", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := Parse(tt.text)[0].Size(dir); got != tt.want {
+			t.Errorf("Size(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
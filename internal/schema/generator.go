@@ -1,11 +1,18 @@
 package schema
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/invopop/jsonschema"
 )
 
-// GenerateSchema generates a JSON schema for the given type T
+// GenerateSchema generates a JSON schema for the given type T. If T has
+// inputs but none of them end up in the schema, the properties are left
+// unset so Validate reports it.
 func GenerateSchema[T any]() anthropic.ToolInputSchemaParam {
 	reflector := jsonschema.Reflector{
 		AllowAdditionalProperties: false,
@@ -14,8 +21,43 @@ func GenerateSchema[T any]() anthropic.ToolInputSchemaParam {
 	var v T
 
 	schema := reflector.Reflect(v)
+	if schema.Properties == nil || (schema.Properties.Len() == 0 && hasInputs(reflect.TypeOf(v))) {
+		return anthropic.ToolInputSchemaParam{}
+	}
 
 	return anthropic.ToolInputSchemaParam{
 		Properties: schema.Properties,
 	}
 }
+
+// hasInputs reports whether t is a struct with fields that are encoded to JSON
+func hasInputs(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.IsExported() && field.Tag.Get("json") != "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that a tool's input schema can be sent to the API: its
+// properties must be a JSON object, empty only for tools without inputs
+func Validate(schema anthropic.ToolInputSchemaParam) error {
+	if schema.Properties == nil {
+		return errors.New("no properties were generated for the tool's inputs")
+	}
+
+	data, err := json.Marshal(schema.Properties)
+	if err != nil {
+		return fmt.Errorf("properties cannot be encoded: %w", err)
+	}
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal(data, &properties); err != nil || properties == nil {
+		return fmt.Errorf("properties are not a JSON object: %s", data)
+	}
+	return nil
+}
@@ -1,13 +1,17 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
 
+	"reapo/internal/agent"
 	"reapo/internal/schema"
 )
 
@@ -17,24 +21,107 @@ var ReadFileDefinition = ToolDefinition{
 	Description: "Read the contents of a given relative file path. Use this when you want to see what's inside a file. Do not use this with directory names.",
 	InputSchema: schema.GenerateSchema[ReadFileInput](),
 	Function:    ReadFile,
+	ReadOnly:    true,
 }
 
 type ReadFileInput struct {
-	Path string `json:"path" jsonschema_description:"The relative path of a file in the working directory."`
+	Path            string `json:"path" jsonschema_description:"The relative path of a file in the working directory."`
+	WithLineNumbers bool   `json:"with_line_numbers,omitempty" jsonschema_description:"Prefix each line with its 1-indexed line number. Defaults to false."`
+	Squeeze         bool   `json:"squeeze,omitempty" jsonschema_description:"Strip trailing whitespace and collapse runs of blank lines into one, to save context on whitespace-heavy files. The result no longer matches the file exactly, so don't copy old_str for edits from it. Defaults to false."`
+	StartLine       int    `json:"start_line,omitempty" jsonschema_description:"First line to read, 1-indexed, to read part of a large file. Defaults to the start of the file."`
+	EndLine         int    `json:"end_line,omitempty" jsonschema_description:"Last line to read, inclusive. Defaults to the end of the file."`
 }
 
-func ReadFile(input json.RawMessage) (string, error) {
+func ReadFile(ctx context.Context, input json.RawMessage) (string, error) {
 	readFileInput := ReadFileInput{}
 	err := json.Unmarshal(input, &readFileInput)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse input: %w", err)
+		return "", agent.ToolErrorf(agent.ToolErrorInvalidInput, "failed to parse input: %w", err)
 	}
 
 	content, err := os.ReadFile(readFileInput.Path)
 	if err != nil {
-		return "", err
+		return "", fileError(err)
+	}
+
+	text := string(content)
+	first := 1
+	if readFileInput.StartLine != 0 || readFileInput.EndLine != 0 {
+		if text, err = LineRange(text, readFileInput.StartLine, readFileInput.EndLine); err != nil {
+			return "", agent.NewToolError(agent.ToolErrorInvalidInput, err)
+		}
+		first = max(readFileInput.StartLine, 1)
+	}
+
+	if readFileInput.WithLineNumbers {
+		return numberLines(text, first, readFileInput.Squeeze), nil
+	}
+	if readFileInput.Squeeze {
+		return squeeze(text), nil
+	}
+	return text, nil
+}
+
+// LineRange returns lines start to end of content, 1-indexed and inclusive.
+// A start of 0 reads from the first line and an end of 0, or past the last
+// line, reads to the end.
+func LineRange(content string, start, end int) (string, error) {
+	if start < 0 || end < 0 || (end != 0 && end < max(start, 1)) {
+		return "", fmt.Errorf("invalid line range %d-%d", start, end)
+	}
+	start = max(start, 1)
+
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		// The final newline doesn't start another line
+		lines = lines[:len(lines)-1]
+	}
+	if start > len(lines) {
+		return "", fmt.Errorf("start line %d is past the end of the file (%d lines)", start, len(lines))
+	}
+	if end == 0 || end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(lines[start-1:end], ""), nil
+}
+
+// numberLines prefixes each line with its line number, right-aligned,
+// counting from first. When squeezing, dropped lines keep their numbers out
+// of the sequence so the remaining lines still show where they are in the file.
+func numberLines(content string, first int, squeezed bool) string {
+	if content == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	width := len(fmt.Sprint(first + len(lines) - 1))
+
+	var result strings.Builder
+	for i, line := range lines {
+		if squeezed {
+			line = strings.TrimRight(line, " \t\r")
+			if line == "" && i > 0 && strings.TrimSpace(lines[i-1]) == "" {
+				continue
+			}
+		}
+		fmt.Fprintf(&result, "%*d\t%s\n", width, first+i, line)
+	}
+	return result.String()
+}
+
+// squeeze strips trailing whitespace from every line and collapses runs of
+// blank lines into a single blank line
+func squeeze(content string) string {
+	lines := strings.Split(content, "\n")
+	kept := make([]string, 0, len(lines))
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" && i > 0 && strings.TrimSpace(lines[i-1]) == "" && i < len(lines)-1 {
+			continue
+		}
+		kept = append(kept, line)
 	}
-	return string(content), nil
+	return strings.Join(kept, "\n")
 }
 
 // ListFiles tool definition
@@ -43,17 +130,18 @@ var ListFilesDefinition = ToolDefinition{
 	Description: "List files and directories at a given path. If no path is provided, lists files in the current directory.",
 	InputSchema: schema.GenerateSchema[ListFilesInput](),
 	Function:    ListFiles,
+	ReadOnly:    true,
 }
 
 type ListFilesInput struct {
 	Path string `json:"path,omitempty" jsonschema_description:"Optional relative path to list files from. Defaults to current directory if not provided."`
 }
 
-func ListFiles(input json.RawMessage) (string, error) {
+func ListFiles(ctx context.Context, input json.RawMessage) (string, error) {
 	listFilesInput := ListFilesInput{}
 	err := json.Unmarshal(input, &listFilesInput)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse input: %w", err)
+		return "", agent.ToolErrorf(agent.ToolErrorInvalidInput, "failed to parse input: %w", err)
 	}
 
 	dir := "."
@@ -66,6 +154,10 @@ func ListFiles(input json.RawMessage) (string, error) {
 		if err != nil {
 			return err
 		}
+		// Stop walking large trees as soon as the tool is aborted
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 
 		relPath, err := filepath.Rel(dir, path)
 		if err != nil {
@@ -83,7 +175,7 @@ func ListFiles(input json.RawMessage) (string, error) {
 	})
 
 	if err != nil {
-		return "", err
+		return "", fileError(err)
 	}
 
 	result, err := json.Marshal(files)
@@ -113,38 +205,230 @@ type EditFileInput struct {
 	NewStr string `json:"new_str" jsonschema_description:"Text to replace old_str with"`
 }
 
-func EditFile(input json.RawMessage) (string, error) {
+func EditFile(ctx context.Context, input json.RawMessage) (string, error) {
 	editFileInput := EditFileInput{}
 	err := json.Unmarshal(input, &editFileInput)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse input: %w", err)
+		return "", agent.ToolErrorf(agent.ToolErrorInvalidInput, "failed to parse input: %w", err)
 	}
 
 	if editFileInput.Path == "" || editFileInput.OldStr == editFileInput.NewStr {
-		return "", fmt.Errorf("invalid input parameters")
+		return "", agent.ToolErrorf(agent.ToolErrorInvalidInput, "invalid input parameters")
 	}
 
 	content, err := os.ReadFile(editFileInput.Path)
 	if err != nil {
 		if os.IsNotExist(err) && editFileInput.OldStr == "" {
-			return createNewFile(editFileInput.Path, editFileInput.NewStr)
+			result, err := createNewFile(editFileInput.Path, editFileInput.NewStr)
+			if err != nil {
+				return "", err
+			}
+			return result + formatEditedFile(ctx, editFileInput.Path), nil
 		}
+		return "", fileError(err)
+	}
+
+	// Match with LF line endings so old_str works on CRLF files, then write the file's own style back
+	eol := lineEnding(string(content))
+	oldContent := toLF(string(content), eol)
+	oldStr, newStr := toLF(editFileInput.OldStr, eol), toLF(editFileInput.NewStr, eol)
+	newContent := strings.Replace(oldContent, oldStr, newStr, -1)
+
+	if oldContent == newContent && oldStr != "" {
+		return "", agent.ToolErrorf(agent.ToolErrorNoMatch, "%s", notFoundMessage(oldContent, oldStr))
+	}
+
+	// Locate the edit before writing so the result can cite path:line
+	location := editLocation(editFileInput.Path, oldContent, oldStr, newStr)
+
+	if err := ctx.Err(); err != nil {
 		return "", err
 	}
 
-	oldContent := string(content)
-	newContent := strings.Replace(oldContent, editFileInput.OldStr, editFileInput.NewStr, -1)
+	err = os.WriteFile(editFileInput.Path, []byte(fromLF(newContent, eol)), 0644)
+	if err != nil {
+		return "", fileError(err)
+	}
+
+	return fmt.Sprintf("OK - edited %s", location) + formatEditedFile(ctx, editFileInput.Path), nil
+}
+
+// editLocation returns "path:startLine" (or "path:startLine-endLine" for
+// multi-line replacements) for the first match of oldStr in content
+func editLocation(filePath, content, oldStr, newStr string) string {
+	index := strings.Index(content, oldStr)
+	if oldStr == "" || index < 0 {
+		return filePath
+	}
+
+	// A trailing newline ends the replacement's last line rather than starting another
+	startLine := strings.Count(content[:index], "\n") + 1
+	endLine := startLine + strings.Count(strings.TrimSuffix(newStr, "\n"), "\n")
+	if endLine > startLine {
+		return fmt.Sprintf("%s:%d-%d", filePath, startLine, endLine)
+	}
+	return fmt.Sprintf("%s:%d", filePath, startLine)
+}
+
+// notFoundMessage explains why old_str didn't match, pointing at a
+// whitespace-only difference when one would explain the failure
+func notFoundMessage(content, oldStr string) string {
+	if line, ok := whitespaceMismatchLine(content, oldStr); ok {
+		return fmt.Sprintf("no exact match for old_str; a whitespace-only difference was found near line %d. Re-read the file and copy its indentation and spacing exactly", line)
+	}
+	return "old_str not found in file"
+}
+
+// whitespaceMismatchLine looks for old_str again with runs of spaces and tabs
+// collapsed and trailing whitespace ignored, returning the 1-indexed line where
+// it would have matched. The first and last lines of old_str may be partial.
+func whitespaceMismatchLine(content, oldStr string) (int, bool) {
+	oldLines := normalizeLines(oldStr)
+	contentLines := normalizeLines(content)
+	if len(oldLines) == 0 || strings.TrimSpace(oldStr) == "" {
+		return 0, false
+	}
+
+	last := len(oldLines) - 1
+	for start := 0; start+last < len(contentLines); start++ {
+		if len(oldLines) == 1 {
+			if strings.Contains(contentLines[start], oldLines[0]) {
+				return start + 1, true
+			}
+			continue
+		}
+
+		if !strings.HasSuffix(contentLines[start], oldLines[0]) || !strings.HasPrefix(contentLines[start+last], oldLines[last]) {
+			continue
+		}
+		matched := true
+		for i := 1; i < last; i++ {
+			if contentLines[start+i] != oldLines[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return start + 1, true
+		}
+	}
+	return 0, false
+}
+
+// normalizeLines splits text into lines with whitespace runs collapsed to a single space
+func normalizeLines(text string) []string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return lines
+}
+
+// lineEnding returns the line ending a file uses: "\r\n" if every line ends
+// with CRLF, "\n" if none do, or "" if the file mixes both
+func lineEnding(content string) string {
+	crlf := strings.Count(content, "\r\n")
+	switch {
+	case crlf == 0:
+		return "\n"
+	case crlf == strings.Count(content, "\n"):
+		return "\r\n"
+	}
+	return ""
+}
+
+// toLF converts CRLF line endings in text to LF. Files with mixed line
+// endings (eol "") are matched byte for byte, so text is left unchanged.
+func toLF(text, eol string) string {
+	if eol == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, "\r\n", "\n")
+}
+
+// fromLF converts LF line endings in text back to eol
+func fromLF(text, eol string) string {
+	if eol != "\r\n" {
+		return text
+	}
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
+
+// SearchReplaceFile tool definition
+var SearchReplaceFileDefinition = ToolDefinition{
+	Name: "search_replace_file",
+	Description: `Apply several edits to a single existing text file in one call.
 
-	if oldContent == newContent && editFileInput.OldStr != "" {
-		return "", fmt.Errorf("old_str not found in file")
+Each edit replaces 'old_str' with 'new_str'. Edits are applied in order, each to the result of the previous one.
+The file is only written if every 'old_str' matches exactly; otherwise nothing is changed.
+Returns the status of each edit.
+`,
+	InputSchema: schema.GenerateSchema[SearchReplaceFileInput](),
+	Function:    SearchReplaceFile,
+}
+
+type SearchReplaceFileInput struct {
+	Path  string              `json:"path" jsonschema_description:"The path to the file"`
+	Edits []SearchReplaceEdit `json:"edits" jsonschema_description:"The edits to apply, in order"`
+}
+
+type SearchReplaceEdit struct {
+	OldStr string `json:"old_str" jsonschema_description:"Text to search for - must match exactly"`
+	NewStr string `json:"new_str" jsonschema_description:"Text to replace old_str with"`
+}
+
+func SearchReplaceFile(ctx context.Context, input json.RawMessage) (string, error) {
+	searchReplaceInput := SearchReplaceFileInput{}
+	err := json.Unmarshal(input, &searchReplaceInput)
+	if err != nil {
+		return "", agent.ToolErrorf(agent.ToolErrorInvalidInput, "failed to parse input: %w", err)
 	}
 
-	err = os.WriteFile(editFileInput.Path, []byte(newContent), 0644)
+	if searchReplaceInput.Path == "" || len(searchReplaceInput.Edits) == 0 {
+		return "", agent.ToolErrorf(agent.ToolErrorInvalidInput, "invalid input parameters")
+	}
+
+	content, err := os.ReadFile(searchReplaceInput.Path)
 	if err != nil {
+		return "", fileError(err)
+	}
+
+	// Apply every edit in memory first so a mismatch leaves the file untouched.
+	// Edits match with LF line endings; the file's own style is written back.
+	eol := lineEnding(string(content))
+	newContent := toLF(string(content), eol)
+	statuses := make([]string, len(searchReplaceInput.Edits))
+	failed := false
+	for i, edit := range searchReplaceInput.Edits {
+		edit.OldStr, edit.NewStr = toLF(edit.OldStr, eol), toLF(edit.NewStr, eol)
+		switch {
+		case edit.OldStr == "" || edit.OldStr == edit.NewStr:
+			statuses[i] = fmt.Sprintf("edit %d: FAILED - old_str must be non-empty and differ from new_str", i+1)
+			failed = true
+		case !strings.Contains(newContent, edit.OldStr):
+			statuses[i] = fmt.Sprintf("edit %d: FAILED - %s", i+1, notFoundMessage(newContent, edit.OldStr))
+			failed = true
+		default:
+			location := editLocation(searchReplaceInput.Path, newContent, edit.OldStr, edit.NewStr)
+			newContent = strings.Replace(newContent, edit.OldStr, edit.NewStr, -1)
+			statuses[i] = fmt.Sprintf("edit %d: OK - %s", i+1, location)
+		}
+	}
+
+	if failed {
+		return "", agent.ToolErrorf(agent.ToolErrorNoMatch, "no changes written:\n%s", strings.Join(statuses, "\n"))
+	}
+
+	if err := ctx.Err(); err != nil {
 		return "", err
 	}
 
-	return "OK", nil
+	err = os.WriteFile(searchReplaceInput.Path, []byte(fromLF(newContent, eol)), 0644)
+	if err != nil {
+		return "", fileError(err)
+	}
+
+	return strings.Join(statuses, "\n") + formatEditedFile(ctx, searchReplaceInput.Path), nil
 }
 
 func createNewFile(filePath, content string) (string, error) {
@@ -152,14 +436,25 @@ func createNewFile(filePath, content string) (string, error) {
 	if dir != "." {
 		err := os.MkdirAll(dir, 0755)
 		if err != nil {
-			return "", fmt.Errorf("failed to create directory: %w", err)
+			return "", fileError(fmt.Errorf("failed to create directory: %w", err))
 		}
 	}
 
 	err := os.WriteFile(filePath, []byte(content), 0644)
 	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+		return "", fileError(fmt.Errorf("failed to create file: %w", err))
 	}
 
 	return fmt.Sprintf("Successfully created file %s", filePath), nil
 }
+
+// fileError categorizes a filesystem error so the UI can suggest a fix
+func fileError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return agent.NewToolError(agent.ToolErrorNotFound, err)
+	case errors.Is(err, fs.ErrPermission):
+		return agent.NewToolError(agent.ToolErrorPermissionDenied, err)
+	}
+	return err
+}
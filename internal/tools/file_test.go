@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestFile writes content to a new file in a temporary directory and
+// returns its path
+func writeTestFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readTestFile returns the content of the file at path
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// toolInput encodes a tool's input as the model would send it
+func toolInput(t *testing.T, input any) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestEditFileLineEndings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		oldStr  string
+		newStr  string
+		want    string
+	}{
+		{
+			name:    "LF old_str in a CRLF file",
+			content: "line one\r\nline two\r\nline three\r\n",
+			oldStr:  "line one\nline two\n",
+			newStr:  "first\nsecond\n",
+			want:    "first\r\nsecond\r\nline three\r\n",
+		},
+		{
+			name:    "CRLF old_str in a CRLF file",
+			content: "a\r\nb\r\nc\r\n",
+			oldStr:  "a\r\nb",
+			newStr:  "x\r\ny\r\nz",
+			want:    "x\r\ny\r\nz\r\nc\r\n",
+		},
+		{
+			name:    "new lines in a CRLF file use CRLF",
+			content: "a\r\nc\r\n",
+			oldStr:  "a\n",
+			newStr:  "a\nb\n",
+			want:    "a\r\nb\r\nc\r\n",
+		},
+		{
+			name:    "CRLF file without a final newline",
+			content: "a\r\nb",
+			oldStr:  "a\nb",
+			newStr:  "b\na",
+			want:    "b\r\na",
+		},
+		{
+			name:    "LF file stays LF",
+			content: "a\nb\nc\n",
+			oldStr:  "b\n",
+			newStr:  "x\ny\n",
+			want:    "a\nx\ny\nc\n",
+		},
+		{
+			name:    "CRLF new_str in an LF file",
+			content: "a\nb\n",
+			oldStr:  "a\n",
+			newStr:  "x\r\ny\r\n",
+			want:    "x\ny\nb\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, tt.content)
+
+			input := toolInput(t, EditFileInput{Path: path, OldStr: tt.oldStr, NewStr: tt.newStr})
+			if _, err := EditFile(context.Background(), input); err != nil {
+				t.Fatalf("EditFile() error = %v", err)
+			}
+			if got := readTestFile(t, path); got != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditFileMixedLineEndings(t *testing.T) {
+	// A file mixing both styles is matched byte for byte
+	content := "a\r\nb\nc\r\n"
+	path := writeTestFile(t, content)
+
+	input := toolInput(t, EditFileInput{Path: path, OldStr: "a\nb", NewStr: "x"})
+	if _, err := EditFile(context.Background(), input); err == nil {
+		t.Fatal("EditFile() matched LF old_str across a CRLF line in a mixed file")
+	}
+
+	input = toolInput(t, EditFileInput{Path: path, OldStr: "a\r\nb\n", NewStr: "x\n"})
+	if _, err := EditFile(context.Background(), input); err != nil {
+		t.Fatalf("EditFile() error = %v", err)
+	}
+	if got, want := readTestFile(t, path), "x\nc\r\n"; got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
+
+func TestSearchReplaceFileLineEndings(t *testing.T) {
+	path := writeTestFile(t, "one\r\ntwo\r\nthree\r\n")
+
+	input := toolInput(t, SearchReplaceFileInput{
+		Path: path,
+		Edits: []SearchReplaceEdit{
+			{OldStr: "one\ntwo", NewStr: "1\n2"},
+			{OldStr: "three\n", NewStr: "3\n4\n"},
+		},
+	})
+	if _, err := SearchReplaceFile(context.Background(), input); err != nil {
+		t.Fatalf("SearchReplaceFile() error = %v", err)
+	}
+	if got, want := readTestFile(t, path), "1\r\n2\r\n3\r\n4\r\n"; got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{content: "", want: "\n"},
+		{content: "no newline", want: "\n"},
+		{content: "a\nb\n", want: "\n"},
+		{content: "a\r\nb\r\n", want: "\r\n"},
+		{content: "a\r\nb", want: "\r\n"},
+		{content: "a\r\nb\n", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := lineEnding(tt.content); got != tt.want {
+			t.Errorf("lineEnding(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestEditLocation(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\n"
+
+	tests := []struct {
+		name   string
+		oldStr string
+		newStr string
+		want   string
+	}{
+		{name: "within a line", oldStr: "two", newStr: "2", want: "f.go:2"},
+		{name: "whole line", oldStr: "two\n", newStr: "2\n", want: "f.go:2"},
+		{name: "lines", oldStr: "two\nthree", newStr: "2\n3", want: "f.go:2-3"},
+		{name: "lines with a trailing newline", oldStr: "two\nthree\n", newStr: "2\n3\n", want: "f.go:2-3"},
+		{name: "grown", oldStr: "four\n", newStr: "4\n5\n6\n", want: "f.go:4-6"},
+		{name: "deleted", oldStr: "two\nthree\n", newStr: "", want: "f.go:2"},
+		{name: "not found", oldStr: "five", newStr: "5", want: "f.go"},
+	}
+
+	for _, tt := range tests {
+		if got := editLocation("f.go", content, tt.oldStr, tt.newStr); got != tt.want {
+			t.Errorf("%s: editLocation() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
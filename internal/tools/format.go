@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"reapo/internal/config"
+	"reapo/internal/project"
+)
+
+// formatEditedFile runs the format command configured for the file's
+// extension (format_commands, e.g. ".go": "gofmt -w") with the file path as
+// its last argument. Extensions format_commands doesn't list use the
+// detected project type's formatter, with project_defaults on. It returns a
+// note for the tool result saying whether the formatter changed the file,
+// or "" when no formatter is configured.
+func formatEditedFile(ctx context.Context, filePath string) string {
+	cfg := config.Get()
+	command, ok := cfg.FormatCommands[filepath.Ext(filePath)]
+	if !ok && cfg.ProjectDefaults {
+		command = project.Current.FormatCommands[filepath.Ext(filePath)]
+	}
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	before, err := os.ReadFile(filePath)
+	if err != nil {
+		return ""
+	}
+
+	output, err := exec.CommandContext(ctx, fields[0], append(fields[1:], filePath)...).CombinedOutput()
+	if err != nil {
+		detail := strings.TrimSpace(string(output))
+		if detail == "" {
+			detail = err.Error()
+		}
+		return fmt.Sprintf("\nFormatter %s failed (the edit was still written): %s", fields[0], detail)
+	}
+
+	after, err := os.ReadFile(filePath)
+	if err != nil || bytes.Equal(before, after) {
+		return ""
+	}
+	return fmt.Sprintf("\nFormatted with %s, which changed the file; re-read it before editing further", fields[0])
+}
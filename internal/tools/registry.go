@@ -1,11 +1,14 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"reapo/internal/agent"
+	"reapo/internal/schema"
 )
 
 // Tool represents a tool that can be used by the agent
@@ -13,7 +16,7 @@ type Tool interface {
 	Name() string
 	Description() string
 	InputSchema() anthropic.ToolInputSchemaParam
-	Execute(input json.RawMessage) (string, error)
+	Execute(ctx context.Context, input json.RawMessage) (string, error)
 }
 
 // ToolDefinition is an alias for agent.ToolDefinition
@@ -52,11 +55,36 @@ func (r *Registry) GetAll() []ToolDefinition {
 }
 
 // Execute runs a tool by name with the given input
-func (r *Registry) Execute(name string, input json.RawMessage) (string, error) {
+func (r *Registry) Execute(ctx context.Context, name string, input json.RawMessage) (string, error) {
 	tool, exists := r.tools[name]
 	if !exists {
 		return "", fmt.Errorf("tool %s not found", name)
 	}
 
-	return tool.Function(input)
+	return tool.Function(ctx, input)
+}
+
+// ValidateDefinitions checks every tool's input schema, so a malformed one is
+// reported at startup instead of by the API rejecting the first request
+func ValidateDefinitions(defs []ToolDefinition) error {
+	var errs []error
+	for _, def := range defs {
+		if err := schema.Validate(def.InputSchema); err != nil {
+			errs = append(errs, fmt.Errorf("tool %s has an invalid input schema: %w", def.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Definitions returns every built-in tool, before config filters them
+func Definitions() []ToolDefinition {
+	return []ToolDefinition{
+		ReadFileDefinition,
+		ListFilesDefinition,
+		EditFileDefinition,
+		SearchReplaceFileDefinition,
+		TodoReadDefinition,
+		TodoWriteDefinition,
+		RunTaskDefinition,
+	}
 }
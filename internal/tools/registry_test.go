@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestDefinitionsValidate(t *testing.T) {
+	if err := ValidateDefinitions(Definitions()); err != nil {
+		t.Fatalf("ValidateDefinitions() error = %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, def := range Definitions() {
+		if def.Name == "" || def.Description == "" {
+			t.Errorf("tool %q has no name or description", def.Name)
+		}
+		if def.Function == nil {
+			t.Errorf("tool %s has no function", def.Name)
+		}
+		if seen[def.Name] {
+			t.Errorf("tool %s is defined twice", def.Name)
+		}
+		seen[def.Name] = true
+	}
+}
+
+func TestValidateDefinitionsReportsEveryBadSchema(t *testing.T) {
+	defs := []ToolDefinition{
+		ReadFileDefinition,
+		{Name: "no_properties", InputSchema: anthropic.ToolInputSchemaParam{}},
+		{Name: "not_an_object", InputSchema: anthropic.ToolInputSchemaParam{Properties: []string{"path"}}},
+	}
+
+	err := ValidateDefinitions(defs)
+	if err == nil {
+		t.Fatal("ValidateDefinitions() accepted malformed schemas")
+	}
+	for _, name := range []string{"no_properties", "not_an_object"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("ValidateDefinitions() error = %q, want it to name %s", err, name)
+		}
+	}
+	if strings.Contains(err.Error(), ReadFileDefinition.Name) {
+		t.Errorf("ValidateDefinitions() error = %q, want it not to name the valid tool", err)
+	}
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"reapo/internal/agent"
+)
+
+// ShellEnabled controls whether shell commands may be executed at all.
+// It is off by default and must be opted into at startup.
+var ShellEnabled = false
+
+// ShellAllowlist contains the executables that may be run. None of them can
+// run other programs, apart from those limited by ShellSubcommands and
+// ShellBlockedArgs.
+var ShellAllowlist = []string{
+	"go", "gofmt", "git", "ls", "cat", "head", "tail", "wc", "grep", "find", "echo", "pwd",
+}
+
+// ShellSubcommands limits allowlisted programs that can run project code or
+// other programs to the subcommands that only read or build. The subcommand
+// must come first, so options like git's -c can't be set before it.
+var ShellSubcommands = map[string][]string{
+	"go":  {"build", "vet", "list", "doc", "version"},
+	"git": {"status", "diff", "log", "show", "blame", "ls-files"},
+}
+
+// ShellBlockedArgs are the arguments that make an allowed command run another
+// program or write files
+var ShellBlockedArgs = map[string][]string{
+	"go":   {"-toolexec", "--toolexec", "-vettool", "--vettool", "-exec", "--exec", "-o", "--o", "-pkgdir", "--pkgdir", "-ldflags", "--ldflags"},
+	"git":  {"--ext-diff", "--output", "--textconv"},
+	"find": {"-exec", "-execdir", "-ok", "-okdir", "-delete", "-fprint", "-fprint0", "-fprintf", "-fls"},
+}
+
+// gitSafeArgs are added after git subcommands that show file contents, so
+// the diff drivers and textconv filters a repository's config or
+// .gitattributes set up aren't run
+var gitSafeArgs = map[string][]string{
+	"diff":  {"--no-ext-diff", "--no-textconv"},
+	"show":  {"--no-ext-diff", "--no-textconv"},
+	"log":   {"--no-ext-diff", "--no-textconv"},
+	"blame": {"--no-textconv"},
+}
+
+// ShellTimeout bounds how long a single command may run
+const ShellTimeout = 30 * time.Second
+
+// RunShellCommand runs an allowlisted command with a timeout and returns its combined output.
+// The command is split on whitespace and executed directly, without a shell, so pipes,
+// redirects and variable expansion are not supported. Cancelling ctx stops the command.
+func RunShellCommand(ctx context.Context, command string) (string, error) {
+	if !ShellEnabled {
+		return "", fmt.Errorf("shell commands are disabled")
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty command")
+	}
+
+	if err := checkShellCommand(fields); err != nil {
+		return "", err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, ShellTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(runCtx, fields[0], shellArgs(fields)...).CombinedOutput()
+	if ctx.Err() == context.Canceled {
+		err = fmt.Errorf("command was cancelled")
+	} else if runCtx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("command timed out after %s", ShellTimeout)
+	} else if err != nil {
+		// Non-zero exit codes still produce useful output (e.g. go vet findings)
+		err = fmt.Errorf("command failed: %w", err)
+	}
+	agent.RecordCommand(command, err)
+	if err != nil {
+		return string(output), err
+	}
+
+	return string(output), nil
+}
+
+// checkShellCommand returns an error unless the command's program is
+// allowlisted and it runs no other program
+func checkShellCommand(fields []string) error {
+	name := fields[0]
+	if !isAllowedCommand(name) {
+		return fmt.Errorf("command %q is not in the shell allowlist", name)
+	}
+
+	if subcommands, ok := ShellSubcommands[name]; ok {
+		if len(fields) < 2 || !slices.Contains(subcommands, fields[1]) {
+			return fmt.Errorf("%s commands are limited to %s", name, strings.Join(subcommands, ", "))
+		}
+	}
+
+	for _, arg := range fields[1:] {
+		flag, _, _ := strings.Cut(arg, "=")
+		if slices.Contains(ShellBlockedArgs[name], flag) {
+			return fmt.Errorf("%s %s is not allowed in shell commands", name, flag)
+		}
+	}
+	return nil
+}
+
+// shellArgs returns the arguments to run the command with, adding
+// gitSafeArgs after git's subcommand
+func shellArgs(fields []string) []string {
+	args := fields[1:]
+	if fields[0] != "git" || len(args) == 0 {
+		return args
+	}
+	safe := gitSafeArgs[args[0]]
+	return slices.Concat(args[:1], safe, args[1:])
+}
+
+func isAllowedCommand(name string) bool {
+	for _, allowed := range ShellAllowlist {
+		if name == allowed {
+			return true
+		}
+	}
+	return false
+}
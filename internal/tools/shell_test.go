@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckShellCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		wantErr bool
+	}{
+		{command: "ls -la"},
+		{command: "go build ./..."},
+		{command: "go vet ./..."},
+		{command: "git status"},
+		{command: "git diff HEAD~1"},
+		{command: "find . -name *.go"},
+		{command: "rm -rf .", wantErr: true},
+		{command: "go run .", wantErr: true},
+		{command: "go test ./...", wantErr: true},
+		{command: "go env", wantErr: true},
+		{command: "go env -w GOFLAGS=-toolexec=/tmp/x", wantErr: true},
+		{command: "go env -u GOFLAGS", wantErr: true},
+		{command: "go build -o /tmp/x .", wantErr: true},
+		{command: "go build -o=/tmp/x .", wantErr: true},
+		{command: "go build -toolexec=/tmp/x .", wantErr: true},
+		{command: "go build -ldflags=-extld=/tmp/x .", wantErr: true},
+		{command: "go vet -vettool=/tmp/x ./...", wantErr: true},
+		{command: "git -c core.pager=/tmp/x status", wantErr: true},
+		{command: "git checkout main", wantErr: true},
+		{command: "git diff --ext-diff", wantErr: true},
+		{command: "git log --textconv", wantErr: true},
+		{command: "git diff --output=/tmp/x", wantErr: true},
+		{command: "find . -exec rm {} ;", wantErr: true},
+		{command: "find . -delete", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := checkShellCommand(strings.Fields(tt.command))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkShellCommand(%q) error = %v, want error %v", tt.command, err, tt.wantErr)
+		}
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{command: "git diff HEAD~1", want: "diff --no-ext-diff --no-textconv HEAD~1"},
+		{command: "git show HEAD", want: "show --no-ext-diff --no-textconv HEAD"},
+		{command: "git log -p", want: "log --no-ext-diff --no-textconv -p"},
+		{command: "git blame main.go", want: "blame --no-textconv main.go"},
+		{command: "git status", want: "status"},
+		{command: "go build ./...", want: "build ./..."},
+		{command: "ls", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(shellArgs(strings.Fields(tt.command)), " "); got != tt.want {
+			t.Errorf("shellArgs(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestShellGitSkipsTextconv(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// A repository whose .txt files go through a textconv filter that leaves
+	// a marker behind when it runs
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	git("init", "-q")
+	git("config", "diff.marker.textconv", "touch "+marker+" && cat")
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("*.txt diff=marker\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"one\n", "two\n"} {
+		if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", content)
+	}
+
+	enabled := ShellEnabled
+	ShellEnabled = true
+	t.Cleanup(func() { ShellEnabled = enabled })
+	t.Chdir(dir)
+
+	for _, command := range []string{"git diff HEAD~1", "git show HEAD", "git log -p", "git blame a.txt"} {
+		output, err := RunShellCommand(t.Context(), command)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", command, err, output)
+		}
+		if _, err := os.Stat(marker); err == nil {
+			t.Fatalf("%s ran the repository's textconv filter", command)
+		}
+		if !strings.Contains(output, "two") {
+			t.Errorf("%s output = %q, want it to show the file", command, output)
+		}
+	}
+}
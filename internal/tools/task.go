@@ -17,14 +17,16 @@ var (
 	taskSystemPrompt string
 )
 
-// InitializeTaskAgent sets up the global client and system prompt for task execution
+// InitializeTaskAgent sets up the global client and system prompt for task execution.
+// client should be the main agent's, from auth.NewClient, so tasks run with
+// the same credentials.
 func InitializeTaskAgent(client *anthropic.Client, systemPrompt string) {
 	taskClient = client
 	taskSystemPrompt = systemPrompt
 }
 
-// runTaskWithAvailableTools uses GenerateText to execute tasks
-func runTaskWithAvailableTools(input json.RawMessage) (string, error) {
+// runTaskWithAvailableTools runs a task with a sub-agent that can use the file and todo tools
+func runTaskWithAvailableTools(ctx context.Context, input json.RawMessage) (string, error) {
 	if taskClient == nil {
 		return "", fmt.Errorf("task client not initialized - call InitializeTaskAgent first")
 	}
@@ -45,15 +47,20 @@ func runTaskWithAvailableTools(input json.RawMessage) (string, error) {
 
 	taskAgent := agent.NewAgent(taskClient, nil, availableTools, taskSystemPrompt)
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	// Let the caller follow what the task agent is doing
+	if callback := agent.ToolCallbackFrom(ctx); callback != nil {
+		taskAgent.SetToolCallback(callback)
+	}
+
+	// Bound the task with a timeout; aborting the tool cancels it too
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
 	defer cancel()
 
 	// Format the task message
 	message := fmt.Sprintf("Task: %s\n\nContext: %s", taskInput.Task, taskInput.Context)
 
-	// Use GenerateText to execute the task
-	return taskAgent.GenerateText(ctx, message)
+	// Run the task, letting the agent use its tools until it has an answer
+	return taskAgent.RunWithTools(ctx, message)
 }
 
 // RunTask tool definition
@@ -1,8 +1,10 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,6 +16,7 @@ type Todo struct {
 	ID          string     `json:"id"`
 	Text        string     `json:"text"`
 	Completed   bool       `json:"completed"`
+	Priority    string     `json:"priority,omitempty"` // "high", "medium", "low", or empty
 	CreatedAt   time.Time  `json:"created_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 }
@@ -25,9 +28,46 @@ var (
 	todosMutex  sync.RWMutex
 )
 
+// priorityRank orders priorities for listing; unset sorts with medium
+var priorityRank = map[string]int{"high": 0, "medium": 1, "": 1, "low": 2}
+
+func validatePriority(priority string) error {
+	if _, ok := priorityRank[priority]; !ok {
+		return fmt.Errorf("invalid priority: %s. Use 'high', 'medium' or 'low'", priority)
+	}
+	return nil
+}
+
+// Todos returns a snapshot of the todo list, ordered by priority then creation time.
+// It is safe to call from any goroutine, such as the TUI while a todowrite
+// call is running; the snapshot shares no memory with the store.
+func Todos() []Todo {
+	todosMutex.RLock()
+	defer todosMutex.RUnlock()
+	return sortedTodos()
+}
+
+// sortedTodos returns a copy of todos ordered by priority, then creation time.
+// The caller must hold todosMutex.
+func sortedTodos() []Todo {
+	sorted := append([]Todo(nil), todos...)
+	for i, todo := range sorted {
+		if todo.CompletedAt != nil {
+			completedAt := *todo.CompletedAt
+			sorted[i].CompletedAt = &completedAt
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if priorityRank[sorted[i].Priority] != priorityRank[sorted[j].Priority] {
+			return priorityRank[sorted[i].Priority] < priorityRank[sorted[j].Priority]
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+	return sorted
+}
+
+// generateTodoID returns the next todo ID. The caller must hold todosMutex.
 func generateTodoID() string {
-	todosMutex.Lock()
-	defer todosMutex.Unlock()
 	todoCounter++
 	return fmt.Sprintf("todo_%d", todoCounter)
 }
@@ -44,7 +84,7 @@ type TodoReadInput struct {
 	// No parameters needed for listing
 }
 
-func TodoRead(input json.RawMessage) (string, error) {
+func TodoRead(ctx context.Context, input json.RawMessage) (string, error) {
 	todosMutex.RLock()
 	defer todosMutex.RUnlock()
 
@@ -53,12 +93,16 @@ func TodoRead(input json.RawMessage) (string, error) {
 	}
 
 	result := "Todos:\n"
-	for _, todo := range todos {
+	for _, todo := range sortedTodos() {
 		status := "[ ]"
 		if todo.Completed {
 			status = "[x]"
 		}
-		result += fmt.Sprintf("%s %s (ID: %s)\n", status, todo.Text, todo.ID)
+		priority := ""
+		if todo.Priority != "" {
+			priority = fmt.Sprintf("(%s) ", todo.Priority)
+		}
+		result += fmt.Sprintf("%s %s%s (ID: %s)\n", status, priority, todo.Text, todo.ID)
 	}
 
 	return result, nil
@@ -67,18 +111,19 @@ func TodoRead(input json.RawMessage) (string, error) {
 // TodoWrite tool definition
 var TodoWriteDefinition = ToolDefinition{
 	Name:        "todowrite",
-	Description: "Create new todos or mark existing todos as completed. Use 'add' to create a new todo or 'complete' to mark a todo as done.",
+	Description: "Create, edit, or complete todos. Use 'add' to create a new todo, 'edit' to change a todo's text, or 'complete' to mark a todo as done.",
 	InputSchema: schema.GenerateSchema[TodoWriteInput](),
 	Function:    TodoWrite,
 }
 
 type TodoWriteInput struct {
-	Action string `json:"action" jsonschema_description:"Action to perform: 'add' to create a new todo, 'complete' to mark a todo as completed"`
-	Text   string `json:"text,omitempty" jsonschema_description:"Todo text (required for 'add' action)"`
-	ID     string `json:"id,omitempty" jsonschema_description:"Todo ID (required for 'complete' action)"`
+	Action   string `json:"action" jsonschema_description:"Action to perform: 'add' to create a new todo, 'edit' to change a todo's text, 'complete' to mark a todo as completed"`
+	Text     string `json:"text,omitempty" jsonschema_description:"Todo text (required for 'add' and 'edit' actions)"`
+	ID       string `json:"id,omitempty" jsonschema_description:"Todo ID (required for 'edit' and 'complete' actions)"`
+	Priority string `json:"priority,omitempty" jsonschema_description:"Optional priority for 'add' and 'edit' actions: 'high', 'medium' or 'low'"`
 }
 
-func TodoWrite(input json.RawMessage) (string, error) {
+func TodoWrite(ctx context.Context, input json.RawMessage) (string, error) {
 	var todoInput TodoWriteInput
 	if err := json.Unmarshal(input, &todoInput); err != nil {
 		return "", fmt.Errorf("failed to parse input: %w", err)
@@ -86,33 +131,69 @@ func TodoWrite(input json.RawMessage) (string, error) {
 
 	switch todoInput.Action {
 	case "add":
-		return addTodo(todoInput.Text)
+		return addTodo(todoInput.Text, todoInput.Priority)
+	case "edit":
+		return editTodo(todoInput.ID, todoInput.Text, todoInput.Priority)
 	case "complete":
 		return completeTodo(todoInput.ID)
 	default:
-		return "", fmt.Errorf("invalid action: %s. Use 'add' or 'complete'", todoInput.Action)
+		return "", fmt.Errorf("invalid action: %s. Use 'add', 'edit' or 'complete'", todoInput.Action)
 	}
 }
 
-func addTodo(text string) (string, error) {
+func addTodo(text, priority string) (string, error) {
 	if text == "" {
 		return "", fmt.Errorf("todo text cannot be empty")
 	}
+	if err := validatePriority(priority); err != nil {
+		return "", err
+	}
 
+	// Assign the ID and append under one lock so IDs follow list order
+	todosMutex.Lock()
 	newTodo := Todo{
 		ID:        generateTodoID(),
 		Text:      text,
 		Completed: false,
+		Priority:  priority,
 		CreatedAt: time.Now(),
 	}
-
-	todosMutex.Lock()
 	todos = append(todos, newTodo)
 	todosMutex.Unlock()
 
 	return fmt.Sprintf("Added todo: %s (ID: %s)", newTodo.Text, newTodo.ID), nil
 }
 
+func editTodo(id, text, priority string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("todo ID cannot be empty")
+	}
+	if text == "" && priority == "" {
+		return "", fmt.Errorf("todo text cannot be empty")
+	}
+	if err := validatePriority(priority); err != nil {
+		return "", err
+	}
+
+	todosMutex.Lock()
+	defer todosMutex.Unlock()
+
+	for i, todo := range todos {
+		if todo.ID == id {
+			// Only change the fields that were provided
+			if text != "" {
+				todos[i].Text = text
+			}
+			if priority != "" {
+				todos[i].Priority = priority
+			}
+			return fmt.Sprintf("Edited todo %s: %s -> %s", id, todo.Text, todos[i].Text), nil
+		}
+	}
+
+	return "", fmt.Errorf("todo with ID %s not found", id)
+}
+
 func completeTodo(id string) (string, error) {
 	if id == "" {
 		return "", fmt.Errorf("todo ID cannot be empty")
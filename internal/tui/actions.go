@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/agent"
+	"reapo/internal/tui/components"
+)
+
+// defaultActionsScript is where /export-actions writes without a path
+const defaultActionsScript = "reapo-actions.sh"
+
+// exportActions writes the session's file changes and shell commands, in
+// the order they happened, as a shell script that can be reviewed or run
+// to replay them
+func (m Model) exportActions(path string) (tea.Model, tea.Cmd) {
+	actions := agent.RecordedActions()
+	if len(actions) == 0 {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "No actions to export: no files have been edited and no commands run",
+				Duration: 4 * time.Second,
+			}
+		}
+	}
+
+	if path == "" {
+		path = defaultActionsScript
+	}
+	if err := os.WriteFile(path, []byte(actionsScript(actions, time.Now())), 0755); err != nil {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineError,
+				Text:     fmt.Sprintf("Error: Failed to export actions: %v", err),
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     fmt.Sprintf("Exported %d actions to %s", len(actions), path),
+			Duration: 4 * time.Second,
+		}
+	}
+}
+
+// actionsScript renders actions as a commented shell script. File changes
+// are applied with patch and commands are run as they were; failed calls
+// and tools that don't change files appear only as comments.
+func actionsScript(actions []agent.Action, now time.Time) string {
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&script, "# Actions recorded by reapo, exported %s.\n", now.Format("2006-01-02 15:04"))
+	script.WriteString("# Review before running: it replays each change in order from the\n")
+	script.WriteString("# directory reapo was started in, and stops at the first failure.\n")
+	script.WriteString("set -e\n")
+
+	for i, action := range actions {
+		script.WriteString("\n")
+		step := fmt.Sprintf("# %d. %s %s", i+1, action.Time.Format("15:04:05"), action.Tool)
+		if action.Path != "" {
+			step += " " + action.Path
+		}
+		switch {
+		case action.Tool == "shell":
+			fmt.Fprintf(&script, "%s: %s\n", step, action.Command)
+			if action.Err != "" {
+				// A command that failed when recorded doesn't stop the replay
+				fmt.Fprintf(&script, "# (%s when recorded)\n", commentLine(action.Err))
+				script.WriteString(shellCommand(action.Command) + " || true\n")
+				break
+			}
+			script.WriteString(shellCommand(action.Command) + "\n")
+		case action.Patch != "":
+			script.WriteString(step + "\n")
+			if action.Err != "" {
+				fmt.Fprintf(&script, "# (the tool reported an error after changing the file: %s)\n", commentLine(action.Err))
+			}
+			script.WriteString("patch -p0 <<'REAPO_PATCH'\n")
+			script.WriteString(action.Patch)
+			script.WriteString("REAPO_PATCH\n")
+		case action.Err != "":
+			fmt.Fprintf(&script, "%s failed, nothing to replay: %s\n", step, commentLine(action.Err))
+		case action.Path != "":
+			fmt.Fprintf(&script, "%s left the file unchanged\n", step)
+		default:
+			fmt.Fprintf(&script, "%s (changes no files): %s\n", step, commentLine(string(action.Input)))
+		}
+	}
+	return script.String()
+}
+
+// shellCommand quotes each word of a command for sh. Commands are run
+// without a shell, split on whitespace, so quoting keeps the words as run.
+func shellCommand(command string) string {
+	fields := strings.Fields(command)
+	for i, field := range fields {
+		if strings.ContainsFunc(field, func(r rune) bool { return !isShellSafe(r) }) {
+			fields[i] = "'" + strings.ReplaceAll(field, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// isShellSafe reports whether r needs no quoting in a shell word
+func isShellSafe(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,+@%", r)
+}
+
+// commentLine flattens text onto one line for a script comment
+func commentLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
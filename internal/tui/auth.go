@@ -1,8 +1,9 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"net/http"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -74,7 +75,7 @@ func (m Model) startLogoutFlow() tea.Cmd {
 	
 	// Remove auth immediately
 	if err := auth.Remove("anthropic"); err != nil {
-		logger.Error("Failed to logout", "error", err)
+		logger.Errorw("Failed to logout", "error", err)
 		return func() tea.Msg {
 			return ShowStatuslineMsg{
 				Type:     components.StatuslineError,
@@ -109,25 +110,35 @@ func (m Model) handleAuthCode(code string, verifier string) tea.Cmd {
 	// Exchange code for tokens in a separate goroutine
 	cmds = append(cmds, func() tea.Msg {
 		// Log the exchange attempt
-		logger.Info("Attempting OAuth code exchange", 
+		logger.Infow("Attempting OAuth code exchange", 
 			"code_length", len(code),
 			"verifier_length", len(verifier))
 		
 		oauthInfo, err := auth.Exchange(code, verifier)
 		if err != nil {
-			logger.Error("OAuth exchange failed", "error", err)
+			logger.Errorw("OAuth exchange failed", "error", err)
 			// Parse specific error types
+			var statusErr *auth.StatusError
+			var networkErr *auth.NetworkError
 			errorMsg := "Error: Authentication failed"
 			if len(code) == 0 {
 				errorMsg = "Error: Authentication cancelled by user"
-			} else if err.Error() == "token exchange failed with status 400" {
-				errorMsg = "Error: Authentication failed: Invalid authorization code"
-			} else if err.Error() == "token exchange failed with status 401" {
-				errorMsg = "Error: Authentication failed: Authorization expired"
-			} else if err.Error() == "token exchange failed with status 403" {
-				errorMsg = "Error: Authentication failed: Access denied"
-			} else if strings.Contains(err.Error(), "failed to exchange code") {
-				errorMsg = "Error: Authentication failed: Network error"
+			} else if errors.As(err, &statusErr) {
+				logger.Errorw("Token endpoint rejected the code", "status", statusErr.StatusCode, "body", statusErr.Body)
+				switch {
+				case statusErr.StatusCode == http.StatusBadRequest:
+					errorMsg = "Error: Authentication failed: Invalid authorization code"
+				case statusErr.StatusCode == http.StatusUnauthorized:
+					errorMsg = "Error: Authentication failed: Authorization expired"
+				case statusErr.StatusCode == http.StatusForbidden:
+					errorMsg = "Error: Authentication failed: Access denied"
+				case statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500:
+					errorMsg = fmt.Sprintf("Error: Authentication failed: The auth server is unavailable (status %d), try again shortly", statusErr.StatusCode)
+				default:
+					errorMsg = fmt.Sprintf("Error: Authentication failed: Unexpected status %d", statusErr.StatusCode)
+				}
+			} else if errors.As(err, &networkErr) {
+				errorMsg = "Error: Authentication failed: Network error, check your connection and try again"
 			} else {
 				errorMsg = fmt.Sprintf("Error: Authentication failed: %v", err)
 			}
@@ -139,14 +150,14 @@ func (m Model) handleAuthCode(code string, verifier string) tea.Cmd {
 			}
 		}
 		
-		logger.Info("OAuth exchange successful",
+		logger.Infow("OAuth exchange successful",
 			"has_access_token", oauthInfo.AccessToken != "",
 			"has_refresh_token", oauthInfo.RefreshToken != "",
 			"expires_at", oauthInfo.ExpiresAt)
 		
 		// Save auth info
 		if err := auth.Set("anthropic", oauthInfo); err != nil {
-			logger.Error("Failed to save auth info", "error", err)
+			logger.Errorw("Failed to save auth info", "error", err)
 			return ShowStatuslineMsg{
 				Type:     components.StatuslineError,
 				Text:     fmt.Sprintf("Error: Failed to save authentication: %v", err),
@@ -154,7 +165,7 @@ func (m Model) handleAuthCode(code string, verifier string) tea.Cmd {
 			}
 		}
 		
-		logger.Info("Auth info saved successfully", "provider", "anthropic")
+		logger.Infow("Auth info saved successfully", "provider", "anthropic")
 		
 		return AuthFlowCompleteMsg{
 			Success: true,
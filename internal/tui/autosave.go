@@ -0,0 +1,312 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/auth"
+	"reapo/internal/config"
+	"reapo/internal/logger"
+	"reapo/internal/tui/components"
+)
+
+// The conversation is saved to a recovery file every autosave_seconds so an
+// unexpected exit loses at most that much. A clean exit removes the file; if
+// it is still there at startup, the user is offered to restore it. Each
+// working directory has its own file, so reapo running in two projects keeps
+// their conversations apart. Finished conversations are archived as
+// sessions instead, which /resume lists and restores.
+
+// RestoreOnStart restores the conversation saved for the working directory
+// at startup without asking, or else its latest session, for --continue
+var RestoreOnStart = false
+
+// recoveryDirName is the directory in the data directory holding a recovery
+// file per project, named by a hash of the project's path
+const recoveryDirName = "recovery"
+
+// legacyRecoveryFileName is the single recovery file used before recovery
+// files were kept per project
+const legacyRecoveryFileName = "recovery.json"
+
+// recovery is the conversation saved by autosave
+type recovery struct {
+	SavedAt    time.Time            `json:"saved_at"`
+	WorkingDir string               `json:"working_dir"`
+	Messages   []components.Message `json:"messages"`
+}
+
+// AutosaveMsg triggers a periodic save of the conversation
+type AutosaveMsg struct{}
+
+// RecoveryFoundMsg reports a conversation left behind by an unclean exit
+type RecoveryFoundMsg struct {
+	Recovery recovery
+}
+
+// recoveryPath returns the location of the recovery file for the current
+// working directory
+func recoveryPath() (string, error) {
+	return projectFile(recoveryDirName, ".json")
+}
+
+// projectFile returns the location of the current working directory's file
+// in dirName in the data directory, named by a hash of the directory's path
+func projectFile(dirName, ext string) (string, error) {
+	dir, err := auth.DefaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(workingDir))
+	return filepath.Join(dir, dirName, hex.EncodeToString(sum[:8])+ext), nil
+}
+
+// readRecovery reads a recovery file, reporting nil for one that is missing,
+// unreadable or empty
+func readRecovery(path string) *recovery {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Error("Failed to read recovery file: %v", err)
+		}
+		return nil
+	}
+
+	var rec recovery
+	if err := json.Unmarshal(data, &rec); err != nil {
+		logger.Error("Ignoring unreadable recovery file %s: %v", path, err)
+		return nil
+	}
+	if len(rec.Messages) == 0 {
+		return nil
+	}
+	return &rec
+}
+
+// migrateLegacyRecovery moves a recovery file saved before they were kept
+// per project to the current project's location, if it belongs to it
+func migrateLegacyRecovery(path string) {
+	dir, err := auth.DefaultDataDir()
+	if err != nil {
+		return
+	}
+	legacyPath := filepath.Join(dir, legacyRecoveryFileName)
+	rec := readRecovery(legacyPath)
+	if rec == nil {
+		return
+	}
+	if workingDir, err := os.Getwd(); err != nil || (rec.WorkingDir != "" && rec.WorkingDir != workingDir) {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	if err := os.Rename(legacyPath, path); err != nil {
+		logger.Error("Failed to move recovery file: %v", err)
+	}
+}
+
+// scheduleAutosave saves the conversation again after the configured interval
+func scheduleAutosave() tea.Cmd {
+	seconds := config.Get().AutosaveSeconds
+	if seconds <= 0 {
+		return nil
+	}
+	return tea.Tick(time.Duration(seconds)*time.Second, func(time.Time) tea.Msg {
+		return AutosaveMsg{}
+	})
+}
+
+// autosave writes the conversation to the recovery file. The messages are
+// encoded here, on the update loop, and the file is written in the background.
+func (m Model) autosave() tea.Cmd {
+	// Don't overwrite a recovered conversation the user hasn't decided about
+	if m.pendingRecovery != nil {
+		return nil
+	}
+	if len(m.messages) == 0 {
+		return func() tea.Msg {
+			clearRecovery()
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(recovery{
+		SavedAt:    time.Now(),
+		WorkingDir: m.workingDir(),
+		Messages:   m.messages,
+	})
+	if err != nil {
+		logger.Error("Failed to encode conversation for autosave: %v", err)
+		return nil
+	}
+
+	return func() tea.Msg {
+		if err := writeRecovery(data); err != nil {
+			logger.Error("Failed to autosave conversation: %v", err)
+		}
+		return nil
+	}
+}
+
+// writeRecovery replaces the recovery file
+func writeRecovery(data []byte) error {
+	path, err := recoveryPath()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic replaces the file at path, writing a temporary file first
+// so a crash mid-write can't leave it truncated
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadRecovery looks for a conversation left behind by an unclean exit
+func loadRecovery() tea.Cmd {
+	return func() tea.Msg {
+		path, err := recoveryPath()
+		if err != nil {
+			return nil
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			migrateLegacyRecovery(path)
+		}
+
+		rec := readRecovery(path)
+		if rec == nil && RestoreOnStart {
+			// With nothing to recover, --continue picks up the last session
+			return loadLatestSession()
+		}
+		if rec == nil {
+			return nil
+		}
+		return RecoveryFoundMsg{Recovery: *rec}
+	}
+}
+
+// clearRecovery removes the recovery file, e.g. on a clean exit
+func clearRecovery() {
+	path, err := recoveryPath()
+	if err != nil {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logger.Error("Failed to remove recovery file: %v", err)
+	}
+}
+
+// offerRecovery asks whether to restore a recovered conversation, or
+// restores it right away with --continue. It is only offered before anything
+// has been sent in this session.
+func (m Model) offerRecovery(rec recovery) (tea.Model, tea.Cmd) {
+	if len(m.messages) > 0 {
+		return m, nil
+	}
+
+	m.pendingRecovery = &rec
+	if RestoreOnStart {
+		return m.restoreRecovery()
+	}
+
+	text := fmt.Sprintf("Found an unsaved conversation from %s (%d messages). r: restore • d: discard",
+		rec.SavedAt.Format("Jan 2 15:04"), len(rec.Messages))
+	if rec.WorkingDir != "" && rec.WorkingDir != m.workingDir() {
+		text = fmt.Sprintf("Found an unsaved conversation from %s in %s (%d messages). r: restore • d: discard",
+			rec.SavedAt.Format("Jan 2 15:04"), rec.WorkingDir, len(rec.Messages))
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineWarning,
+			Text:     text,
+			Duration: 0, // Cleared once the user decides
+		}
+	}
+}
+
+// handleRecoveryChoice acts on the user's answer to offerRecovery
+func (m Model) handleRecoveryChoice(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "r", "enter":
+		return m.restoreRecovery()
+	case "d", "esc":
+		m = m.endRecoveryChoice()
+		return m, func() tea.Msg {
+			clearRecovery()
+			return nil
+		}
+	}
+	return m, nil
+}
+
+// restoreRecovery restores the recovered conversation offered by offerRecovery
+func (m Model) restoreRecovery() (tea.Model, tea.Cmd) {
+	rec := m.pendingRecovery
+	m = m.endRecoveryChoice()
+	m.messages = restoredMessages(rec.Messages)
+	m.contextTokens = m.countConversationTokens()
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     fmt.Sprintf("Restored %d messages", len(m.messages)),
+			Duration: 3 * time.Second,
+		}
+	}
+}
+
+// endRecoveryChoice leaves the recovery prompt
+func (m Model) endRecoveryChoice() Model {
+	m.pendingRecovery = nil
+	if m.statusline != nil {
+		m.statusline.ClearMessage()
+	}
+	return m
+}
+
+// restoredMessages settles messages that were still in progress when the
+// conversation was saved. Interrupted responses are marked as cut off so
+// /continue can pick them up.
+func restoredMessages(messages []components.Message) []components.Message {
+	for i, msg := range messages {
+		switch msg.Status {
+		case components.MessagePending, components.MessageProcessing, components.MessageStreaming:
+		default:
+			continue
+		}
+		messages[i].Status = components.MessageCompleted
+		messages[i].Progress = nil
+		if msg.Role == "assistant" && msg.Type == components.MessageTypeText {
+			messages[i].Truncated = true
+		}
+	}
+	return messages
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/config"
+	"reapo/internal/tui/components"
+)
+
+// The keyboard focus can move from the input to the chat, where vim-style
+// keys scroll back through the conversation instead of editing the input
+
+// focusChat moves the keyboard focus to the chat
+func (m Model) focusChat() (tea.Model, tea.Cmd) {
+	m.chatFocused = true
+	m.chatScroll = 0
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Chat focused: j/k to scroll, Ctrl+U/Ctrl+D half a page, g/G top/bottom, Enter to select a message, Tab or Esc to return",
+			Duration: 0, // Cleared when the input is focused again
+		}
+	}
+}
+
+// handleChatFocus scrolls the focused chat or returns the focus to the input
+func (m Model) handleChatFocus(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	height, _ := m.chatSize(0)
+	page := max(height-1, 1)
+
+	key := msg.String()
+	if m.keymap.Matches(config.ActionCycleFocus, key) {
+		key = "tab"
+	}
+
+	switch key {
+	case "k", "up":
+		m.chatScroll++
+	case "j", "down":
+		m.chatScroll--
+	case "ctrl+u":
+		m.chatScroll += max(page/2, 1)
+	case "ctrl+d":
+		m.chatScroll -= max(page/2, 1)
+	case "pgup":
+		m.chatScroll += page
+	case "pgdown":
+		m.chatScroll -= page
+	case "g", "home":
+		m.chatScroll = m.maxChatScroll()
+	case "G", "end":
+		m.chatScroll = 0
+	case "enter":
+		// Selection keeps the focused message in view from the newest lines
+		m.chatScroll = 0
+		return m.startMessageSelection()
+	case "tab", "esc":
+		return m.focusInput(), nil
+	default:
+		// The input doesn't receive keys while the chat is focused
+		return m, nil
+	}
+	m.chatScroll = min(max(m.chatScroll, 0), m.maxChatScroll())
+	return m, nil
+}
+
+// maxChatScroll returns how far the chat can currently be scrolled back
+func (m Model) maxChatScroll() int {
+	height, width := m.chatSize(0)
+	chat := components.NewChatComponent(m.messages, height, width)
+	chat.SetVerbose(m.verbose)
+	return chat.MaxScroll()
+}
+
+// focusInput returns the keyboard focus to the input and the chat to the newest messages
+func (m Model) focusInput() Model {
+	m.chatFocused = false
+	m.chatScroll = 0
+	if m.statusline != nil {
+		m.statusline.ClearMessage()
+	}
+	return m
+}
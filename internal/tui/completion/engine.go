@@ -1,17 +1,96 @@
 package completion
 
 import (
+	"sort"
 	"strings"
+
+	"reapo/internal/config"
+	"reapo/internal/personas"
+	"reapo/internal/prompts"
 )
 
 var slashCommands = []CompletionItem{
 	{Text: "/help", Description: "Show all available commands"},
-	{Text: "/status", Description: "Show authentication status"},
+	{Text: "/status", Description: "Show authentication status, model and effort"},
 	{Text: "/clear", Description: "Clear conversation context"},
+	{Text: "/new", Description: "Start a fresh conversation (resets token usage)"},
+	{Text: "/model", Description: "Show or switch the model (/model <name>)"},
+	{Text: "/effort", Description: "Show or set reasoning effort (/effort low|medium|high|off)"},
+	{Text: "/config", Description: "Show or set configuration (/config <key> <value>)"},
+	{Text: "/continue", Description: "Continue a response that hit the token limit"},
+	{Text: "/todos", Description: "Toggle the todo panel"},
+	{Text: "/verbose", Description: "Toggle showing every tool result with full inputs and outputs"},
+	{Text: "/scratch", Description: "Open the scratch buffer for notes that are never sent"},
+	{Text: "/debug", Description: "Show recent API requests and responses"},
+	{Text: "/dryrun", Description: "Toggle showing tool calls without executing them"},
+	{Text: "/plain", Description: "Toggle plain input: Enter sends, no vim modes"},
+	{Text: "/ask", Description: "Ask a question the model answers without tools"},
+	{Text: "/confirm", Description: "Toggle confirming each API request with its estimated cost"},
+	{Text: "/hints", Description: "Toggle the key hints below the input"},
+	{Text: "/save-prompt", Description: "Save a prompt template (/save-prompt <name> [text])"},
+	{Text: "/load-prompt", Description: "Load a prompt template into the input (/load-prompt <name>)"},
+	{Text: "/persona", Description: "Switch the system prompt persona (/persona <name>)"},
 	{Text: "/editor", Description: "Open external editor ($EDITOR)"},
 	{Text: "/compact", Description: "Summarize and compact conversation"},
+	{Text: "/find", Description: "Search the chat for text"},
+	{Text: "/export-actions", Description: "Export file edits and commands as a replayable script (/export-actions [path])"},
+	{Text: "/resume", Description: "List or restore this project's saved conversations (/resume all for every project's)"},
+	{Text: "/registers", Description: "Show the input's vim registers (/registers clear to empty them)"},
+	{Text: "/summary", Description: "Summarize conversation without clearing it"},
 	{Text: "/login", Description: "Login to Claude (Max plan or API key)"},
 	{Text: "/logout", Description: "Logout from Claude"},
+	{Text: "/quit", Description: "Quit reapo"},
+}
+
+// builtinAliases are other names for slash commands. More can be added with
+// the command_aliases option.
+var builtinAliases = map[string]string{
+	"/exit": "/quit",
+	"/q":    "/quit",
+}
+
+// SlashCommands returns all available slash commands
+func SlashCommands() []CompletionItem {
+	return append([]CompletionItem(nil), slashCommands...)
+}
+
+// ResolveCommand returns the slash command that name stands for, following
+// the configured and built-in aliases. Commands can't be shadowed, and other
+// names are returned unchanged.
+func ResolveCommand(name string) string {
+	for _, command := range slashCommands {
+		if command.Text == name {
+			return name
+		}
+	}
+	if command, ok := config.Get().CommandAliases[name]; ok {
+		return command
+	}
+	if command, ok := builtinAliases[name]; ok {
+		return command
+	}
+	return name
+}
+
+// aliasItems returns a completion item for each alias, configured aliases
+// replacing built-in ones of the same name
+func aliasItems() []CompletionItem {
+	aliases := make(map[string]string, len(builtinAliases))
+	for alias, command := range builtinAliases {
+		aliases[alias] = command
+	}
+	for alias, command := range config.Get().CommandAliases {
+		aliases[alias] = command
+	}
+
+	items := make([]CompletionItem, 0, len(aliases))
+	for alias := range aliases {
+		if command := ResolveCommand(alias); command != alias {
+			items = append(items, CompletionItem{Text: alias, Description: "Alias for " + command})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Text < items[j].Text })
+	return items
 }
 
 type CompletionEngine struct {
@@ -46,7 +125,43 @@ func (e *CompletionEngine) getSlashCompletions(query string) []CompletionItem {
 		query = query[1:]
 	}
 
-	return FuzzyMatch(query, e.commands)
+	// Complete template names once the command, or an alias for it, has been typed
+	if command, name, ok := strings.Cut(query, " "); ok {
+		switch ResolveCommand("/" + command) {
+		case "/load-prompt":
+			return e.getPromptCompletions(strings.TrimSpace(name))
+		case "/persona":
+			return e.getPersonaCompletions(strings.TrimSpace(name))
+		}
+	}
+
+	return FuzzyMatch(query, append(append([]CompletionItem(nil), e.commands...), aliasItems()...))
+}
+
+func (e *CompletionEngine) getPromptCompletions(query string) []CompletionItem {
+	names, err := prompts.List()
+	if err != nil {
+		return nil
+	}
+
+	items := make([]CompletionItem, 0, len(names))
+	for _, name := range names {
+		items = append(items, CompletionItem{Text: "/load-prompt " + name, Description: "Prompt template"})
+	}
+	return FuzzyMatch("/load-prompt "+query, items)
+}
+
+func (e *CompletionEngine) getPersonaCompletions(query string) []CompletionItem {
+	names, err := personas.List()
+	if err != nil {
+		return nil
+	}
+
+	items := make([]CompletionItem, 0, len(names))
+	for _, name := range names {
+		items = append(items, CompletionItem{Text: "/persona " + name, Description: "Persona"})
+	}
+	return FuzzyMatch("/persona "+query, items)
 }
 
 func (e *CompletionEngine) getFileCompletions(query string) []CompletionItem {
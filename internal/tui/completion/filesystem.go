@@ -11,19 +11,32 @@ const maxCompletionItems = 50
 func GetFileCompletions(workingDir, query string) []CompletionItem {
 	var items []CompletionItem
 
-	// Walk the entire directory tree from working directory
-	err := filepath.WalkDir(workingDir, func(path string, d os.DirEntry, err error) error {
+	// Once a directory has been typed, e.g. "internal/", search inside it
+	root := workingDir
+	dir, name := filepath.Split(query)
+	if dir != "" {
+		scoped := filepath.Join(workingDir, dir)
+		if info, err := os.Stat(scoped); err == nil && info.IsDir() {
+			root = scoped
+			if name == "" {
+				return listDirectory(workingDir, root)
+			}
+		}
+	}
+
+	// Walk the directory tree from the search root
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return nil // Skip errors, continue walking
 		}
 
 		// Skip the root directory itself
-		if path == workingDir {
+		if path == root {
 			return nil
 		}
 
 		// Skip hidden files and directories unless explicitly requested
-		if strings.HasPrefix(d.Name(), ".") && !strings.HasPrefix(query, ".") {
+		if strings.HasPrefix(d.Name(), ".") && !strings.HasPrefix(name, ".") {
 			if d.IsDir() {
 				return filepath.SkipDir // Skip entire hidden directory
 			}
@@ -74,3 +87,34 @@ func GetFileCompletions(workingDir, query string) []CompletionItem {
 
 	return items
 }
+
+// listDirectory lists the entries directly inside dir, directories first,
+// for drilling into a completed directory. Hidden entries are left out.
+func listDirectory(workingDir, dir string) []CompletionItem {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var dirs, files []CompletionItem
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		relPath, err := filepath.Rel(workingDir, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if entry.IsDir() {
+			dirs = append(dirs, CompletionItem{Text: relPath + "/"})
+		} else {
+			files = append(files, CompletionItem{Text: relPath})
+		}
+	}
+
+	items := append(dirs, files...)
+	if len(items) > maxCompletionItems {
+		items = items[:maxCompletionItems]
+	}
+	return items
+}
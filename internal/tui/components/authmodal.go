@@ -8,17 +8,17 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// authModalHelp is the key help shown at the bottom of the auth modal
+const authModalHelp = "Enter to submit • Esc to cancel"
+
 // AuthModal is a modal dialog for authentication input
 type AuthModal struct {
-	active      bool
-	title       string
-	message     string
-	url         string
-	input       textinput.Model
-	width       int
-	height      int
-	onSubmit    func(string) tea.Cmd
-	onCancel    func() tea.Cmd
+	Modal
+	message  string
+	url      string
+	input    textinput.Model
+	onSubmit func(string) tea.Cmd
+	onCancel func() tea.Cmd
 }
 
 // NewAuthModal creates a new auth modal
@@ -27,8 +27,9 @@ func NewAuthModal() AuthModal {
 	ti.Placeholder = "Paste authorization code here..."
 	ti.CharLimit = 256
 	ti.Width = 50
-	
+
 	return AuthModal{
+		Modal: NewModal("", ModalSize{Percent: 85, Min: 60}),
 		input: ti,
 	}
 }
@@ -46,45 +47,32 @@ type AuthModalConfig struct {
 
 // Show displays the modal with the given configuration
 func (m *AuthModal) Show(config AuthModalConfig) tea.Cmd {
-	m.active = true
-	m.title = config.Title
+	m.SetTitle(config.Title)
 	m.message = config.Message
 	m.url = config.URL
-	m.width = config.Width
-	m.height = config.Height
 	m.onSubmit = config.OnSubmit
 	m.onCancel = config.OnCancel
+	m.Open(config.Width, config.Height)
 	m.input.Reset()
 	m.input.Focus()
-	
-	// Calculate initial input width (same as in View)
-	modalWidth := m.width * 85 / 100
-	if modalWidth < 60 {
-		modalWidth = min(60, m.width-4)
-	}
-	m.input.Width = max(10, modalWidth-6)
-	
+	m.input.Width = m.inputWidth()
+
 	return textinput.Blink
 }
 
 // Hide hides the modal
 func (m *AuthModal) Hide() {
-	m.active = false
+	m.Modal.Hide()
 	m.input.Blur()
 	m.input.Reset()
 }
 
-// Active returns whether the modal is currently shown
-func (m AuthModal) Active() bool {
-	return m.active
-}
-
 // Update handles tea messages
 func (m AuthModal) Update(msg tea.Msg) (AuthModal, tea.Cmd) {
-	if !m.active {
+	if !m.IsVisible() {
 		return m, nil
 	}
-	
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
@@ -100,19 +88,12 @@ func (m AuthModal) Update(msg tea.Msg) (AuthModal, tea.Cmd) {
 				return m, m.onCancel()
 			}
 		}
-	
+
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		// Calculate modal width same as in View
-		modalWidth := m.width * 85 / 100
-		if modalWidth < 60 {
-			modalWidth = min(60, m.width-4)
-		}
-		// Adjust input width to fit modal (modal padding is 2 on each side, plus some margin)
-		m.input.Width = max(10, modalWidth-6)
+		m.Resize(msg.Width, msg.Height)
+		m.input.Width = m.inputWidth()
 	}
-	
+
 	// Update the text input
 	var cmd tea.Cmd
 	m.input, cmd = m.input.Update(msg)
@@ -121,108 +102,46 @@ func (m AuthModal) Update(msg tea.Msg) (AuthModal, tea.Cmd) {
 
 // View renders the modal
 func (m AuthModal) View() string {
-	if !m.active {
+	if !m.IsVisible() {
 		return ""
 	}
-	
-	// Handle very small terminals
-	if m.width < 20 || m.height < 10 {
-		return "Terminal too small"
-	}
-	
-	// Calculate modal width - 85% of screen width
-	modalWidth := m.width * 85 / 100
-	// Ensure minimum width for usability
-	if modalWidth < 60 {
-		modalWidth = min(60, m.width-4)
-	}
-	
+
 	// Define styles
-	modalStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("39")).
-		Padding(1, 2).
-		Width(modalWidth)
-	
-	titleStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("39")).
-		MarginBottom(1).
-		Align(lipgloss.Center).
-		Width(modalWidth - 4) // Account for padding
-	
 	messageStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
-		MarginBottom(1).
-		Width(modalWidth - 4)
-	
+		Foreground(lipgloss.Color("241"))
+
 	urlLabelStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("241")).
 		Bold(true)
-	
+
 	urlStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("33")).
-		MarginBottom(1).
-		Width(modalWidth - 4)
-	
-	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
-		MarginTop(1).
-		Align(lipgloss.Center).
-		Width(modalWidth - 4)
-	
+		Foreground(lipgloss.Color("33"))
+
 	// Build content
 	var content strings.Builder
-	
-	if m.title != "" {
-		content.WriteString(titleStyle.Render(m.title))
-		content.WriteString("\n")
-	}
-	
+
 	if m.message != "" {
 		content.WriteString(messageStyle.Render(m.message))
-		content.WriteString("\n")
+		content.WriteString("\n\n")
 	}
-	
+
 	if m.url != "" {
 		// Wrap URL if needed
 		content.WriteString(urlLabelStyle.Render("Visit this URL:"))
 		content.WriteString("\n")
-		wrappedURL := wrapURL(m.url, max(10, modalWidth-4))
-		content.WriteString(urlStyle.Render(wrappedURL))
-		content.WriteString("\n")
+		content.WriteString(urlStyle.Render(wrapURL(m.url, m.ContentWidth())))
+		content.WriteString("\n\n")
 	}
-	
-	// Adjust input width to fit modal
-	m.input.Width = max(10, modalWidth-6)
+
+	m.input.Width = m.inputWidth()
 	content.WriteString(m.input.View())
-	content.WriteString("\n")
-	content.WriteString(helpStyle.Render("Enter to submit • Esc to cancel"))
-	
-	modal := modalStyle.Render(content.String())
-	
-	// Center the modal vertically and horizontally
-	return lipgloss.Place(
-		m.width,
-		m.height,
-		lipgloss.Center,
-		lipgloss.Center,
-		modal,
-	)
-}
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
+	return m.Render(content.String(), authModalHelp)
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
+// inputWidth fits the code input inside the modal, leaving room for its prompt
+func (m AuthModal) inputWidth() int {
+	return max(10, m.ContentWidth()-2)
 }
 
 // wrapURL wraps URL text to fit within the specified width
@@ -248,4 +167,4 @@ func wrapURL(text string, width int) string {
 		}
 	}
 	return result.String()
-}
\ No newline at end of file
+}
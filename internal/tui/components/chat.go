@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"reapo/internal/agent"
 )
 
 // MessageStatus represents the current state of a message
@@ -14,6 +16,7 @@ type MessageStatus string
 const (
 	MessagePending    MessageStatus = "pending"    // User message waiting to be processed
 	MessageProcessing MessageStatus = "processing" // Agent is working on this message
+	MessageStreaming  MessageStatus = "streaming"  // Response text is still arriving
 	MessageCompleted  MessageStatus = "completed"  // Agent finished processing
 	MessageError      MessageStatus = "error"      // Processing failed
 )
@@ -37,12 +40,13 @@ type Progress struct {
 
 // ToolInfo represents information about a tool invocation
 type ToolInfo struct {
-	Name       string // Tool name (e.g., "read_file", "edit_file")
-	Input      string // Tool input parameters (JSON string)
-	Output     string // Tool output/result
-	Error      string // Error message if tool failed
-	Duration   string // How long the tool took to execute
-	ShowOutput bool   // Whether to show the output for this tool
+	Name          string                  // Tool name (e.g., "read_file", "edit_file")
+	Input         string                  // Tool input parameters (JSON string)
+	Output        string                  // Tool output/result
+	Error         string                  // Error message if tool failed
+	ErrorCategory agent.ToolErrorCategory // Why the tool failed, if it did
+	Duration      string                  // How long the tool took to execute
+	ShowOutput    bool                    // Whether to show the output for this tool
 }
 
 // Message represents a chat message
@@ -57,18 +61,22 @@ type Message struct {
 	UpdatedAt time.Time     // Last update time
 	Progress  *Progress     // Optional progress information
 	ToolInfo  *ToolInfo     // Optional tool information for tool-related messages
+	Truncated bool          // Response was cut off at the max_tokens limit
+	Pinned    bool          // Kept verbatim by /compact and /clear
+	Nested    bool          // Activity of the run_task sub-agent, shown indented and not sent to the model
 }
 
 // ShouldShowToolOutput determines if a tool's output should be displayed
 func ShouldShowToolOutput(toolName string) bool {
 	// Tools that should show output
 	outputTools := map[string]bool{
-		"edit_file":  true,
-		"write_file": true,
-		"todoread":   true,
-		"todowrite":  true,
-		"run_task":   true,
-		"list_files": true,
+		"edit_file":           true,
+		"write_file":          true,
+		"search_replace_file": true,
+		"todoread":            true,
+		"todowrite":           true,
+		"run_task":            true,
+		"list_files":          true,
 	}
 
 	// Tools that should only show invocation (no output)
@@ -77,11 +85,29 @@ func ShouldShowToolOutput(toolName string) bool {
 	return outputTools[toolName]
 }
 
+// Tool previews fill this many chat lines, so they scale with the terminal width
+const (
+	inputPreviewLines  = 1
+	outputPreviewLines = 3
+	minPreviewWidth    = 20 // Columns shown even when the chat is very narrow
+	toolPreviewIndent  = 3  // Width of the tool icon the preview is wrapped beside
+)
+
+// nestedIndent is how far sub-agent activity is indented under its run_task call
+const nestedIndent = 4
+
+// streamingCursor follows the text of a response that is still streaming
+const streamingCursor = "▍"
+
 // ChatComponent handles the rendering of chat messages
 type ChatComponent struct {
 	messages []Message
 	height   int
 	width    int
+	focused  int    // Index of the highlighted message, or -1 for none
+	query    string // Text to highlight in messages, empty for none
+	scroll   int    // Lines scrolled back from the newest, when no message is focused
+	verbose  bool   // Show every tool result, with full inputs and outputs
 }
 
 // NewChatComponent creates a new chat component
@@ -90,41 +116,101 @@ func NewChatComponent(messages []Message, height int, width int) *ChatComponent
 		messages: messages,
 		height:   height,
 		width:    width,
+		focused:  -1,
 	}
 }
 
-// Render renders the chat messages with proper styling and scrolling
-func (c *ChatComponent) Render() string {
-	return c.RenderWithSpinners(nil)
+// SetFocused highlights the message at index and keeps it in view (-1 for none)
+func (c *ChatComponent) SetFocused(index int) {
+	c.focused = index
 }
 
-// RenderWithSpinners renders chat messages with spinner support
-func (c *ChatComponent) RenderWithSpinners(spinners map[string]*SpinnerComponent) string {
-	// Styles for bullet points only
-	userBulletStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("4"))       // Blue
-	assistantBulletStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("3"))  // Yellow
-	errorBulletStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))      // Red
-	processingBulletStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6")) // Cyan
+// SetScroll scrolls the chat back by lines from the newest line
+func (c *ChatComponent) SetScroll(lines int) {
+	c.scroll = lines
+}
 
-	// Text style matches input text (default terminal color)
-	textStyle := lipgloss.NewStyle() // No color specified, uses default
+// SetVerbose shows the results of every tool, including those whose output
+// is normally hidden, with their inputs and outputs in full
+func (c *ChatComponent) SetVerbose(verbose bool) {
+	c.verbose = verbose
+}
 
-	// Build chat messages
-	var chatLines []string
-	for i, msg := range c.messages {
-		content := c.renderMessage(msg, spinners, userBulletStyle, assistantBulletStyle, errorBulletStyle, processingBulletStyle, textStyle)
-		chatLines = append(chatLines, content)
+// hidden reports whether a message is left out of the chat: results of
+// tools that don't show output are only shown in verbose mode, or if the
+// tool failed
+func (c *ChatComponent) hidden(msg Message) bool {
+	return !c.verbose && msg.Type == MessageTypeToolResult && msg.ToolInfo != nil && !msg.ToolInfo.ShowOutput && msg.ToolInfo.Error == ""
+}
 
-		// Add empty line between messages (except after the last message)
-		if i < len(c.messages)-1 {
-			chatLines = append(chatLines, "")
+// Transcript renders every message, without limiting it to the chat's height
+func (c *ChatComponent) Transcript() string {
+	chatLines, _ := c.lines(nil)
+	return strings.Join(chatLines, "\n")
+}
+
+// MaxScroll returns how far the chat can be scrolled back before its first
+// line reaches the top
+func (c *ChatComponent) MaxScroll() int {
+	chatLines, _ := c.lines(nil)
+	return max(0, len(chatLines)-c.visibleLines())
+}
+
+// visibleLines returns how many chat lines fit. The last line is left for
+// the padding newline so the input never shares a line with the chat.
+func (c *ChatComponent) visibleLines() int {
+	return max(c.height-1, 1)
+}
+
+// SetHighlight marks every case-insensitive occurrence of query in message text
+func (c *ChatComponent) SetHighlight(query string) {
+	c.query = query
+}
+
+// highlight marks the occurrences of the highlight query in a line of text.
+// Matches split across wrapped lines are not marked.
+func (c *ChatComponent) highlight(line string) string {
+	lower := strings.ToLower(line)
+	if c.query == "" || len(lower) != len(line) {
+		// Lowercasing changed byte offsets, so positions can't be mapped back
+		return line
+	}
+
+	matchStyle := lipgloss.NewStyle().Background(lipgloss.Color("3")).Foreground(lipgloss.Color("0"))
+	query := strings.ToLower(c.query)
+	var result strings.Builder
+	for {
+		i := strings.Index(lower, query)
+		if i < 0 {
+			break
 		}
+		result.WriteString(line[:i])
+		result.WriteString(matchStyle.Render(line[i : i+len(query)]))
+		line, lower = line[i+len(query):], lower[i+len(query):]
 	}
+	result.WriteString(line)
+	return result.String()
+}
+
+// Render renders the chat messages with proper styling and scrolling
+func (c *ChatComponent) Render() string {
+	return c.RenderWithSpinners(nil)
+}
+
+// RenderWithSpinners renders chat messages with spinner support
+func (c *ChatComponent) RenderWithSpinners(spinners map[string]*SpinnerComponent) string {
+	chatLines, focusedStart := c.lines(spinners)
 
-	// Limit chat lines to fit viewport
+	// Limit chat lines to fit viewport, showing the newest lines (less any
+	// scrollback) unless that would scroll the focused message out of view
 	chatHeight := max(c.height, 1)
-	if len(chatLines) > chatHeight {
-		chatLines = chatLines[len(chatLines)-chatHeight:]
+	visibleLines := c.visibleLines()
+	if len(chatLines) > visibleLines {
+		start := max(len(chatLines)-visibleLines-c.scroll, 0)
+		if focusedStart >= 0 && focusedStart < start {
+			start = focusedStart
+		}
+		chatLines = chatLines[start : start+visibleLines]
 	}
 
 	// Pad chat area to fill screen
@@ -143,7 +229,53 @@ func (c *ChatComponent) RenderWithSpinners(spinners map[string]*SpinnerComponent
 	return chat
 }
 
-// wrapText wraps text to fit within the specified width, accounting for prefix length
+// lines renders every message and returns the chat's lines along with the
+// line where the focused message starts (-1 if none is focused)
+func (c *ChatComponent) lines(spinners map[string]*SpinnerComponent) ([]string, int) {
+	// Styles for bullet points only
+	userBulletStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("4"))       // Blue
+	assistantBulletStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("3"))  // Yellow
+	errorBulletStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))      // Red
+	processingBulletStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6")) // Cyan
+
+	// Text style matches input text (default terminal color)
+	textStyle := lipgloss.NewStyle() // No color specified, uses default
+
+	// Focused messages get a left bar, so they are wrapped narrower
+	focusedStyle := lipgloss.NewStyle().
+		Border(lipgloss.ThickBorder(), false, false, false, true).
+		BorderForeground(lipgloss.Color("39")).
+		PaddingLeft(1)
+
+	// Build chat messages, remembering where the focused one starts
+	var chatLines []string
+	focusedStart := -1
+	for i, msg := range c.messages {
+		if c.hidden(msg) {
+			continue
+		}
+		// Add empty line between messages
+		if len(chatLines) > 0 {
+			chatLines = append(chatLines, "")
+		}
+
+		var content string
+		if i == c.focused {
+			focusedStart = len(chatLines)
+			narrow := *c
+			narrow.width = max(c.width-focusedStyle.GetHorizontalFrameSize(), 1)
+			content = focusedStyle.Render(narrow.renderMessage(msg, spinners, userBulletStyle, assistantBulletStyle, errorBulletStyle, processingBulletStyle, textStyle))
+		} else {
+			content = c.renderMessage(msg, spinners, userBulletStyle, assistantBulletStyle, errorBulletStyle, processingBulletStyle, textStyle)
+		}
+		chatLines = append(chatLines, strings.Split(content, "\n")...)
+	}
+	return chatLines, focusedStart
+}
+
+// wrapText wraps text to fit within the specified width, accounting for prefix length.
+// Widths are measured in terminal cells, so wide characters such as CJK and
+// emoji count as two columns, and words wider than a line are broken up.
 func wrapText(text string, width int, prefixLen int) string {
 	if width <= prefixLen {
 		return text // Can't wrap meaningfully
@@ -158,7 +290,7 @@ func wrapText(text string, width int, prefixLen int) string {
 	var wrappedLines []string
 
 	for _, line := range lines {
-		if len(line) <= availableWidth {
+		if lipgloss.Width(line) <= availableWidth {
 			wrappedLines = append(wrappedLines, line)
 			continue
 		}
@@ -167,11 +299,10 @@ func wrapText(text string, width int, prefixLen int) string {
 		var currentLine strings.Builder
 		var currentLen int
 
-		words := strings.Fields(line)
-		for i, word := range words {
-			wordLen := len(word)
+		for _, word := range wrapWords(line, availableWidth) {
+			wordLen := lipgloss.Width(word)
 			spaceLen := 0
-			if i > 0 {
+			if currentLen > 0 {
 				spaceLen = 1 // for the space
 			}
 
@@ -181,7 +312,6 @@ func wrapText(text string, width int, prefixLen int) string {
 				wrappedLines = append(wrappedLines, currentLine.String())
 				currentLine.Reset()
 				currentLen = 0
-				spaceLen = 0
 			}
 
 			// Add space if not the first word on the line
@@ -204,8 +334,33 @@ func wrapText(text string, width int, prefixLen int) string {
 	return strings.Join(wrappedLines, "\n")
 }
 
+// wrapWords splits a line into words, breaking any word wider than width
+// (such as a run of CJK text without spaces) into pieces that fit.
+// Pieces are split between grapheme clusters, so emoji stay intact.
+func wrapWords(line string, width int) []string {
+	var words []string
+	for _, word := range strings.Fields(line) {
+		if lipgloss.Width(word) <= width {
+			words = append(words, word)
+			continue
+		}
+		words = append(words, strings.Split(ansi.Hardwrap(word, width, true), "\n")...)
+	}
+	return words
+}
+
 // renderMessage renders a single message with appropriate status indicators
 func (c *ChatComponent) renderMessage(msg Message, spinners map[string]*SpinnerComponent, userBulletStyle, assistantBulletStyle, errorBulletStyle, processingBulletStyle, textStyle lipgloss.Style) string {
+	// Sub-agent activity is rendered narrower and indented under its task
+	if msg.Nested {
+		nested := *c
+		nested.width = max(c.width-nestedIndent, 1)
+		msg.Nested = false
+		rendered := nested.renderMessage(msg, spinners, userBulletStyle, assistantBulletStyle, errorBulletStyle, processingBulletStyle, textStyle.Faint(true))
+		indent := strings.Repeat(" ", nestedIndent)
+		return indent + strings.ReplaceAll(rendered, "\n", "\n"+indent)
+	}
+
 	// Handle tool-specific messages
 	if msg.Type == MessageTypeToolInvocation || msg.Type == MessageTypeToolResult {
 		return c.renderToolMessage(msg, spinners, textStyle)
@@ -237,7 +392,7 @@ func (c *ChatComponent) renderMessage(msg Message, spinners map[string]*SpinnerC
 	switch msg.Status {
 	case MessagePending:
 		bulletStyle = processingBulletStyle
-	case MessageProcessing:
+	case MessageProcessing, MessageStreaming:
 		bulletStyle = processingBulletStyle
 	case MessageError:
 		bulletStyle = errorBulletStyle
@@ -246,7 +401,7 @@ func (c *ChatComponent) renderMessage(msg Message, spinners map[string]*SpinnerC
 	// Build the complete message content
 	var content string
 	if msg.Status == MessageProcessing && spinners != nil && spinners[msg.ID] != nil {
-		content = msg.Content
+		content = Sanitize(msg.Content)
 		if content == "" && msg.Progress != nil {
 			// For processing messages with no content, show progress inline
 			content = msg.Progress.Description
@@ -254,7 +409,7 @@ func (c *ChatComponent) renderMessage(msg Message, spinners map[string]*SpinnerC
 
 		// Wrap text accounting for bullet + spinner + space
 		spinnerPrefix := prefix + spinners[msg.ID].RenderInline() + " "
-		wrappedContent := wrapText(content, c.width, len(spinnerPrefix))
+		wrappedContent := wrapText(content, c.width, lipgloss.Width(spinnerPrefix))
 
 		// Handle multi-line content with proper indentation
 		lines := strings.Split(wrappedContent, "\n")
@@ -271,32 +426,68 @@ func (c *ChatComponent) renderMessage(msg Message, spinners map[string]*SpinnerC
 		}
 		return result
 	} else {
-		content = msg.Content
+		content = Sanitize(msg.Content)
 		// Add progress information if available
 		if msg.Progress != nil && msg.Status != MessageProcessing {
 			content += fmt.Sprintf("\n   %s", msg.Progress.Description)
 		}
 
-		// Wrap text accounting for bullet
-		wrappedContent := wrapText(content, c.width, len(prefix))
-
-		// Handle multi-line content with proper indentation
-		lines := strings.Split(wrappedContent, "\n")
-		if len(lines) <= 1 {
-			return bulletStyle.Render(prefix) + textStyle.Render(wrappedContent)
+		// Text still streaming in is dimmed and ends in a cursor until it completes
+		if msg.Status == MessageStreaming {
+			textStyle = textStyle.Faint(true)
+			content += streamingCursor
 		}
 
+		// Wrap text accounting for the bullet or the wider continuation indent
+		indent := strings.Repeat(" ", 3) // Fixed indentation for visual alignment
+		wrappedContent := wrapText(content, c.width, max(lipgloss.Width(prefix), len(indent)))
+
 		// First line gets bullet
-		result := bulletStyle.Render(prefix) + textStyle.Render(lines[0])
+		lines := strings.Split(wrappedContent, "\n")
+		result := bulletStyle.Render(prefix) + textStyle.Render(c.highlight(lines[0]))
 		// Subsequent lines get indentation
-		indent := strings.Repeat(" ", 3) // Fixed indentation for visual alignment
 		for _, line := range lines[1:] {
-			result += "\n" + indent + textStyle.Render(line)
+			result += "\n" + indent + textStyle.Render(c.highlight(line))
+		}
+
+		// Mark responses that were cut off at the max_tokens limit
+		if msg.Truncated {
+			note := "(response truncated — /continue to keep going)"
+			if EndsInCodeBlock(msg.Content) {
+				note = "(response cut off inside a code block — /continue to finish it)"
+			}
+			truncatedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Italic(true)
+			result += "\n" + indent + truncatedStyle.Render(note)
+		}
+
+		// Mark messages that survive /compact and /clear
+		if msg.Pinned {
+			pinnedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Italic(true)
+			result += "\n" + indent + pinnedStyle.Render("(pinned)")
 		}
 		return result
 	}
 }
 
+// toolErrorHint suggests what to do about a tool failure of the given category
+func toolErrorHint(category agent.ToolErrorCategory) string {
+	switch category {
+	case agent.ToolErrorNotFound:
+		return "the path doesn't exist; list_files shows what's there"
+	case agent.ToolErrorPermissionDenied:
+		return "check the file's permissions"
+	case agent.ToolErrorInvalidInput:
+		return "the model sent malformed tool input"
+	case agent.ToolErrorNoMatch:
+		return "the file may have changed; read_file shows its current contents"
+	case agent.ToolErrorCancelled:
+		return "aborted by the user; the model continues without this result"
+	case agent.ToolErrorTimeout:
+		return "raise timeout_seconds with /config if the tool needs longer"
+	}
+	return ""
+}
+
 // renderToolMessage renders tool invocation and result messages
 func (c *ChatComponent) renderToolMessage(msg Message, spinners map[string]*SpinnerComponent, textStyle lipgloss.Style) string {
 	// Tool-specific styling
@@ -320,14 +511,14 @@ func (c *ChatComponent) renderToolMessage(msg Message, spinners map[string]*Spin
 
 				// Show input if available (truncated)
 				if msg.ToolInfo.Input != "" && msg.ToolInfo.Input != "{}" {
-					inputPreview := msg.ToolInfo.Input
-					if len(inputPreview) > 100 {
-						inputPreview = inputPreview[:100] + "..."
-					}
-					content += fmt.Sprintf("\n   Input: %s", inputPreview)
+					label := "   Input: "
+					content += "\n" + label + c.toolPreview(msg.ToolInfo.Input, inputPreviewLines, len(label))
 				}
 			} else {
 				content = fmt.Sprintf("Tool: %s", msg.ToolInfo.Name)
+				if c.verbose && msg.ToolInfo.Input != "" && msg.ToolInfo.Input != "{}" {
+					content += "\n   Input: " + Sanitize(msg.ToolInfo.Input)
+				}
 			}
 		} else {
 			content = "Tool invocation"
@@ -336,7 +527,10 @@ func (c *ChatComponent) renderToolMessage(msg Message, spinners map[string]*Spin
 		if msg.ToolInfo != nil && msg.ToolInfo.Error != "" {
 			prefix = "❌ "
 			bulletStyle = toolErrorStyle
-			content = fmt.Sprintf("Tool %s failed: %s", msg.ToolInfo.Name, msg.ToolInfo.Error)
+			content = fmt.Sprintf("Tool %s failed: %s", msg.ToolInfo.Name, Sanitize(msg.ToolInfo.Error))
+			if hint := toolErrorHint(msg.ToolInfo.ErrorCategory); hint != "" {
+				content += "\n   Hint: " + hint
+			}
 		} else {
 			prefix = "✅ "
 			bulletStyle = toolSuccessStyle
@@ -345,13 +539,10 @@ func (c *ChatComponent) renderToolMessage(msg Message, spinners map[string]*Spin
 				content += fmt.Sprintf(" (%s)", msg.ToolInfo.Duration)
 			}
 
-			// Show truncated output if available and tool should show output
-			if msg.ToolInfo != nil && msg.ToolInfo.Output != "" && msg.ToolInfo.ShowOutput {
-				outputPreview := msg.ToolInfo.Output
-				if len(outputPreview) > 200 {
-					outputPreview = outputPreview[:200] + "..."
-				}
-				content += fmt.Sprintf("\n   Result: %s", outputPreview)
+			// Show the output if the tool surfaces it or verbose mode is on
+			if msg.ToolInfo != nil && msg.ToolInfo.Output != "" && (msg.ToolInfo.ShowOutput || c.verbose) {
+				label := "   Result: "
+				content += "\n" + label + c.toolPreview(msg.ToolInfo.Output, outputPreviewLines, len(label))
 			}
 		}
 	}
@@ -360,7 +551,7 @@ func (c *ChatComponent) renderToolMessage(msg Message, spinners map[string]*Spin
 	if msg.Status == MessageProcessing && spinners != nil && spinners[msg.ID] != nil {
 		// Wrap text accounting for prefix + spinner + space
 		spinnerPrefix := prefix + spinners[msg.ID].RenderInline() + " "
-		wrappedContent := wrapText(content, c.width, len(spinnerPrefix))
+		wrappedContent := wrapText(content, c.width, lipgloss.Width(spinnerPrefix))
 
 		// Handle multi-line content with proper indentation
 		lines := strings.Split(wrappedContent, "\n")
@@ -371,14 +562,14 @@ func (c *ChatComponent) renderToolMessage(msg Message, spinners map[string]*Spin
 		// First line gets prefix + spinner
 		result := bulletStyle.Render(prefix) + spinners[msg.ID].RenderInline() + " " + textStyle.Render(lines[0])
 		// Subsequent lines get indentation
-		indent := strings.Repeat(" ", len(prefix)+2) // prefix + spinner width
+		indent := strings.Repeat(" ", lipgloss.Width(spinnerPrefix))
 		for _, line := range lines[1:] {
 			result += "\n" + indent + textStyle.Render(line)
 		}
 		return result
 	} else {
 		// Regular rendering without spinner
-		wrappedContent := wrapText(content, c.width, len(prefix))
+		wrappedContent := wrapText(content, c.width, lipgloss.Width(prefix))
 
 		// Handle multi-line content with proper indentation
 		lines := strings.Split(wrappedContent, "\n")
@@ -389,10 +580,62 @@ func (c *ChatComponent) renderToolMessage(msg Message, spinners map[string]*Spin
 		// First line gets prefix
 		result := bulletStyle.Render(prefix) + textStyle.Render(lines[0])
 		// Subsequent lines get indentation
-		indent := strings.Repeat(" ", len(prefix))
+		indent := strings.Repeat(" ", lipgloss.Width(prefix))
 		for _, line := range lines[1:] {
 			result += "\n" + indent + textStyle.Render(line)
 		}
 		return result
 	}
 }
+
+// toolPreview returns a tool's input or output shortened to the given
+// number of chat lines, or in full in verbose mode
+func (c *ChatComponent) toolPreview(text string, lines, labelWidth int) string {
+	if c.verbose {
+		return Sanitize(text)
+	}
+	return truncatePreview(Sanitize(text), lines, c.previewWidth(lines, labelWidth))
+}
+
+// previewWidth returns how many columns a tool preview may take up: the
+// given number of chat lines, less the indentation and the preview's label
+func (c *ChatComponent) previewWidth(lines, labelWidth int) int {
+	return max(lines*(c.width-toolPreviewIndent)-labelWidth, minPreviewWidth)
+}
+
+// truncatePreview shortens text to at most the given number of lines and
+// width columns in total, marking the cut with "..."
+func truncatePreview(text string, lines, width int) string {
+	if split := strings.SplitN(text, "\n", lines+1); len(split) > lines {
+		text = strings.Join(split[:lines], "\n") + "..."
+	}
+	return ansi.Truncate(text, width, "...")
+}
+
+// EndsInCodeBlock reports whether text ends inside a fenced code block,
+// i.e. a ``` or ~~~ fence was opened and never closed
+func EndsInCodeBlock(text string) bool {
+	fence := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		marker := codeFence(line)
+		switch {
+		case fence == "" && marker != "":
+			fence = marker
+		case fence != "" && strings.HasPrefix(marker, fence) && marker == line:
+			// A closing fence uses the same character, at least as many times, and nothing else
+			fence = ""
+		}
+	}
+	return fence != ""
+}
+
+// codeFence returns the run of three or more backticks or tildes that line
+// starts with, or "" if it doesn't start a fence
+func codeFence(line string) string {
+	if !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "~~~") {
+		return ""
+	}
+	end := len(line) - len(strings.TrimLeft(line, line[:1]))
+	return line[:end]
+}
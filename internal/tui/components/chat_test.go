@@ -0,0 +1,48 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestWrapTextWideCharacters(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "ascii", text: "the quick brown fox jumps over the lazy dog"},
+		{name: "cjk without spaces", text: "日本語のテキストは単語の間に空白がないので長い行になります"},
+		{name: "cjk words", text: "中文 文本 包含 空格 分隔 的 词语 以及 更多 的 内容 在 这里"},
+		{name: "mixed", text: "reapo は 端末 で 動く coding エージェント です and it wraps 한국어 텍스트 too"},
+		{name: "emoji", text: "🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉 party time 👍 👍 👍 👍 👍 👍"},
+		{name: "emoji sequences", text: "👩‍💻👩‍💻👩‍💻👩‍💻👩‍💻👩‍💻👩‍💻👩‍💻 🇯🇵🇯🇵🇯🇵🇯🇵🇯🇵🇯🇵🇯🇵"},
+		{name: "several lines", text: "短い行\n" + strings.Repeat("長", 40) + "\nshort"},
+	}
+
+	for _, tt := range tests {
+		for _, width := range []int{7, 10, 13, 20, 31} {
+			const prefixLen = 2
+			wrapped := wrapText(tt.text, width, prefixLen)
+
+			for i, line := range strings.Split(wrapped, "\n") {
+				if got := lipgloss.Width(line); got > width-prefixLen {
+					t.Errorf("%s at width %d: line %d %q is %d cells wide, want at most %d", tt.name, width, i, line, got, width-prefixLen)
+				}
+			}
+
+			// Wrapping only moves text between lines
+			if got, want := strings.Join(strings.Fields(wrapped), ""), strings.Join(strings.Fields(tt.text), ""); got != want {
+				t.Errorf("%s at width %d: wrapped text %q lost content of %q", tt.name, width, wrapped, tt.text)
+			}
+		}
+	}
+}
+
+func TestWrapTextKeepsShortLines(t *testing.T) {
+	text := "日本語\n\n🎉 ok"
+	if got := wrapText(text, 20, 2); got != text {
+		t.Errorf("wrapText(%q) = %q, want it unchanged", text, got)
+	}
+}
@@ -0,0 +1,153 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"reapo/internal/tui/completion"
+)
+
+// CommandPaletteSelectMsg is sent when an item is chosen from the command palette
+type CommandPaletteSelectMsg struct {
+	Item completion.CompletionItem
+}
+
+// CommandPalette is a fuzzy-searchable modal listing all commands and actions
+type CommandPalette struct {
+	Modal
+	query    string
+	items    []completion.CompletionItem // All available items
+	filtered []completion.CompletionItem // Items matching the query
+	selected int
+}
+
+// NewCommandPalette creates a new command palette
+func NewCommandPalette() *CommandPalette {
+	return &CommandPalette{
+		Modal: NewModal("", DefaultModalSize),
+	}
+}
+
+// Show displays the palette with the given items and an empty query
+func (p *CommandPalette) Show(items []completion.CompletionItem, width, height int) {
+	p.query = ""
+	p.items = items
+	p.filtered = items
+	p.selected = 0
+	p.Open(width, height)
+}
+
+// Update handles tea messages
+func (p CommandPalette) Update(msg tea.Msg) (CommandPalette, tea.Cmd) {
+	if !p.IsVisible() {
+		return p, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			p.Hide()
+			return p, nil
+		case "enter":
+			p.Hide()
+			if p.selected >= len(p.filtered) {
+				return p, nil
+			}
+			item := p.filtered[p.selected]
+			return p, func() tea.Msg {
+				return CommandPaletteSelectMsg{Item: item}
+			}
+		case "up", "ctrl+p":
+			if len(p.filtered) > 0 {
+				p.selected = (p.selected - 1 + len(p.filtered)) % len(p.filtered)
+			}
+		case "down", "ctrl+n", "tab":
+			if len(p.filtered) > 0 {
+				p.selected = (p.selected + 1) % len(p.filtered)
+			}
+		case "backspace":
+			if p.query != "" {
+				runes := []rune(p.query)
+				p.setQuery(string(runes[:len(runes)-1]))
+			}
+		default:
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				p.setQuery(p.query + string(msg.Runes))
+			}
+		}
+	case tea.WindowSizeMsg:
+		p.Resize(msg.Width, msg.Height)
+	}
+
+	return p, nil
+}
+
+// setQuery updates the filter and resets the selection
+func (p *CommandPalette) setQuery(query string) {
+	p.query = query
+	p.filtered = completion.FuzzyMatch(strings.TrimSpace(query), p.items)
+	p.selected = 0
+}
+
+// View renders the palette
+func (p CommandPalette) View() string {
+	if !p.IsVisible() {
+		return ""
+	}
+
+	// Define styles
+	promptStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("39"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("86")).
+		Bold(true)
+
+	descStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241"))
+
+	// Build content
+	var content strings.Builder
+
+	content.WriteString(promptStyle.Render("> ") + p.query + promptStyle.Render("█"))
+	content.WriteString("\n\n")
+
+	if len(p.filtered) == 0 {
+		content.WriteString(descStyle.Render("No matching commands"))
+	}
+
+	// Show a window of items that keeps the selection visible
+	maxItems := max(1, min(10, p.height-10))
+	start := max(0, p.selected-maxItems+1)
+	end := min(len(p.filtered), start+maxItems)
+
+	textWidth := 0
+	for _, item := range p.filtered {
+		textWidth = max(textWidth, len(item.Text))
+	}
+
+	// Truncate descriptions so each item stays on one line
+	descWidth := p.ContentWidth() - 2 - textWidth - 2
+
+	for i := start; i < end; i++ {
+		item := p.filtered[i]
+		text := fmt.Sprintf("%-*s", textWidth, item.Text)
+		description := item.Description
+		if len(description) > descWidth {
+			description = description[:max(0, descWidth-3)] + "..."
+		}
+		if i == p.selected {
+			content.WriteString(selectedStyle.Render("> "+text) + "  " + descStyle.Render(description))
+		} else {
+			content.WriteString("  " + text + "  " + descStyle.Render(description))
+		}
+		if i < end-1 {
+			content.WriteString("\n")
+		}
+	}
+
+	return p.Render(content.String(), "")
+}
@@ -4,14 +4,24 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"reapo/internal/tui/completion"
 )
 
+// Bounds on the completion popup's width, including its border
+const (
+	completionMinWidth = 20
+	completionMaxWidth = 60
+)
+
 type CompletionComponent struct {
-	items    []completion.CompletionItem
-	selected int
-	height   int
-	width    int
+	items       []completion.CompletionItem
+	selected    int
+	height      int
+	width       int // Width of the popup
+	screenWidth int // Width of the terminal
+	anchor      int // Column the popup starts at, if it fits
 }
 
 func NewCompletionComponent(items []completion.CompletionItem, selected int, width int) CompletionComponent {
@@ -22,11 +32,32 @@ func NewCompletionComponent(items []completion.CompletionItem, selected int, wid
 	}
 
 	return CompletionComponent{
-		items:    items,
-		selected: selected,
-		height:   height,
-		width:    width,
+		items:       items,
+		selected:    selected,
+		height:      height,
+		width:       popupWidth(items, width),
+		screenWidth: width,
+	}
+}
+
+// SetAnchor places the popup at column col, e.g. below the character that
+// triggered completion. Near the right edge it is moved left to stay on screen.
+func (c *CompletionComponent) SetAnchor(col int) {
+	c.anchor = col
+}
+
+// popupWidth fits the popup to its widest item, within the width bounds and
+// the terminal
+func popupWidth(items []completion.CompletionItem, screenWidth int) int {
+	width := completionMinWidth
+	for _, item := range items {
+		itemWidth := 2 + lipgloss.Width(item.Text) + 2 // Selection marker and border
+		if item.Description != "" {
+			itemWidth += 2 + lipgloss.Width(item.Description)
+		}
+		width = max(width, itemWidth)
 	}
+	return max(min(width, completionMaxWidth, screenWidth), 3)
 }
 
 func (c CompletionComponent) Render() string {
@@ -65,43 +96,38 @@ func (c CompletionComponent) Render() string {
 			line = fmt.Sprintf("  %s", item.Text)
 		}
 
-		// Add description if present
+		// Add description if present, right-aligned inside the border
+		inner := c.width - 2
 		if item.Description != "" {
-			// Calculate available space for description
-			availableSpace := c.width - len(line) - 2 // 2 for padding
-			if availableSpace > 0 && len(item.Description) > 0 {
-				description := item.Description
-				if len(description) > availableSpace {
-					description = description[:availableSpace-3] + "..."
-				}
-				line = fmt.Sprintf("%-*s %s", c.width-len(description)-2, line, description)
+			// Calculate available space for description; with too little
+			// room for even "..." it is left out
+			availableSpace := inner - lipgloss.Width(line) - 2 // 2 for padding
+			if availableSpace > 3 {
+				description := ansi.Truncate(item.Description, availableSpace, "...")
+				gap := max(inner-lipgloss.Width(line)-lipgloss.Width(description), 1)
+				line += strings.Repeat(" ", gap) + description
 			}
 		}
 
 		// Truncate if too long
-		if len(line) > c.width-2 {
-			line = line[:c.width-5] + "..."
-		}
+		line = ansi.Truncate(line, inner, "...")
 
 		lines = append(lines, line)
 	}
 
-	// Create border
-	if len(lines) > 0 {
-		border := strings.Repeat("─", c.width-2)
-		result := "┌" + border + "┐\n"
-
-		for _, line := range lines {
-			// Pad line to full width
-			paddedLine := fmt.Sprintf("│%-*s│", c.width-2, line)
-			result += paddedLine + "\n"
-		}
+	// Create border, indented to the anchor as far as the screen allows
+	indent := strings.Repeat(" ", max(min(c.anchor, c.screenWidth-c.width), 0))
+	border := strings.Repeat("─", c.width-2)
+	result := indent + "┌" + border + "┐\n"
 
-		result += "└" + border + "┘"
-		return result
+	for _, line := range lines {
+		// Pad line to full width
+		padding := strings.Repeat(" ", max(c.width-2-lipgloss.Width(line), 0))
+		result += indent + "│" + line + padding + "│\n"
 	}
 
-	return ""
+	result += indent + "└" + border + "┘"
+	return result
 }
 
 func (c CompletionComponent) Height() int {
@@ -0,0 +1,88 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"reapo/internal/tui/completion"
+)
+
+// checkPopupFits fails the test if any line of a rendered popup is wider
+// than the screen
+func checkPopupFits(t *testing.T, rendered string, screenWidth int) {
+	t.Helper()
+	for i, line := range strings.Split(rendered, "\n") {
+		if got := lipgloss.Width(line); got > screenWidth {
+			t.Errorf("screen width %d: line %d %q is %d cells wide", screenWidth, i, line, got)
+		}
+	}
+}
+
+func TestCompletionLongDescriptionNarrowScreen(t *testing.T) {
+	items := []completion.CompletionItem{
+		{Text: "/resume", Description: "List or restore this project's saved conversations, or those of every project with all"},
+		{Text: "/help", Description: "Show help"},
+		{Text: "internal/tui/components/completion.go"},
+	}
+
+	for screenWidth := 1; screenWidth <= 100; screenWidth++ {
+		for _, anchor := range []int{0, screenWidth / 2, screenWidth} {
+			c := NewCompletionComponent(items, 0, screenWidth)
+			c.SetAnchor(anchor)
+			rendered := c.Render()
+
+			checkPopupFits(t, rendered, max(screenWidth, 3))
+			if c.width > completionMaxWidth {
+				t.Errorf("screen width %d: popup is %d wide, want at most %d", screenWidth, c.width, completionMaxWidth)
+			}
+			if got := strings.Count(rendered, "\n") + 1; got != c.Height() {
+				t.Errorf("screen width %d: popup has %d lines, want %d", screenWidth, got, c.Height())
+			}
+		}
+	}
+
+	// On a wide screen the long description is cut to the capped width
+	c := NewCompletionComponent(items, 0, 200)
+	rendered := c.Render()
+	if !strings.Contains(rendered, "...") {
+		t.Errorf("long description wasn't truncated:\n%s", rendered)
+	}
+	if c.width != completionMaxWidth {
+		t.Errorf("popup is %d wide on a wide screen, want %d", c.width, completionMaxWidth)
+	}
+}
+
+func TestCompletionDescriptionWidthBoundaries(t *testing.T) {
+	// With a three-character item the room left for its description is the
+	// popup width minus 9: the border, the selection marker and the padding
+	const text = "abc"
+
+	for _, description := range []string{"x", "ab", "abc", "abcd", "abcde", "日本"} {
+		for availableSpace := -8; availableSpace <= 6; availableSpace++ {
+			screenWidth := availableSpace + 9
+			if screenWidth < 1 {
+				continue
+			}
+			items := []completion.CompletionItem{{Text: text, Description: description}}
+			c := NewCompletionComponent(items, 0, screenWidth)
+			if c.width != max(screenWidth, 3) {
+				t.Fatalf("screen width %d: popup is %d wide, want the screen width", screenWidth, c.width)
+			}
+
+			rendered := c.Render()
+			checkPopupFits(t, rendered, c.width)
+
+			// Descriptions are left out without room for at least "..."
+			row := strings.Split(rendered, "\n")[1]
+			_, shown, _ := strings.Cut(row, "> "+text)
+			shown = strings.TrimSpace(strings.TrimSuffix(shown, "│"))
+			if availableSpace <= 3 && shown != "" {
+				t.Errorf("description %q shown with %d columns available: %q", description, availableSpace, row)
+			}
+			if availableSpace > 3 && lipgloss.Width(description) <= availableSpace && shown != description {
+				t.Errorf("description %q missing with %d columns available: %q", description, availableSpace, row)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"reapo/internal/config"
+)
+
+// configModalHelp is the key help shown at the bottom of the config modal
+const configModalHelp = "/config <key> <value> to set • /config save to persist\nPress Esc, Enter, or Space to close"
+
+// ConfigModal is a modal dialog for displaying the effective configuration
+type ConfigModal struct {
+	Modal
+	entries []config.Entry
+	path    string
+}
+
+// NewConfigModal creates a new config modal
+func NewConfigModal() *ConfigModal {
+	return &ConfigModal{
+		Modal: NewModal("Configuration", DefaultModalSize),
+	}
+}
+
+// Show displays the modal with the given configuration entries
+func (m *ConfigModal) Show(entries []config.Entry, path string, width, height int) {
+	m.entries = entries
+	m.path = path
+	m.Open(width, height)
+}
+
+// Update handles tea messages
+func (m ConfigModal) Update(msg tea.Msg) (ConfigModal, tea.Cmd) {
+	if !m.IsVisible() {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.Scroll(msg, m.body(), configModalHelp) {
+			return m, nil
+		}
+		switch msg.Type {
+		case tea.KeyEsc, tea.KeyEnter, tea.KeySpace:
+			m.Hide()
+			return m, nil
+		}
+	case tea.WindowSizeMsg:
+		m.Resize(msg.Width, msg.Height)
+	}
+
+	return m, nil
+}
+
+// View renders the modal
+func (m ConfigModal) View() string {
+	if !m.IsVisible() {
+		return ""
+	}
+	return m.Render(m.body(), configModalHelp)
+}
+
+// body renders the configuration entries, aligned by the longest key
+func (m ConfigModal) body() string {
+	keyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("86"))
+
+	readOnlyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241"))
+
+	keyWidth := 0
+	for _, entry := range m.entries {
+		keyWidth = max(keyWidth, len(entry.Key))
+	}
+
+	lines := make([]string, 0, len(m.entries)+2)
+	for _, entry := range m.entries {
+		key := fmt.Sprintf("%-*s", keyWidth, entry.Key)
+		if entry.Settable {
+			lines = append(lines, keyStyle.Render(key)+"  "+entry.Value)
+		} else {
+			lines = append(lines, readOnlyStyle.Render(key)+"  "+entry.Value+readOnlyStyle.Render(" (restart required)"))
+		}
+	}
+	if m.path != "" {
+		lines = append(lines, "", readOnlyStyle.Render("File: "+m.path))
+	}
+	return strings.Join(lines, "\n")
+}
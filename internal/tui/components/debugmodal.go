@@ -0,0 +1,113 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"reapo/internal/agent"
+)
+
+// debugModalHelp is the key help shown at the bottom of the debug modal
+const debugModalHelp = "Full requests and responses are logged to chat.log by request ID\nPress Esc, Enter, or Space to close"
+
+// DebugModal is a modal dialog summarizing recent API requests and responses
+type DebugModal struct {
+	Modal
+	exchanges []agent.Exchange
+}
+
+// NewDebugModal creates a new debug modal
+func NewDebugModal() *DebugModal {
+	return &DebugModal{
+		Modal: NewModal("Recent API Exchanges", ModalSize{Percent: 80, Min: 40, Max: 100}),
+	}
+}
+
+// Show displays the modal with the given exchanges (oldest first)
+func (m *DebugModal) Show(exchanges []agent.Exchange, width, height int) {
+	m.exchanges = exchanges
+	m.Open(width, height)
+}
+
+// Update handles tea messages
+func (m DebugModal) Update(msg tea.Msg) (DebugModal, tea.Cmd) {
+	if !m.IsVisible() {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.Scroll(msg, m.body(), debugModalHelp) {
+			return m, nil
+		}
+		switch msg.Type {
+		case tea.KeyEsc, tea.KeyEnter, tea.KeySpace:
+			m.Hide()
+			return m, nil
+		}
+	case tea.WindowSizeMsg:
+		m.Resize(msg.Width, msg.Height)
+	}
+
+	return m, nil
+}
+
+// View renders the modal
+func (m DebugModal) View() string {
+	if !m.IsVisible() {
+		return ""
+	}
+	return m.Render(m.body(), debugModalHelp)
+}
+
+// body renders the exchanges newest first, four lines each
+func (m DebugModal) body() string {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("86"))
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241"))
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196"))
+
+	if len(m.exchanges) == 0 {
+		return dimStyle.Render("No API requests yet")
+	}
+
+	var content strings.Builder
+	for i := len(m.exchanges) - 1; i >= 0; i-- {
+		exchange := m.exchanges[i]
+
+		content.WriteString(headerStyle.Render(fmt.Sprintf("#%d %s  %s", i+1, exchange.Time.Format("15:04:05"), exchange.Model)))
+		content.WriteString(dimStyle.Render(fmt.Sprintf(" (%s) %s", exchange.Duration.Round(time.Millisecond), exchange.RequestID)))
+		content.WriteString("\n")
+		content.WriteString(fmt.Sprintf("  request:  %d messages %s", len(exchange.Roles), dimStyle.Render(formatRoles(exchange.Roles))))
+		content.WriteString("\n")
+		if exchange.Err != "" {
+			content.WriteString("  response: " + errorStyle.Render(exchange.Err))
+		} else {
+			response := fmt.Sprintf("  response: stop=%s tokens in=%d out=%d", exchange.StopReason, exchange.InputTokens, exchange.OutputTokens)
+			if len(exchange.ToolUses) > 0 {
+				response += " tools=" + strings.Join(exchange.ToolUses, ", ")
+			}
+			content.WriteString(response)
+		}
+		if i > 0 {
+			content.WriteString("\n\n")
+		}
+	}
+	return content.String()
+}
+
+// formatRoles summarizes a request's message roles, keeping only the most recent
+func formatRoles(roles []string) string {
+	const maxRoles = 4
+	if len(roles) > maxRoles {
+		return "(… " + strings.Join(roles[len(roles)-maxRoles:], " → ") + ")"
+	}
+	return "(" + strings.Join(roles, " → ") + ")"
+}
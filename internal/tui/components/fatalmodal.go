@@ -0,0 +1,131 @@
+package components
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FatalProblem is an error that makes every request fail until the user
+// does something about it
+type FatalProblem struct {
+	Title  string   // What went wrong, e.g. "Authentication failed"
+	Detail string   // The error as the API reported it
+	Steps  []string // How to recover
+	Login  bool     // Whether logging in again can fix it
+	Exit   bool     // Whether it can't be fixed without restarting reapo
+}
+
+// FatalExitMsg is sent when the user acknowledges a problem reapo can't
+// recover from
+type FatalExitMsg struct{}
+
+// FatalModal is a modal dialog explaining an error that stops every request
+// and how to recover. Problems that need a restart quit once acknowledged.
+type FatalModal struct {
+	Modal
+	problem FatalProblem
+}
+
+// NewFatalModal creates a new fatal error modal
+func NewFatalModal() *FatalModal {
+	return &FatalModal{
+		Modal: NewModal("Requests are failing", DefaultModalSize),
+	}
+}
+
+// Show displays the modal for problem
+func (m *FatalModal) Show(problem FatalProblem, width, height int) {
+	m.problem = problem
+	m.SetTitle(problem.Title)
+	m.Open(width, height)
+}
+
+// Problem returns the problem the modal was last shown for
+func (m FatalModal) Problem() FatalProblem {
+	return m.problem
+}
+
+// Update handles tea messages
+func (m FatalModal) Update(msg tea.Msg) (FatalModal, tea.Cmd) {
+	if !m.IsVisible() {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.Scroll(msg, m.body(), m.help()) {
+			return m, nil
+		}
+		switch {
+		case m.problem.Exit && (msg.Type == tea.KeyEnter || msg.Type == tea.KeyEsc):
+			m.Hide()
+			return m, func() tea.Msg {
+				return FatalExitMsg{}
+			}
+		case m.problem.Login && msg.Type == tea.KeyEnter:
+			m.Hide()
+			return m, func() tea.Msg {
+				return OnboardingLoginMsg{}
+			}
+		case msg.Type == tea.KeyEnter || msg.Type == tea.KeyEsc:
+			m.Hide()
+			return m, nil
+		}
+	case tea.WindowSizeMsg:
+		m.Resize(msg.Width, msg.Height)
+	}
+
+	return m, nil
+}
+
+// View renders the modal
+func (m FatalModal) View() string {
+	if !m.IsVisible() {
+		return ""
+	}
+	return m.Render(m.body(), m.help())
+}
+
+// help returns the key help for the problem shown
+func (m FatalModal) help() string {
+	switch {
+	case m.problem.Exit:
+		return "Press Enter or Esc to quit reapo"
+	case m.problem.Login:
+		return "Press Enter to log in now, or Esc to close"
+	default:
+		return "Press Enter or Esc to close"
+	}
+}
+
+// body renders the modal's content
+func (m FatalModal) body() string {
+	commandStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("86"))
+	detailStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	var content strings.Builder
+	content.WriteString("Every request will fail until this is fixed:")
+	content.WriteString("\n\n")
+	content.WriteString(detailStyle.Render(Sanitize(m.problem.Detail)))
+	content.WriteString("\n\n")
+	content.WriteString("To recover:")
+	for _, step := range m.problem.Steps {
+		content.WriteString("\n")
+		content.WriteString("• " + highlightCommands(step, commandStyle))
+	}
+	return content.String()
+}
+
+// highlightCommands styles the slash commands, flags and environment
+// variables in a recovery step
+func highlightCommands(step string, style lipgloss.Style) string {
+	words := strings.Split(step, " ")
+	for i, word := range words {
+		if strings.HasPrefix(word, "/") || strings.HasPrefix(word, "--") || word == "ANTHROPIC_API_KEY" {
+			words[i] = style.Render(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -16,6 +16,9 @@ type FooterComponent struct {
 	contextTokens    int
 	maxContextTokens int
 	modelName        string
+	gitBranch        string
+	gitDirty         bool
+	persona          string
 }
 
 // NewFooterComponent creates a new footer component
@@ -64,11 +67,23 @@ func (f *FooterComponent) Render() string {
 	}
 	
 	leftText := "reapo"
+	if f.persona != "" {
+		leftText += " (" + f.persona + ")"
+	}
 	rightText := f.modelName
 
+	// Git branch, with * when the work tree has changes; omitted outside a repository
+	branchText := f.gitBranch
+	if branchText != "" && f.gitDirty {
+		branchText += "*"
+	}
+
 	// Build the sections with proper spacing
-	// Layout: reapo | pwd | context | model
+	// Layout: reapo | pwd | [branch] | context | model
 	sections := []string{leftText, pwd, contextText, rightText}
+	if branchText != "" {
+		sections = []string{leftText, pwd, branchText, contextText, rightText}
+	}
 	
 	// Calculate spacing between sections
 	totalContentWidth := 0
@@ -116,8 +131,20 @@ func (f *FooterComponent) Render() string {
 		Background(lipgloss.Color("236")).
 		Render(separator)
 	
+	var styledBranch string
+	if branchText != "" {
+		branchColor := "245"
+		if f.gitDirty {
+			branchColor = "3" // Yellow
+		}
+		styledBranch = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(branchColor)).
+			Background(lipgloss.Color("236")).
+			Render(branchText) + styledSeparator
+	}
+
 	// Compose the footer
-	composedFooter := styledLeft + styledSeparator + styledPwd + styledSeparator + contextStyled + styledSeparator + styledRight
+	composedFooter := styledLeft + styledSeparator + styledPwd + styledSeparator + styledBranch + contextStyled + styledSeparator + styledRight
 	
 	// Ensure the footer fills the entire width with padding
 	paddingNeeded := remainingWidth - lipgloss.Width(composedFooter) - 2 // -2 for left/right padding
@@ -144,6 +171,17 @@ func (f *FooterComponent) UpdateContextInfo(contextTokens, maxContextTokens int,
 	f.modelName = modelName
 }
 
+// SetGitStatus sets the git branch shown in the footer; an empty branch hides it
+func (f *FooterComponent) SetGitStatus(branch string, dirty bool) {
+	f.gitBranch = branch
+	f.gitDirty = dirty
+}
+
+// SetPersona sets the persona shown next to the app name; empty shows none
+func (f *FooterComponent) SetPersona(persona string) {
+	f.persona = persona
+}
+
 // formatTokenCount formats token count with k suffix for thousands
 func formatTokenCount(tokens int) string {
 	if tokens >= 1000 {
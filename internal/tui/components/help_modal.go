@@ -3,54 +3,64 @@ package components
 import (
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// helpModalHelp is the key help shown at the bottom of the help modal
+const helpModalHelp = "Press Esc to close this help"
+
 // HelpModal represents a help modal showing available commands
 type HelpModal struct {
-	visible bool
+	Modal
 }
 
 // NewHelpModal creates a new help modal
 func NewHelpModal() *HelpModal {
 	return &HelpModal{
-		visible: false,
+		Modal: NewModal("Reapo Help", ModalSize{Percent: 80, Min: 40, Max: 110}),
 	}
 }
 
 // Show makes the help modal visible
-func (h *HelpModal) Show() {
-	h.visible = true
+func (h *HelpModal) Show(width, height int) {
+	h.Open(width, height)
 }
 
-// Hide makes the help modal invisible
-func (h *HelpModal) Hide() {
-	h.visible = false
-}
+// Update handles tea messages
+func (h HelpModal) Update(msg tea.Msg) (HelpModal, tea.Cmd) {
+	if !h.IsVisible() {
+		return h, nil
+	}
 
-// IsVisible returns whether the modal is visible
-func (h *HelpModal) IsVisible() bool {
-	return h.visible
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if h.Scroll(msg, h.body(), helpModalHelp) {
+			return h, nil
+		}
+		switch msg.Type {
+		case tea.KeyEsc, tea.KeyEnter, tea.KeySpace:
+			h.Hide()
+			return h, nil
+		}
+	case tea.WindowSizeMsg:
+		h.Resize(msg.Width, msg.Height)
+	}
+
+	return h, nil
 }
 
 // View renders the help modal
-func (h *HelpModal) View() string {
-	if !h.visible {
+func (h HelpModal) View() string {
+	if !h.IsVisible() {
 		return ""
 	}
+	return h.Render(h.body(), helpModalHelp)
+}
 
+// body lists the commands, modes and key bindings
+func (h HelpModal) body() string {
 	// Define styles
-	modalStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
-		Padding(1, 2).
-		Background(lipgloss.Color("235"))
-
-	titleStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("214")).
-		MarginBottom(1)
-
 	commandStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("86"))
 
@@ -63,18 +73,62 @@ func (h *HelpModal) View() string {
 
 	// Build content
 	var content strings.Builder
-	content.WriteString(titleStyle.Render("Reapo Help"))
-	content.WriteString("\n\n")
 
 	content.WriteString(keyStyle.Render("Slash Commands:"))
 	content.WriteString("\n")
 	content.WriteString(commandStyle.Render("/help") + " - " + descStyle.Render("Show this help menu"))
 	content.WriteString("\n")
-	content.WriteString(commandStyle.Render("/clear") + " - " + descStyle.Render("Clear conversation history"))
+	content.WriteString(commandStyle.Render("/clear") + " - " + descStyle.Render("Clear the displayed conversation history (Ctrl+Z to undo)"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/new") + " - " + descStyle.Render("Start a fresh conversation, resetting context and token usage"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/model [name]") + " - " + descStyle.Render("Show the active model or switch to another"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/effort [low|medium|high|off]") + " - " + descStyle.Render("Show or set how long the model thinks before answering"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/config [key value]") + " - " + descStyle.Render("Show configuration or set a runtime option"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/continue") + " - " + descStyle.Render("Continue a response that was cut off at the token limit"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/todos") + " - " + descStyle.Render("Toggle a panel showing the agent's todo list"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/scratch") + " - " + descStyle.Render("Open a per-project scratch buffer for notes that are never sent (Ctrl+N)"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/verbose") + " - " + descStyle.Render("Toggle showing every tool result, including read_file, with full inputs and outputs (Ctrl+O)"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/debug") + " - " + descStyle.Render("Show recent API requests, tool uses and token usage"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/dryrun") + " - " + descStyle.Render("Toggle showing tool calls without executing them"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/plain") + " - " + descStyle.Render("Toggle plain input: Enter sends, Alt+Enter for newline, no vim modes"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/ask <question>") + " - " + descStyle.Render("Ask a question the model answers directly, without calling tools"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/confirm") + " - " + descStyle.Render("Toggle asking before each API request, with its estimated tokens and cost"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/hints") + " - " + descStyle.Render("Toggle the bar showing the keys for the current input mode"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/save-prompt <name> [text]") + " - " + descStyle.Render("Save text, or your last message, as a prompt template"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/load-prompt <name>") + " - " + descStyle.Render("Load a prompt template into the input"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/persona [name]") + " - " + descStyle.Render("Switch the system prompt to a persona from the config personas directory"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/find <text>") + " - " + descStyle.Render("Highlight text in the chat and jump between matches"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/export-actions [path]") + " - " + descStyle.Render("Write the session's file edits and commands to a script to review or replay"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/resume [all] [number]") + " - " + descStyle.Render("List this project's saved conversations, or every project's with all, or restore one (start reapo with --continue to restore the latest)"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/registers [clear]") + " - " + descStyle.Render("Show the vim registers yanked into, or empty them"))
 	content.WriteString("\n")
 	content.WriteString(commandStyle.Render("/editor") + " - " + descStyle.Render("Quick access to $EDITOR"))
 	content.WriteString("\n")
 	content.WriteString(commandStyle.Render("/compact") + " - " + descStyle.Render("Summarize and compact conversation"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/summary") + " - " + descStyle.Render("Summarize conversation without clearing it"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("/quit") + " - " + descStyle.Render("Quit reapo (also /exit, /q, or any name in command_aliases)"))
 	content.WriteString("\n\n")
 
 	content.WriteString(keyStyle.Render("Vim Modes:"))
@@ -92,6 +146,32 @@ func (h *HelpModal) View() string {
 	content.WriteString("\n")
 	content.WriteString(commandStyle.Render("Ctrl+S (Insert/Visual)") + " - " + descStyle.Render("Send message"))
 	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+W / Ctrl+U (Insert)") + " - " + descStyle.Render("Delete the previous word / to start of line"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+A / Ctrl+E (Insert)") + " - " + descStyle.Render("Move to start / end of line"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+P") + " - " + descStyle.Render("Open the command palette"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+V (Insert) / \"+p (Normal)") + " - " + descStyle.Render("Paste from the system clipboard"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("\"a-\"z (Normal/Visual)") + " - " + descStyle.Render("Yank, delete or paste with a named register, e.g. \"ayy then \"ap; \"A appends"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Tab (Normal)") + " - " + descStyle.Render("Focus the chat to scroll it with j/k; Tab or Esc returns to the input"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+K") + " - " + descStyle.Render("Select an earlier message to quote (j/k, Enter), edit and regenerate (e) or pin it (p)"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+X") + " - " + descStyle.Render("Abort the running tool; the model continues without its result"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+N") + " - " + descStyle.Render("Switch between the input and the scratch buffer; sending from it copies it to the input"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+O") + " - " + descStyle.Render("Toggle verbose tool output (/verbose)"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+L") + " - " + descStyle.Render("Redraw the screen"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("Ctrl+F") + " - " + descStyle.Render("Search the chat (/find); n/N step through matches"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("F2") + " - " + descStyle.Render("Switch to the previously used model"))
+	content.WriteString("\n")
 	content.WriteString(commandStyle.Render("Ctrl+C") + " - " + descStyle.Render("Exit application"))
 	content.WriteString("\n")
 	content.WriteString(commandStyle.Render("Esc") + " - " + descStyle.Render("Return to Normal mode / Close modal"))
@@ -100,9 +180,10 @@ func (h *HelpModal) View() string {
 	content.WriteString(keyStyle.Render("File References:"))
 	content.WriteString("\n")
 	content.WriteString(commandStyle.Render("@filename") + " - " + descStyle.Render("Reference a file (with completion)"))
-	content.WriteString("\n\n")
-
-	content.WriteString(descStyle.Render("Press Esc to close this help"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("@filename:10-40") + " - " + descStyle.Render("Reference only lines 10 to 40 of a file"))
+	content.WriteString("\n")
+	content.WriteString(commandStyle.Render("@!command") + " - " + descStyle.Render("Inline a command's output (requires REAPO_ENABLE_SHELL=1)"))
 
-	return modalStyle.Render(content.String())
+	return content.String()
 }
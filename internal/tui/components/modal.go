@@ -0,0 +1,200 @@
+package components
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Smallest terminal a modal can be drawn in
+const (
+	modalMinTerminalWidth  = 20
+	modalMinTerminalHeight = 10
+)
+
+// modalChrome is the height taken by a modal's border and vertical padding
+const modalChrome = 4
+
+// ModalSize bounds a modal's width: Percent of the terminal width, raised to
+// at least Min (while it fits) and capped at Max (0 for no cap)
+type ModalSize struct {
+	Percent int
+	Min     int
+	Max     int
+}
+
+// DefaultModalSize is the width used by most modals
+var DefaultModalSize = ModalSize{Percent: 60, Min: 40, Max: 80}
+
+// Modal is the frame shared by the modal dialogs. It tracks visibility and
+// the terminal size, and renders a titled, bordered box centered on the
+// screen, scrolling the body when it is taller than the terminal.
+// Dialogs embed it and supply their body and help line.
+type Modal struct {
+	title   string
+	size    ModalSize
+	visible bool
+	width   int
+	height  int
+	offset  int // First body line shown when the body scrolls
+}
+
+// NewModal creates a hidden modal frame
+func NewModal(title string, size ModalSize) Modal {
+	return Modal{title: title, size: size}
+}
+
+// Open shows the modal for a terminal of the given size, scrolled to the top
+func (m *Modal) Open(width, height int) {
+	m.visible = true
+	m.width = width
+	m.height = height
+	m.offset = 0
+}
+
+// Hide hides the modal
+func (m *Modal) Hide() {
+	m.visible = false
+}
+
+// IsVisible returns whether the modal is currently shown
+func (m Modal) IsVisible() bool {
+	return m.visible
+}
+
+// SetTitle changes the title shown at the top of the modal
+func (m *Modal) SetTitle(title string) {
+	m.title = title
+}
+
+// Resize records a new terminal size
+func (m *Modal) Resize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Width returns the width of the modal box for the current terminal
+func (m Modal) Width() int {
+	width := m.width * m.size.Percent / 100
+	if width < m.size.Min {
+		width = min(m.size.Min, m.width-4)
+	}
+	if m.size.Max > 0 && width > m.size.Max {
+		width = m.size.Max
+	}
+	return width
+}
+
+// ContentWidth returns the width available inside the border and padding
+func (m Modal) ContentWidth() int {
+	return max(1, m.Width()-4)
+}
+
+// Scroll moves through a body taller than the terminal with the arrow, page
+// and j/k/g/G keys. It reports whether the key was used.
+func (m *Modal) Scroll(msg tea.KeyMsg, body, help string) bool {
+	lines, visible := m.layout(body, help)
+	maxOffset := max(0, len(lines)-visible)
+	if maxOffset == 0 {
+		return false
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.offset--
+	case "down", "j":
+		m.offset++
+	case "pgup", "ctrl+u":
+		m.offset -= visible
+	case "pgdown", "ctrl+d":
+		m.offset += visible
+	case "home", "g":
+		m.offset = 0
+	case "end", "G":
+		m.offset = maxOffset
+	default:
+		return false
+	}
+	m.offset = min(max(m.offset, 0), maxOffset)
+	return true
+}
+
+// Render draws the modal around body with help below it, centered on the screen
+func (m Modal) Render(body, help string) string {
+	if m.width < modalMinTerminalWidth || m.height < modalMinTerminalHeight {
+		return "Terminal too small"
+	}
+
+	lines, visible := m.layout(body, help)
+	if len(lines) > visible {
+		offset := min(m.offset, len(lines)-visible)
+		lines = lines[offset : offset+visible]
+		help = scrollHelp(help)
+	}
+
+	var content strings.Builder
+	if m.title != "" {
+		content.WriteString(m.titleStyle().Render(m.title))
+		content.WriteString("\n")
+	}
+	content.WriteString(strings.Join(lines, "\n"))
+	if help != "" {
+		content.WriteString("\n")
+		content.WriteString(m.helpStyle().Render(help))
+	}
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Width(m.Width())
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content.String()),
+	)
+}
+
+// layout wraps body to the content width and returns its lines along with
+// how many of them fit on screen beside the title and help
+func (m Modal) layout(body, help string) ([]string, int) {
+	lines := strings.Split(lipgloss.NewStyle().Width(m.ContentWidth()).Render(body), "\n")
+
+	reserved := modalChrome
+	if m.title != "" {
+		reserved += lipgloss.Height(m.titleStyle().Render(m.title))
+	}
+	if help != "" {
+		// Measure with the scroll hint, which is added when the body doesn't fit
+		reserved += lipgloss.Height(m.helpStyle().Render(scrollHelp(help)))
+	}
+	return lines, max(1, m.height-reserved)
+}
+
+// titleStyle is the centered title at the top of the modal
+func (m Modal) titleStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		MarginBottom(1).
+		Align(lipgloss.Center).
+		Width(m.ContentWidth())
+}
+
+// helpStyle is the dim, centered key help at the bottom of the modal
+func (m Modal) helpStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		MarginTop(1).
+		Align(lipgloss.Center).
+		Width(m.ContentWidth())
+}
+
+// scrollHelp adds the scroll keys to a modal's help line
+func scrollHelp(help string) string {
+	return help + " • ↑/↓ to scroll"
+}
@@ -0,0 +1,75 @@
+package components
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// onboardingModalHelp is the key help shown at the bottom of the onboarding modal
+const onboardingModalHelp = "Press Enter to log in now, or Esc to close"
+
+// OnboardingLoginMsg is sent when the user chooses to log in from the onboarding modal
+type OnboardingLoginMsg struct{}
+
+// OnboardingModal is a modal dialog explaining how to authenticate on first run
+type OnboardingModal struct {
+	Modal
+}
+
+// NewOnboardingModal creates a new onboarding modal
+func NewOnboardingModal() *OnboardingModal {
+	return &OnboardingModal{
+		Modal: NewModal("Welcome to reapo", DefaultModalSize),
+	}
+}
+
+// Show displays the modal
+func (m *OnboardingModal) Show(width, height int) {
+	m.Open(width, height)
+}
+
+// Update handles tea messages
+func (m OnboardingModal) Update(msg tea.Msg) (OnboardingModal, tea.Cmd) {
+	if !m.IsVisible() {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.Hide()
+			return m, func() tea.Msg {
+				return OnboardingLoginMsg{}
+			}
+		case tea.KeyEsc:
+			m.Hide()
+			return m, nil
+		}
+	case tea.WindowSizeMsg:
+		m.Resize(msg.Width, msg.Height)
+	}
+
+	return m, nil
+}
+
+// View renders the modal
+func (m OnboardingModal) View() string {
+	if !m.IsVisible() {
+		return ""
+	}
+
+	commandStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("86"))
+
+	var content strings.Builder
+	content.WriteString("No authentication is configured yet. Choose one of:")
+	content.WriteString("\n\n")
+	content.WriteString("• " + commandStyle.Render("/login") + " to sign in with your Claude Max account")
+	content.WriteString("\n")
+	content.WriteString("• Set " + commandStyle.Render("ANTHROPIC_API_KEY") + " in your environment and restart reapo")
+
+	return m.Render(content.String(), onboardingModalHelp)
+}
@@ -0,0 +1,80 @@
+package components
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// tabStop is the width tabs are expanded to in rendered chat text
+const tabStop = 4
+
+// Sanitize makes text from the model or a tool safe to lay out in the chat.
+// ANSI escape sequences are stripped, a carriage return keeps only the text
+// written after it (as a terminal would show a progress line), tabs become
+// spaces, and other control characters and invisible formatting characters
+// that would throw off width measurement are removed.
+func Sanitize(text string) string {
+	if isPlainText(text) {
+		return text
+	}
+
+	text = strings.ToValidUTF8(text, "\uFFFD")
+	text = ansi.Strip(text)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if cr := strings.LastIndexByte(line, '\r'); cr >= 0 {
+			line = line[cr+1:]
+		}
+		lines[i] = strings.Map(sanitizeRune, expandTabs(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isPlainText reports whether text has nothing for Sanitize to change
+func isPlainText(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if c := text[i]; c >= utf8.RuneSelf || (c < 0x20 && c != '\n') || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			spaces := tabStop - col%tabStop
+			b.WriteString(strings.Repeat(" ", spaces))
+			col += spaces
+			continue
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
+
+// sanitizeRune drops control characters and zero-width formatting characters.
+// The zero-width joiner and non-joiner are kept since emoji sequences and
+// some scripts depend on them.
+func sanitizeRune(r rune) rune {
+	switch {
+	case r < 0x20, r >= 0x7f && r < 0xa0: // C0 and C1 controls, DEL
+		return -1
+	case r == '\u200b', r == '\u2060', r == '\ufeff': // Zero-width space, word joiner, BOM
+		return -1
+	case r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069', r == '\u200e', r == '\u200f': // Bidi controls
+		return -1
+	}
+	return r
+}
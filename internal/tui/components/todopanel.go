@@ -0,0 +1,83 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"reapo/internal/tools"
+)
+
+// TodoPanel renders the agent's todo list as a side panel
+type TodoPanel struct {
+	todos  []tools.Todo
+	width  int
+	height int
+}
+
+// NewTodoPanel creates a todo panel for the given todos and size
+func NewTodoPanel(todos []tools.Todo, width, height int) TodoPanel {
+	return TodoPanel{
+		todos:  todos,
+		width:  width,
+		height: height,
+	}
+}
+
+// Render renders the panel
+func (p TodoPanel) Render() string {
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Padding(0, 1).
+		Width(p.width - 2).
+		Height(max(p.height-2, 1))
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("214"))
+
+	doneStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Strikethrough(true)
+
+	priorityStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("1"))
+
+	completed := 0
+	for _, todo := range p.todos {
+		if todo.Completed {
+			completed++
+		}
+	}
+
+	var lines []string
+	lines = append(lines, titleStyle.Render(fmt.Sprintf("Todos (%d/%d)", completed, len(p.todos))))
+	if len(p.todos) == 0 {
+		lines = append(lines, doneStyle.UnsetStrikethrough().Render("No todos yet"))
+	}
+
+	// Width available for text inside the border and padding
+	textWidth := max(p.width-4-4, 1)
+	for _, todo := range p.todos {
+		text := todo.Text
+		if len(text) > textWidth {
+			text = text[:max(textWidth-3, 0)] + "..."
+		}
+
+		if todo.Completed {
+			lines = append(lines, "[x] "+doneStyle.Render(text))
+		} else if todo.Priority == "high" {
+			lines = append(lines, priorityStyle.Render("[!] ")+text)
+		} else {
+			lines = append(lines, "[ ] "+text)
+		}
+	}
+
+	// Keep within the panel height, leaving room for the border
+	if maxLines := max(p.height-2, 1); len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+
+	return borderStyle.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,48 @@
+package vimtextarea
+
+import (
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ClipboardPasteMsg carries the system clipboard contents read for a paste
+type ClipboardPasteMsg struct {
+	Text   string
+	Before bool  // Paste before the cursor ("+P)
+	Err    error // Set if the clipboard could not be read
+}
+
+// readClipboard reads the system clipboard in the background
+func readClipboard(before bool) tea.Cmd {
+	return func() tea.Msg {
+		text, err := clipboard.ReadAll()
+		return ClipboardPasteMsg{Text: text, Before: before, Err: err}
+	}
+}
+
+// handleClipboardPaste inserts clipboard text as a single block. In Insert mode
+// it goes in at the cursor without triggering completion; in Normal mode it
+// behaves like p/P.
+func (m Model) handleClipboardPaste(msg ClipboardPasteMsg) Model {
+	if msg.Err != nil || msg.Text == "" {
+		return m
+	}
+
+	switch m.mode {
+	case Insert:
+		m = m.insertBlock(msg.Text)
+	case Normal:
+		// Text copied as whole lines ends with a newline; paste it linewise
+		text := strings.TrimSuffix(strings.ReplaceAll(msg.Text, "\r\n", "\n"), "\n")
+		if msg.Before {
+			m = m.pasteBefore(text)
+		} else {
+			m = m.pasteAfter(text)
+		}
+	}
+
+	m.cursor = m.validateCursor(m.cursor)
+	return m
+}
@@ -20,6 +20,16 @@ func (m Model) insertText(text string) Model {
 	return m
 }
 
+// indentFor returns the leading whitespace of the given line when autoindent
+// is enabled, or an empty string otherwise
+func (m Model) indentFor(row int) string {
+	if !m.autoIndent || row < 0 || row >= len(m.content) {
+		return ""
+	}
+	line := m.content[row]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
 func (m Model) insertNewLine() Model {
 	if m.cursor.Row >= len(m.content) {
 		return m
@@ -29,25 +39,30 @@ func (m Model) insertNewLine() Model {
 	before := line[:m.cursor.Col]
 	after := line[m.cursor.Col:]
 
+	// Splitting inside the indentation moves it down as-is
+	indent := m.indentFor(m.cursor.Row)
+	if len(indent) > len(before) {
+		indent = ""
+	} else if indent != "" {
+		after = strings.TrimLeft(after, " \t")
+	}
+
 	m.content[m.cursor.Row] = before
 
 	newContent := make([]string, len(m.content)+1)
 	copy(newContent[:m.cursor.Row+1], m.content[:m.cursor.Row+1])
-	newContent[m.cursor.Row+1] = after
+	newContent[m.cursor.Row+1] = indent + after
 	copy(newContent[m.cursor.Row+2:], m.content[m.cursor.Row+1:])
 
 	m.content = newContent
 	m.cursor.Row++
-	m.cursor.Col = 0
-	m.adjustScroll()
+	m.cursor.Col = len(indent)
+	m = m.adjustScroll()
 	return m
 }
 
 func (m Model) insertNewLineBelow() Model {
-	line := ""
-	if m.cursor.Row < len(m.content) {
-		line = ""
-	}
+	line := m.indentFor(m.cursor.Row)
 
 	newContent := make([]string, len(m.content)+1)
 	copy(newContent[:m.cursor.Row+1], m.content[:m.cursor.Row+1])
@@ -56,20 +71,22 @@ func (m Model) insertNewLineBelow() Model {
 
 	m.content = newContent
 	m.cursor.Row++
-	m.cursor.Col = 0
-	m.adjustScroll()
+	m.cursor.Col = len(line)
+	m = m.adjustScroll()
 	return m
 }
 
 func (m Model) insertNewLineAbove() Model {
+	line := m.indentFor(m.cursor.Row)
+
 	newContent := make([]string, len(m.content)+1)
 	copy(newContent[:m.cursor.Row], m.content[:m.cursor.Row])
-	newContent[m.cursor.Row] = ""
+	newContent[m.cursor.Row] = line
 	copy(newContent[m.cursor.Row+1:], m.content[m.cursor.Row:])
 
 	m.content = newContent
-	m.cursor.Col = 0
-	m.adjustScroll()
+	m.cursor.Col = len(line)
+	m = m.adjustScroll()
 	return m
 }
 
@@ -100,6 +117,80 @@ func (m Model) backspace() Model {
 	return m
 }
 
+// deleteWordBackward deletes the word before the cursor, like vim's Insert mode
+// ctrl+w. Whitespace before the word is deleted with it; at the start of a line
+// it joins with the previous line instead.
+func (m Model) deleteWordBackward() Model {
+	if m.cursor.Row >= len(m.content) {
+		return m
+	}
+	if m.cursor.Col == 0 {
+		return m.backspace()
+	}
+
+	start := m.prevWord(m.cursor, false)
+	if start.Row != m.cursor.Row {
+		// Only whitespace before the cursor - delete it but stay on this line
+		start = Position{Row: m.cursor.Row, Col: 0}
+	}
+
+	line := m.content[m.cursor.Row]
+	m.content[m.cursor.Row] = line[:start.Col] + line[m.cursor.Col:]
+	m.cursor.Col = start.Col
+	m = m.adjustScroll()
+	return m
+}
+
+// deleteToLineStart deletes from the start of the line to the cursor, like
+// vim's Insert mode ctrl+u. At the start of a line it joins with the previous line.
+func (m Model) deleteToLineStart() Model {
+	if m.cursor.Row >= len(m.content) {
+		return m
+	}
+	if m.cursor.Col == 0 {
+		return m.backspace()
+	}
+
+	m.content[m.cursor.Row] = m.content[m.cursor.Row][m.cursor.Col:]
+	m.cursor.Col = 0
+	m = m.adjustScroll()
+	return m
+}
+
+// insertBlock inserts text that may span several lines at the cursor,
+// leaving the cursor after it
+func (m Model) insertBlock(text string) Model {
+	if m.cursor.Row >= len(m.content) {
+		return m
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(lines) == 1 {
+		return m.insertText(text)
+	}
+
+	line := m.content[m.cursor.Row]
+	before := line[:m.cursor.Col]
+	after := line[m.cursor.Col:]
+	last := len(lines) - 1
+
+	inserted := make([]string, len(lines))
+	copy(inserted, lines)
+	inserted[0] = before + lines[0]
+	inserted[last] = lines[last] + after
+
+	newContent := make([]string, 0, len(m.content)+last)
+	newContent = append(newContent, m.content[:m.cursor.Row]...)
+	newContent = append(newContent, inserted...)
+	newContent = append(newContent, m.content[m.cursor.Row+1:]...)
+	m.content = newContent
+
+	m.cursor.Row += last
+	m.cursor.Col = len(lines[last])
+	m = m.adjustScroll()
+	return m
+}
+
 func (m Model) deleteChar(count int) Model {
 	for i := 0; i < count; i++ {
 		if m.cursor.Row < len(m.content) {
@@ -148,7 +239,7 @@ func (m Model) deleteLines(count int) Model {
 			deletedLines = append(deletedLines, m.content[i])
 		}
 	}
-	m.clipboard = strings.Join(deletedLines, "\n")
+	m = m.setRegister(strings.Join(deletedLines, "\n"))
 
 	// Handle edge case: deleting all lines
 	if len(m.content) <= count {
@@ -185,13 +276,13 @@ func (m Model) yankLines(count int) Model {
 			yankedLines = append(yankedLines, m.content[i])
 		}
 	}
-	m.clipboard = strings.Join(yankedLines, "\n")
+	m = m.setRegister(strings.Join(yankedLines, "\n"))
 	return m
 }
 
 func (m Model) yankLine() Model {
 	if m.cursor.Row < len(m.content) {
-		m.clipboard = m.content[m.cursor.Row]
+		m = m.setRegister(m.content[m.cursor.Row])
 	}
 	return m
 }
@@ -201,7 +292,7 @@ func (m Model) deleteToEndOfLine() Model {
 		line := m.content[m.cursor.Row]
 		if m.cursor.Col < len(line) {
 			// Yank the deleted text
-			m.clipboard = line[m.cursor.Col:]
+			m = m.setRegister(line[m.cursor.Col:])
 			// Delete from cursor to end of line
 			m.content[m.cursor.Row] = line[:m.cursor.Col]
 		}
@@ -232,7 +323,7 @@ func (m Model) deleteRange(startPos, endPos Position) Model {
 		line := m.content[startPos.Row]
 		before := line[:startPos.Col]
 		after := line[endPos.Col:]
-		m.clipboard = line[startPos.Col:endPos.Col]
+		m = m.setRegister(line[startPos.Col:endPos.Col])
 		m.content[startPos.Row] = before + after
 		m.cursor = startPos
 	} else {
@@ -254,7 +345,7 @@ func (m Model) deleteRange(startPos, endPos Position) Model {
 			deletedText = append(deletedText, lastLine[:endPos.Col])
 		}
 
-		m.clipboard = strings.Join(deletedText, "\n")
+		m = m.setRegister(strings.Join(deletedText, "\n"))
 
 		// Merge remaining parts
 		before := m.content[startPos.Row][:startPos.Col]
@@ -282,7 +373,7 @@ func (m Model) yankRange(startPos, endPos Position) Model {
 	if startPos.Row == endPos.Row {
 		// Single line yank
 		line := m.content[startPos.Row]
-		m.clipboard = line[startPos.Col:endPos.Col]
+		m = m.setRegister(line[startPos.Col:endPos.Col])
 	} else {
 		// Multi-line yank
 		var yankedText []string
@@ -302,7 +393,7 @@ func (m Model) yankRange(startPos, endPos Position) Model {
 			yankedText = append(yankedText, lastLine[:endPos.Col])
 		}
 
-		m.clipboard = strings.Join(yankedText, "\n")
+		m = m.setRegister(strings.Join(yankedText, "\n"))
 	}
 	return m
 }
@@ -340,7 +431,7 @@ func (m Model) deleteSelection() Model {
 				deletedText = append(deletedText, m.content[row])
 			}
 		}
-		m.clipboard = strings.Join(deletedText, "\n")
+		m = m.setRegister(strings.Join(deletedText, "\n"))
 
 		// Delete lines
 		before := m.content[start.Row][:start.Col]
@@ -368,7 +459,7 @@ func (m Model) yankSelection() Model {
 
 	if start.Row == end.Row {
 		line := m.content[start.Row]
-		m.clipboard = line[start.Col:end.Col]
+		m = m.setRegister(line[start.Col:end.Col])
 	} else {
 		var yankedText []string
 		for row := start.Row; row <= end.Row; row++ {
@@ -380,19 +471,33 @@ func (m Model) yankSelection() Model {
 				yankedText = append(yankedText, m.content[row])
 			}
 		}
-		m.clipboard = strings.Join(yankedText, "\n")
+		m = m.setRegister(strings.Join(yankedText, "\n"))
 	}
 	return m
 }
 
-func (m Model) pasteAfter() Model {
-	if m.clipboard == "" {
+// isEmpty reports whether the input is the single empty line it starts as
+func (m Model) isEmpty() bool {
+	return len(m.content) == 1 && m.content[0] == ""
+}
+
+// pasteAfter puts text after the cursor, or below the current line if it spans lines
+func (m Model) pasteAfter(text string) Model {
+	if text == "" {
 		return m
 	}
 
-	if strings.Contains(m.clipboard, "\n") {
+	// Lines pasted into an empty input replace its one empty line
+	if m.isEmpty() && strings.Contains(text, "\n") {
+		m.content = strings.Split(text, "\n")
+		m.cursor = Position{0, 0}
+		m = m.adjustScroll()
+		return m.saveUndoState()
+	}
+
+	if strings.Contains(text, "\n") {
 		// Paste as new line(s)
-		lines := strings.Split(m.clipboard, "\n")
+		lines := strings.Split(text, "\n")
 
 		newContent := make([]string, len(m.content)+len(lines))
 		copy(newContent[:m.cursor.Row+1], m.content[:m.cursor.Row+1])
@@ -408,21 +513,30 @@ func (m Model) pasteAfter() Model {
 	} else {
 		// Paste as text
 		m.cursor = m.moveRight(1)
-		m = m.insertText(m.clipboard)
+		m = m.insertText(text)
 	}
 
 	m = m.saveUndoState()
 	return m
 }
 
-func (m Model) pasteBefore() Model {
-	if m.clipboard == "" {
+// pasteBefore puts text before the cursor, or above the current line if it spans lines
+func (m Model) pasteBefore(text string) Model {
+	if text == "" {
 		return m
 	}
 
-	if strings.Contains(m.clipboard, "\n") {
+	// Lines pasted into an empty input replace its one empty line
+	if m.isEmpty() && strings.Contains(text, "\n") {
+		m.content = strings.Split(text, "\n")
+		m.cursor = Position{0, 0}
+		m = m.adjustScroll()
+		return m.saveUndoState()
+	}
+
+	if strings.Contains(text, "\n") {
 		// Paste as new line(s) before current
-		lines := strings.Split(m.clipboard, "\n")
+		lines := strings.Split(text, "\n")
 
 		newContent := make([]string, len(m.content)+len(lines))
 		copy(newContent[:m.cursor.Row], m.content[:m.cursor.Row])
@@ -436,7 +550,7 @@ func (m Model) pasteBefore() Model {
 		m.cursor.Col = 0
 	} else {
 		// Paste as text
-		m = m.insertText(m.clipboard)
+		m = m.insertText(text)
 	}
 
 	m = m.saveUndoState()
@@ -0,0 +1,156 @@
+package vimtextarea
+
+import "testing"
+
+func TestOperatorToEndOfLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		start    Position
+		keys     []string
+		want     string
+		wantReg  string
+		wantPos  Position
+		wantMode Mode
+	}{
+		{
+			name:     "d$ without a trailing newline",
+			value:    "foo bar",
+			start:    Position{0, 4},
+			keys:     []string{"d", "$"},
+			want:     "foo ",
+			wantReg:  "bar",
+			wantPos:  Position{0, 3},
+			wantMode: Normal,
+		},
+		{
+			name:     "d$ with a trailing newline",
+			value:    "foo bar\n",
+			start:    Position{0, 4},
+			keys:     []string{"d", "$"},
+			want:     "foo \n",
+			wantReg:  "bar",
+			wantPos:  Position{0, 3},
+			wantMode: Normal,
+		},
+		{
+			name:     "d$ on the last character",
+			value:    "foo bar\nbaz",
+			start:    Position{0, 6},
+			keys:     []string{"d", "$"},
+			want:     "foo ba\nbaz",
+			wantReg:  "r",
+			wantPos:  Position{0, 5},
+			wantMode: Normal,
+		},
+		{
+			name:     "d$ from the start of the line",
+			value:    "foo\nbar",
+			start:    Position{1, 0},
+			keys:     []string{"d", "$"},
+			want:     "foo\n",
+			wantReg:  "bar",
+			wantPos:  Position{1, 0},
+			wantMode: Normal,
+		},
+		{
+			name:     "c$ without a trailing newline",
+			value:    "foo bar",
+			start:    Position{0, 4},
+			keys:     []string{"c", "$"},
+			want:     "foo ",
+			wantReg:  "bar",
+			wantPos:  Position{0, 4},
+			wantMode: Insert,
+		},
+		{
+			name:     "c$ with a trailing newline",
+			value:    "foo bar\n",
+			start:    Position{0, 4},
+			keys:     []string{"c", "$"},
+			want:     "foo \n",
+			wantReg:  "bar",
+			wantPos:  Position{0, 4},
+			wantMode: Insert,
+		},
+		{
+			name:     "y$ keeps the line",
+			value:    "foo bar\n",
+			start:    Position{0, 4},
+			keys:     []string{"y", "$"},
+			want:     "foo bar\n",
+			wantReg:  "bar",
+			wantPos:  Position{0, 4},
+			wantMode: Normal,
+		},
+		{
+			name:     "y$ on a one-character line",
+			value:    "x",
+			start:    Position{0, 0},
+			keys:     []string{"y", "$"},
+			want:     "x",
+			wantReg:  "x",
+			wantPos:  Position{0, 0},
+			wantMode: Normal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(newNormal(tt.value, tt.start), tt.keys...)
+			if got := m.Value(); got != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+			if m.clipboard != tt.wantReg {
+				t.Errorf("register = %q, want %q", m.clipboard, tt.wantReg)
+			}
+			if m.cursor != tt.wantPos {
+				t.Errorf("cursor = %v, want %v", m.cursor, tt.wantPos)
+			}
+			if m.mode != tt.wantMode {
+				t.Errorf("mode = %v, want %v", m.mode, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestEditFreshBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		register string
+		keys     []string
+		want     string
+		wantPos  Position
+		wantMode Mode
+	}{
+		{name: "o", keys: []string{"o"}, want: "\n", wantPos: Position{1, 0}, wantMode: Insert},
+		{name: "o then type", keys: []string{"o", "x"}, want: "\nx", wantPos: Position{1, 1}, wantMode: Insert},
+		{name: "O", keys: []string{"O"}, want: "\n", wantPos: Position{0, 0}, wantMode: Insert},
+		{name: "O then type", keys: []string{"O", "x"}, want: "x\n", wantPos: Position{0, 1}, wantMode: Insert},
+		{name: "p text", register: "hello", keys: []string{"p"}, want: "hello", wantPos: Position{0, 4}, wantMode: Normal},
+		{name: "P text", register: "hello", keys: []string{"P"}, want: "hello", wantPos: Position{0, 4}, wantMode: Normal},
+		{name: "p lines", register: "one\ntwo", keys: []string{"p"}, want: "one\ntwo", wantPos: Position{0, 0}, wantMode: Normal},
+		{name: "P lines", register: "one\ntwo", keys: []string{"P"}, want: "one\ntwo", wantPos: Position{0, 0}, wantMode: Normal},
+		{name: "p empty register", keys: []string{"p"}, want: "", wantPos: Position{0, 0}, wantMode: Normal},
+		{name: "p then undo", register: "one\ntwo", keys: []string{"p", "u"}, want: "", wantPos: Position{0, 0}, wantMode: Normal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A new textarea starts in Insert mode on a single empty line
+			m := press(New(), "esc")
+			m.clipboard = tt.register
+
+			m = press(m, tt.keys...)
+			if got := m.Value(); got != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+			if m.cursor != tt.wantPos {
+				t.Errorf("cursor = %v, want %v", m.cursor, tt.wantPos)
+			}
+			if m.mode != tt.wantMode {
+				t.Errorf("mode = %v, want %v", m.mode, tt.wantMode)
+			}
+		})
+	}
+}
@@ -21,12 +21,16 @@ func (m Model) moveRight(count int) Position {
 func (m Model) moveUp(count int) Position {
 	pos := m.cursor
 	pos.Row = max(0, pos.Row-count)
+	// Keep the same screen column so tabs line up visually
+	pos.Col = m.colForDisplay(pos.Row, m.displayCol(m.cursor.Row, m.cursor.Col))
 	return m.validateCursor(pos)
 }
 
 func (m Model) moveDown(count int) Position {
 	pos := m.cursor
 	pos.Row = min(len(m.content)-1, pos.Row+count)
+	// Keep the same screen column so tabs line up visually
+	pos.Col = m.colForDisplay(pos.Row, m.displayCol(m.cursor.Row, m.cursor.Col))
 	return m.validateCursor(pos)
 }
 
@@ -44,10 +48,22 @@ func (m Model) moveToFirstNonWhitespace() Position {
 	return pos
 }
 
+// moveToEndOfLine returns the position of the last character on the line,
+// where the Normal mode cursor sits after $
 func (m Model) moveToEndOfLine() Position {
 	pos := m.cursor
 	if pos.Row < len(m.content) {
-		pos.Col = max(0, len(m.content[pos.Row]))
+		pos.Col = max(0, len(m.content[pos.Row])-1)
+	}
+	return pos
+}
+
+// moveAfterEndOfLine returns the position just past the last character on
+// the line, where Insert mode starts after A
+func (m Model) moveAfterEndOfLine() Position {
+	pos := m.cursor
+	if pos.Row < len(m.content) {
+		pos.Col = len(m.content[pos.Row])
 	}
 	return pos
 }
@@ -119,121 +135,142 @@ func (m Model) nextWord(pos Position, isWORD bool) Position {
 		return pos
 	}
 
-	line := m.content[pos.Row]
+	row := pos.Row
+	line := m.content[row]
 	col := pos.Col
 
-	// Skip current word
-	for col < len(line) && !m.isWordBoundary(line[col], isWORD) {
-		col++
+	// Skip the rest of the current word (a run of the same character class)
+	if col < len(line) {
+		class := m.charClass(line[col], isWORD)
+		if class != classWhitespace {
+			for col < len(line) && m.charClass(line[col], isWORD) == class {
+				col++
+			}
+		}
 	}
 
-	// Skip whitespace
-	for col < len(line) && unicode.IsSpace(rune(line[col])) {
-		col++
-	}
+	// Skip whitespace, continuing onto following lines
+	for {
+		for col < len(line) && m.charClass(line[col], isWORD) == classWhitespace {
+			col++
+		}
 
-	if col >= len(line) && pos.Row < len(m.content)-1 {
-		return Position{Row: pos.Row + 1, Col: 0}
-	}
+		if col < len(line) || row >= len(m.content)-1 {
+			return Position{Row: row, Col: col}
+		}
+
+		row++
+		line = m.content[row]
+		col = 0
 
-	return Position{Row: pos.Row, Col: col}
+		// Like vim, an empty line counts as a word
+		if len(line) == 0 {
+			return Position{Row: row, Col: 0}
+		}
+	}
 }
 
 func (m Model) prevWord(pos Position, isWORD bool) Position {
-	if pos.Row < 0 {
+	if pos.Row < 0 || pos.Row >= len(m.content) {
 		return pos
 	}
 
-	line := m.content[pos.Row]
-	col := pos.Col
-
-	if col > 0 {
-		col--
-	}
+	row := pos.Row
+	col := min(pos.Col, len(m.content[row]))
 
-	// Skip whitespace
-	for col > 0 && unicode.IsSpace(rune(line[col])) {
-		col--
-	}
+	for {
+		line := m.content[row]
 
-	// Skip to beginning of word
-	for col > 0 && !m.isWordBoundary(line[col-1], isWORD) {
-		col--
-	}
-
-	return Position{Row: pos.Row, Col: col}
-}
+		// At the start of a line, continue from the end of the previous line
+		if col == 0 {
+			if row == 0 {
+				return Position{Row: 0, Col: 0}
+			}
+			row--
+			line = m.content[row]
+			col = len(line)
 
-func (m Model) nextWordEnd(pos Position, isWORD bool) Position {
-	if pos.Row >= len(m.content) {
-		return pos
-	}
+			// Like vim, an empty line counts as a word
+			if col == 0 {
+				return Position{Row: row, Col: 0}
+			}
+		}
 
-	line := m.content[pos.Row]
-	col := pos.Col
+		col--
 
-	// If we're at the end of the line, go to next line
-	if col >= len(line) {
-		if pos.Row < len(m.content)-1 {
-			nextLine := m.content[pos.Row+1]
-			// Find first non-whitespace character
-			for i := 0; i < len(nextLine); i++ {
-				if !unicode.IsSpace(rune(nextLine[i])) {
-					// Find the end of this word
-					for j := i; j < len(nextLine) && !m.isWordBoundary(nextLine[j], isWORD); j++ {
-						i = j
-					}
-					return Position{Row: pos.Row + 1, Col: i}
-				}
-			}
+		// Skip whitespace
+		for col > 0 && m.charClass(line[col], isWORD) == classWhitespace {
+			col--
 		}
-		return pos
-	}
 
-	// Check if we're currently at the end of a word
-	atWordEnd := col < len(line) && !m.isWordBoundary(line[col], isWORD) &&
-		(col+1 >= len(line) || m.isWordBoundary(line[col+1], isWORD))
+		// Only whitespace before the cursor on this line - keep going up
+		if m.charClass(line[col], isWORD) == classWhitespace {
+			col = 0
+			continue
+		}
 
-	// If we're at the end of a word, move forward to find the next word
-	if atWordEnd {
-		col++ // Move past current character
-	}
+		// Skip to beginning of word (a run of the same character class)
+		class := m.charClass(line[col], isWORD)
+		for col > 0 && m.charClass(line[col-1], isWORD) == class {
+			col--
+		}
 
-	// Skip any whitespace or punctuation
-	for col < len(line) && (unicode.IsSpace(rune(line[col])) || m.isWordBoundary(line[col], isWORD)) {
-		col++
+		return Position{Row: row, Col: col}
 	}
+}
 
-	// If we reached end of line, try next line
-	if col >= len(line) {
-		if pos.Row < len(m.content)-1 {
-			return m.nextWordEnd(Position{Row: pos.Row + 1, Col: 0}, isWORD)
-		}
+func (m Model) nextWordEnd(pos Position, isWORD bool) Position {
+	if pos.Row >= len(m.content) {
 		return pos
 	}
 
-	// Now we should be at the beginning of a word - find its end
-	if col < len(line) && !m.isWordBoundary(line[col], isWORD) {
-		// Find the end of this word
-		for col < len(line) && !m.isWordBoundary(line[col], isWORD) {
+	row := pos.Row
+	col := pos.Col + 1
+
+	// Skip whitespace (and empty lines) to the start of the next word
+	for {
+		line := m.content[row]
+		for col < len(line) && m.charClass(line[col], isWORD) == classWhitespace {
 			col++
 		}
-		// Move back one position to be ON the last character, not after it
-		if col > 0 {
-			col--
+		if col < len(line) {
+			break
 		}
-		return Position{Row: pos.Row, Col: col}
+		if row >= len(m.content)-1 {
+			return pos
+		}
+		row++
+		col = 0
 	}
 
-	return pos
+	// Move to the last character of this word
+	line := m.content[row]
+	class := m.charClass(line[col], isWORD)
+	for col+1 < len(line) && m.charClass(line[col+1], isWORD) == class {
+		col++
+	}
+
+	return Position{Row: row, Col: col}
 }
 
-func (m Model) isWordBoundary(char byte, isWORD bool) bool {
+// Character classes used by word motions. Like vim, a word is a run of
+// keyword characters (letters, digits, underscore) or a run of other
+// non-blank characters; a WORD is any run of non-blank characters.
+const (
+	classWhitespace = iota
+	classPunctuation
+	classKeyword
+)
+
+func (m Model) charClass(char byte, isWORD bool) int {
 	r := rune(char)
-	if isWORD {
-		return unicode.IsSpace(r)
+	if unicode.IsSpace(r) {
+		return classWhitespace
 	}
-	return unicode.IsSpace(r) || unicode.IsPunct(r)
+	if isWORD || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+		return classPunctuation
+	}
+	return classKeyword
 }
 
 func (m Model) repeatFind(dir int) Position {
@@ -259,6 +296,12 @@ func (m Model) validateCursor(pos Position) Position {
 	return pos
 }
 
+// scrollTo sets the viewport's top line, then clamps it like adjustScroll
+func (m Model) scrollTo(offset int) Model {
+	m.scrollOffset = offset
+	return m.adjustScroll()
+}
+
 // adjustScroll ensures the cursor is visible in the viewport
 func (m Model) adjustScroll() Model {
 	// Don't scroll if we have fewer lines than the viewport height
@@ -0,0 +1,175 @@
+package vimtextarea
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newNormal returns a textarea holding value in Normal mode, with the cursor at pos
+func newNormal(value string, pos Position) Model {
+	m := New()
+	m.SetValue(value)
+	m.mode = Normal
+	m.cursor = pos
+	return m
+}
+
+// press types keys into m one at a time
+func press(m Model, keys ...string) Model {
+	for _, key := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "esc" {
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func TestWordBackwardAcrossLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		key   string
+		start Position
+		want  []Position // Cursor after each press
+	}{
+		{
+			name:  "blank line is a word",
+			value: "foo\n\n  bar",
+			key:   "b",
+			start: Position{2, 2},
+			want:  []Position{{1, 0}, {0, 0}, {0, 0}},
+		},
+		{
+			name:  "leading whitespace",
+			value: "foo bar\n    baz",
+			key:   "b",
+			start: Position{1, 4},
+			want:  []Position{{0, 4}, {0, 0}},
+		},
+		{
+			name:  "from inside leading whitespace",
+			value: "foo\n    bar",
+			key:   "b",
+			start: Position{1, 2},
+			want:  []Position{{0, 0}},
+		},
+		{
+			name:  "whitespace-only line is skipped",
+			value: "foo\n   \nbar",
+			key:   "b",
+			start: Position{2, 0},
+			want:  []Position{{0, 0}},
+		},
+		{
+			name:  "first column to the previous line's last word",
+			value: "foo bar\nbaz",
+			key:   "b",
+			start: Position{1, 0},
+			want:  []Position{{0, 4}},
+		},
+		{
+			name:  "punctuation across a blank line",
+			value: "foo.bar\n\n  baz.qux",
+			key:   "b",
+			start: Position{2, 6},
+			want:  []Position{{2, 5}, {2, 2}, {1, 0}, {0, 4}, {0, 3}, {0, 0}},
+		},
+		{
+			name:  "WORD across a blank line",
+			value: "foo.bar\n\n  baz.qux",
+			key:   "B",
+			start: Position{2, 6},
+			want:  []Position{{2, 2}, {1, 0}, {0, 0}},
+		},
+		{
+			name:  "WORD with leading whitespace",
+			value: "a.b c.d\n\t  e.f",
+			key:   "B",
+			start: Position{1, 3},
+			want:  []Position{{0, 4}, {0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newNormal(tt.value, tt.start)
+			for i, want := range tt.want {
+				m = press(m, tt.key)
+				if m.cursor != want {
+					t.Fatalf("%s press %d: cursor = %v, want %v", tt.key, i+1, m.cursor, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWordMotionsThroughPunctuation(t *testing.T) {
+	// Like vim, runs of punctuation are words of their own:
+	// foo . bar ( baz )
+	const line = "foo.bar(baz)"
+
+	tests := []struct {
+		key   string
+		start int
+		want  []int // Cursor column after each press
+	}{
+		{key: "w", start: 0, want: []int{3, 4, 7, 8, 11, 11}},
+		{key: "w", start: 5, want: []int{7, 8, 11}},
+		{key: "b", start: 11, want: []int{8, 7, 4, 3, 0, 0}},
+		{key: "b", start: 9, want: []int{8, 7, 4}},
+		{key: "e", start: 0, want: []int{2, 3, 6, 7, 10, 11, 11}},
+		{key: "e", start: 4, want: []int{6, 7, 10}},
+		{key: "W", start: 0, want: []int{11}},
+		{key: "B", start: 11, want: []int{0}},
+		{key: "E", start: 0, want: []int{11}},
+	}
+
+	for _, tt := range tests {
+		m := newNormal(line, Position{0, tt.start})
+		for i, want := range tt.want {
+			m = press(m, tt.key)
+			if m.cursor != (Position{0, want}) {
+				t.Fatalf("%s from column %d, press %d: cursor = %v, want column %d", tt.key, tt.start, i+1, m.cursor, want)
+			}
+		}
+	}
+}
+
+func TestWordMotionCounts(t *testing.T) {
+	tests := []struct {
+		keys []string
+		want Position
+	}{
+		{keys: []string{"3", "w"}, want: Position{0, 7}},
+		{keys: []string{"2", "e"}, want: Position{0, 3}},
+		{keys: []string{"$", "2", "b"}, want: Position{0, 7}},
+	}
+
+	for _, tt := range tests {
+		m := press(newNormal("foo.bar(baz)", Position{0, 0}), tt.keys...)
+		if m.cursor != tt.want {
+			t.Errorf("%v: cursor = %v, want %v", tt.keys, m.cursor, tt.want)
+		}
+	}
+}
+
+func TestEndOfLine(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Position
+	}{
+		{value: "foo bar", want: Position{0, 6}},
+		{value: "x", want: Position{0, 0}},
+		{value: "", want: Position{0, 0}},
+	}
+
+	for _, tt := range tests {
+		m := press(newNormal(tt.value, Position{0, 0}), "$")
+		if m.cursor != tt.want {
+			t.Errorf("$ on %q: cursor = %v, want %v", tt.value, m.cursor, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package vimtextarea
+
+import (
+	"unicode"
+)
+
+// Yanks, deletes and pastes use the unnamed register unless a named
+// register "a to "z is chosen first by typing " and its name. An uppercase
+// name appends to the register on a new line instead of replacing it.
+
+// UnnamedRegister is the name Registers reports the unnamed register under
+const UnnamedRegister = '"'
+
+// selectRegister chooses the register for the next yank, delete or paste
+// from the key typed after ". It reports whether key names a register.
+func (m Model) selectRegister(key string) (Model, bool) {
+	if len(key) != 1 {
+		return m, false
+	}
+
+	name := rune(key[0])
+	switch {
+	case name == UnnamedRegister:
+		m.register = 0
+	case name <= unicode.MaxASCII && unicode.IsLetter(name):
+		m.register = name
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+// setRegister stores yanked or deleted text in the unnamed register and in
+// the chosen named register, if any
+func (m Model) setRegister(text string) Model {
+	if m.register != 0 {
+		if m.registers == nil {
+			m.registers = make(map[rune]string)
+		}
+		name := unicode.ToLower(m.register)
+		if unicode.IsUpper(m.register) && m.registers[name] != "" {
+			text = m.registers[name] + "\n" + text
+		}
+		m.registers[name] = text
+	}
+	m.clipboard = text
+	return m
+}
+
+// registerText returns the text a paste uses: the chosen named register, or
+// the unnamed one
+func (m Model) registerText() string {
+	if m.register != 0 {
+		return m.registers[unicode.ToLower(m.register)]
+	}
+	return m.clipboard
+}
+
+// Registers returns the contents of the unnamed register, under
+// UnnamedRegister, and of every named register that has been set
+func (m Model) Registers() map[rune]string {
+	registers := make(map[rune]string, len(m.registers)+1)
+	for name, text := range m.registers {
+		registers[name] = text
+	}
+	if m.clipboard != "" {
+		registers[UnnamedRegister] = m.clipboard
+	}
+	return registers
+}
+
+// ClearRegisters empties the unnamed and named registers
+func (m *Model) ClearRegisters() {
+	m.clipboard = ""
+	m.registers = nil
+	m.register = 0
+}
@@ -3,6 +3,7 @@ package vimtextarea
 import (
 	"github.com/charmbracelet/lipgloss"
 	"strings"
+	"unicode/utf8"
 )
 
 func (m Model) View() string {
@@ -13,7 +14,7 @@ func (m Model) View() string {
 	var lines []string
 
 	// Check if we should show placeholder (empty content or first line is empty)
-	showPlaceholder := len(m.content) == 1 && m.content[0] == "" && m.placeholder != ""
+	showPlaceholder := m.isEmpty() && m.placeholder != ""
 
 	// Calculate the visible range based on scroll offset
 	visibleStart := m.scrollOffset
@@ -54,22 +55,28 @@ func (m Model) View() string {
 
 func (m Model) renderLine(row int, line string) string {
 	if row != m.cursor.Row {
-		return line
+		return m.expandTabs(line, 0)
 	}
 
 	// Render cursor
 	if m.cursor.Col >= len(line) {
 		// Cursor at end of line
 		cursorStyle := m.getCursorStyle()
-		return line + cursorStyle.Render(" ")
+		return m.expandTabs(line, 0) + cursorStyle.Render(" ")
 	}
 
-	before := line[:m.cursor.Col]
+	before := m.expandTabs(line[:m.cursor.Col], 0)
+	beforeWidth := utf8.RuneCountInString(before)
 	char := string(line[m.cursor.Col])
 	after := line[m.cursor.Col+1:]
 
 	cursorStyle := m.getCursorStyle()
-	return before + cursorStyle.Render(char) + after
+	if char == "\t" {
+		// Draw the cursor on the first cell of the tab
+		tabWidth := m.tabWidth - beforeWidth%m.tabWidth
+		return before + cursorStyle.Render(" ") + strings.Repeat(" ", tabWidth-1) + m.expandTabs(after, beforeWidth+tabWidth)
+	}
+	return before + cursorStyle.Render(char) + m.expandTabs(after, beforeWidth+1)
 }
 
 func (m Model) renderLineWithSelection(row int, line string) string {
@@ -99,9 +106,9 @@ func (m Model) renderLineWithSelection(row int, line string) string {
 		endCol = len(line)
 	}
 
-	before := line[:startCol]
-	selected := line[startCol:endCol]
-	after := line[endCol:]
+	before := m.expandTabs(line[:startCol], 0)
+	selected := m.expandTabs(line[startCol:endCol], utf8.RuneCountInString(before))
+	after := m.expandTabs(line[endCol:], utf8.RuneCountInString(before+selected))
 
 	result := before + selectionStyle.Render(selected) + after
 
@@ -113,6 +120,59 @@ func (m Model) renderLineWithSelection(row int, line string) string {
 	return result
 }
 
+// expandTabs replaces tabs with spaces up to the next tab stop, where
+// startCol is the screen column that s starts at
+func (m Model) expandTabs(s string, startCol int) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+
+	var b strings.Builder
+	col := startCol
+	for _, r := range s {
+		if r == '\t' {
+			width := m.tabWidth - col%m.tabWidth
+			b.WriteString(strings.Repeat(" ", width))
+			col += width
+		} else {
+			b.WriteRune(r)
+			col++
+		}
+	}
+	return b.String()
+}
+
+// displayCol returns the screen column of a byte offset within a line
+func (m Model) displayCol(row, col int) int {
+	if row < 0 || row >= len(m.content) {
+		return col
+	}
+	line := m.content[row]
+	return utf8.RuneCountInString(m.expandTabs(line[:min(col, len(line))], 0))
+}
+
+// colForDisplay returns the byte offset within a line that covers the
+// given screen column
+func (m Model) colForDisplay(row, displayCol int) int {
+	if row < 0 || row >= len(m.content) {
+		return displayCol
+	}
+
+	line := m.content[row]
+	col := 0
+	for i, r := range line {
+		width := 1
+		if r == '\t' {
+			width = m.tabWidth - col%m.tabWidth
+		}
+		if col+width > displayCol {
+			return i
+		}
+		col += width
+	}
+	return len(line)
+}
+
 func (m Model) getCursorStyle() lipgloss.Style {
 	// Use the same cursor color (gray) for all modes
 	return lipgloss.NewStyle().Background(lipgloss.Color("7")).Foreground(lipgloss.Color("0"))
@@ -43,11 +43,19 @@ type Model struct {
 	content     []string
 	cursor      Position
 	selection   *Selection
-	clipboard   string
+	clipboard   string          // The unnamed register
+	registers   map[rune]string // Named registers "a to "z
+	register    rune            // Register chosen for the next yank, delete or paste; 0 for the unnamed one
 	width       int
 	height      int
 	placeholder string
 	focused     bool
+	plain       bool // Stay in Insert mode, like a regular input field
+
+	// Tab handling
+	tabWidth   int  // Display width of a tab character
+	expandTab  bool // Insert spaces instead of a tab character
+	autoIndent bool // Copy the current line's indentation to new lines
 
 	// Viewport/scrolling
 	scrollOffset int // Line number at top of viewport
@@ -70,6 +78,9 @@ type Model struct {
 	awaitingReplaceChar bool
 	replaceCount        int
 
+	// Multi-key command state
+	pendingPrefix string // "g", "z", or a register ("\"", "\"+") while awaiting the next key
+
 	// Insert session tracking
 	inInsertSession bool // Track if we're currently in an insert session
 
@@ -2,6 +2,7 @@ package vimtextarea
 
 import (
 	"strings"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"reapo/internal/tui/completion"
@@ -22,6 +23,7 @@ func New() Model {
 		width:        80,
 		height:       1,
 		scrollOffset: 0,
+		tabWidth:     4,
 		undoHistory: UndoHistory{
 			states:  make([]UndoState, 0, 100),
 			index:   -1,
@@ -43,6 +45,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return m.handleKeyMsg(msg)
+	case ClipboardPasteMsg:
+		return m.handleClipboardPaste(msg), nil
 	}
 	return m, nil
 }
@@ -50,6 +54,12 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	key := msg.String()
 
+	// Plain mode bypasses vim modality entirely
+	if m.plain && m.mode != Insert {
+		m = m.startInsertSession()
+		m.mode = Insert
+	}
+
 	switch m.mode {
 	case Normal:
 		return m.handleNormalMode(key)
@@ -73,6 +83,13 @@ func (m Model) handleNormalMode(key string) (Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// If we're awaiting the next key of a g/z command or register, handle it
+	if m.pendingPrefix != "" {
+		prefix := m.pendingPrefix
+		m.pendingPrefix = ""
+		return m.handlePrefixCommand(prefix, key)
+	}
+
 	// Handle counts
 	if len(key) == 1 && key >= "1" && key <= "9" && m.inputCount == 0 && !m.commandState.awaitingMotion {
 		m.inputCount = int(key[0] - '0')
@@ -146,8 +163,9 @@ func (m Model) handleNormalMode(key string) (Model, tea.Cmd) {
 		m = m.adjustScroll()
 
 	// Document navigation
-	case "g":
-		return m.handleGCommand()
+	case "g", "z", "\"":
+		m.pendingPrefix = key
+		return m, nil
 	case "G":
 		if count == 1 {
 			// No count specified, go to last line
@@ -182,7 +200,7 @@ func (m Model) handleNormalMode(key string) (Model, tea.Cmd) {
 		m.mode = Insert
 	case "A":
 		m = m.startInsertSession()
-		m.cursor = m.moveToEndOfLine()
+		m.cursor = m.moveAfterEndOfLine()
 		m = m.adjustScroll()
 		m.mode = Insert
 	case "o":
@@ -231,9 +249,9 @@ func (m Model) handleNormalMode(key string) (Model, tea.Cmd) {
 		m.commandState.motionCount = 0
 		return m, nil
 	case "p":
-		m = m.pasteAfter()
+		m = m.pasteAfter(m.registerText())
 	case "P":
-		m = m.pasteBefore()
+		m = m.pasteBefore(m.registerText())
 
 	// Undo/Redo
 	case "u":
@@ -243,6 +261,7 @@ func (m Model) handleNormalMode(key string) (Model, tea.Cmd) {
 	}
 
 	m.inputCount = 0
+	m.register = 0
 	m.cursor = m.validateCursor(m.cursor)
 	return m, nil
 }
@@ -277,7 +296,6 @@ func (m Model) handleInsertMode(key string, msg tea.KeyMsg) (Model, tea.Cmd) {
 				} else {
 					// Regular completion - insert as before
 					m = m.insertCompletion(selected.Text)
-					m.completionState.Reset()
 				}
 			}
 			return m, nil
@@ -303,18 +321,37 @@ func (m Model) handleInsertMode(key string, msg tea.KeyMsg) (Model, tea.Cmd) {
 	switch key {
 	case "esc":
 		m.completionState.Reset()
+		if m.plain {
+			// There is no Normal mode to return to
+			break
+		}
 		m = m.endInsertSession()
 		m.mode = Normal
 		m.cursor = m.moveLeft(1)
 		m = m.adjustScroll()
-	case "enter":
+	case "enter", "alt+enter", "ctrl+j":
 		m = m.insertNewLine()
 	case "backspace":
 		m = m.backspace()
 		// Check if we should trigger completion after backspace
 		m = m.checkAndTriggerCompletion()
+	case "ctrl+w":
+		m = m.deleteWordBackward()
+		// Deleting part of a completion query updates or dismisses it
+		m = m.checkAndTriggerCompletion()
+	case "ctrl+u":
+		m = m.deleteToLineStart()
+		m = m.checkAndTriggerCompletion()
+	case "ctrl+v":
+		return m, readClipboard(false)
 	case "tab":
-		m = m.insertText("\t")
+		if m.expandTab {
+			// Insert spaces up to the next tab stop
+			col := m.displayCol(m.cursor.Row, m.cursor.Col)
+			m = m.insertText(strings.Repeat(" ", m.tabWidth-col%m.tabWidth))
+		} else {
+			m = m.insertText("\t")
+		}
 	case "left":
 		m.cursor = m.moveLeft(1)
 		m = m.adjustScroll()
@@ -323,6 +360,15 @@ func (m Model) handleInsertMode(key string, msg tea.KeyMsg) (Model, tea.Cmd) {
 		m.cursor = m.moveRight(1)
 		m = m.adjustScroll()
 		m = m.checkAndTriggerCompletion()
+	case "ctrl+a":
+		// Emacs-style line start; Normal mode keeps ctrl+a for itself
+		m.cursor.Col = 0
+		m = m.adjustScroll()
+		m = m.checkAndTriggerCompletion()
+	case "ctrl+e":
+		m.cursor = m.moveAfterEndOfLine()
+		m = m.adjustScroll()
+		m = m.checkAndTriggerCompletion()
 	case "up":
 		m.cursor = m.moveUp(1)
 		m = m.adjustScroll()
@@ -350,7 +396,17 @@ func (m Model) handleInsertMode(key string, msg tea.KeyMsg) (Model, tea.Cmd) {
 }
 
 func (m Model) handleVisualMode(key string) (Model, tea.Cmd) {
+	// A register for the next yank or delete follows "
+	if m.pendingPrefix == "\"" {
+		m.pendingPrefix = ""
+		m, _ = m.selectRegister(key)
+		return m, nil
+	}
+
 	switch key {
+	case "\"":
+		m.pendingPrefix = key
+		return m, nil
 	case "esc":
 		m.mode = Normal
 		m.selection = nil
@@ -384,6 +440,9 @@ func (m Model) handleVisualMode(key string) (Model, tea.Cmd) {
 		m.selection = nil
 	}
 
+	if m.mode != Visual {
+		m.register = 0
+	}
 	m.cursor = m.validateCursor(m.cursor)
 	return m, nil
 }
@@ -399,6 +458,7 @@ func (m Model) handleMotionCommand(key string) (Model, tea.Cmd) {
 
 	var startPos, endPos Position
 	startPos = m.cursor
+	inclusive := false // Whether the motion includes the character at endPos
 
 	switch key {
 	// Line operations (dd, yy, cc)
@@ -416,18 +476,27 @@ func (m Model) handleMotionCommand(key string) (Model, tea.Cmd) {
 		endPos = m.moveUp(motionCount)
 
 	// Word motions
-	case "w":
-		endPos = m.moveWordForward(motionCount)
-	case "W":
-		endPos = m.moveWORDForward(motionCount)
+	case "w", "W":
+		if key == "w" {
+			endPos = m.moveWordForward(motionCount)
+		} else {
+			endPos = m.moveWORDForward(motionCount)
+		}
+		// Like vim, when the last word moved over ends a line the operation
+		// stops at the end of that line instead of joining the next one
+		if endPos.Row > startPos.Row {
+			endPos = Position{Row: endPos.Row - 1, Col: len(m.content[endPos.Row-1])}
+		}
 	case "b":
 		endPos = m.moveWordBackward(motionCount)
 	case "B":
 		endPos = m.moveWORDBackward(motionCount)
 	case "e":
 		endPos = m.moveWordEnd(motionCount)
+		inclusive = true
 	case "E":
 		endPos = m.moveWORDEnd(motionCount)
+		inclusive = true
 
 	// Line motions
 	case "0":
@@ -438,6 +507,7 @@ func (m Model) handleMotionCommand(key string) (Model, tea.Cmd) {
 		endPos = m.moveToFirstNonWhitespace()
 	case "$":
 		endPos = m.moveToEndOfLine()
+		inclusive = true
 
 	// Document motions
 	case "G":
@@ -450,12 +520,14 @@ func (m Model) handleMotionCommand(key string) (Model, tea.Cmd) {
 	default:
 		// Invalid motion, cancel command
 		m.commandState = CommandState{}
+		m.register = 0
 		return m, nil
 	}
 
 	// Execute the operation with the motion
-	m = m.executeOperation(startPos, endPos)
+	m = m.executeOperation(startPos, endPos, inclusive)
 	m.commandState = CommandState{}
+	m.register = 0
 	m.cursor = m.validateCursor(m.cursor)
 	return m, nil
 }
@@ -471,27 +543,20 @@ func (m Model) executeLineOperation(count int) (Model, tea.Cmd) {
 	}
 
 	m.commandState = CommandState{}
+	m.register = 0
 	m.cursor = m.validateCursor(m.cursor)
 	return m, nil
 }
 
-func (m Model) executeOperation(startPos, endPos Position) Model {
+func (m Model) executeOperation(startPos, endPos Position, inclusive bool) Model {
 	// Ensure proper order
 	if startPos.Row > endPos.Row || (startPos.Row == endPos.Row && startPos.Col > endPos.Col) {
 		startPos, endPos = endPos, startPos
 	}
 
-	// For word-end motions in delete/change operations, include the character at endPos
-	if m.commandState.operator == "d" || m.commandState.operator == "c" {
-		// Check if this was an 'e' motion by seeing if endPos is at a word boundary
-		if endPos.Row < len(m.content) && endPos.Col < len(m.content[endPos.Row]) {
-			line := m.content[endPos.Row]
-			// If we're at the last character of a word, include it in the deletion
-			if endPos.Col < len(line) && !m.isWordBoundary(line[endPos.Col], false) &&
-				(endPos.Col+1 >= len(line) || m.isWordBoundary(line[endPos.Col+1], false)) {
-				endPos.Col++
-			}
-		}
+	// Inclusive motions (e, E, $) operate on the character at endPos too
+	if inclusive && endPos.Row < len(m.content) && endPos.Col < len(m.content[endPos.Row]) {
+		endPos.Col++
 	}
 
 	switch m.commandState.operator {
@@ -505,11 +570,46 @@ func (m Model) executeOperation(startPos, endPos Position) Model {
 	return m
 }
 
-func (m Model) handleGCommand() (Model, tea.Cmd) {
-	// This would need a state machine for multi-key commands
-	// For now, just handle 'gg'
-	m.cursor = Position{0, 0}
-	m.adjustScroll()
+func (m Model) handlePrefixCommand(prefix, key string) (Model, tea.Cmd) {
+	// "a to "z choose the register for the next yank, delete or paste
+	if prefix == "\"" {
+		if selected, ok := m.selectRegister(key); ok {
+			return selected, nil
+		}
+	}
+
+	switch prefix + key {
+	case "\"+", "\"*":
+		// The system clipboard register is only read from; wait for p/P
+		m.pendingPrefix = "\"+"
+		return m, nil
+	case "\"+p":
+		m.inputCount = 0
+		return m, readClipboard(false)
+	case "\"+P":
+		m.inputCount = 0
+		return m, readClipboard(true)
+	case "gg":
+		if m.inputCount == 0 {
+			m.cursor = Position{0, 0}
+		} else {
+			// Count specified, go to that line number
+			m.cursor = m.moveToLine(m.inputCount - 1)
+		}
+		m = m.adjustScroll()
+	case "zz":
+		// Center the cursor line in the viewport
+		m = m.scrollTo(m.cursor.Row - m.height/2)
+	case "zt":
+		// Put the cursor line at the top of the viewport
+		m = m.scrollTo(m.cursor.Row)
+	case "zb":
+		// Put the cursor line at the bottom of the viewport
+		m = m.scrollTo(m.cursor.Row - m.height + 1)
+	}
+
+	m.inputCount = 0
+	m.cursor = m.validateCursor(m.cursor)
 	return m, nil
 }
 
@@ -529,13 +629,55 @@ func (m *Model) SetValue(value string) {
 	m.cursor = Position{0, 0}
 }
 
+// InsertAtEnd switches to Insert mode with the cursor after the last character
+func (m *Model) InsertAtEnd() {
+	*m = m.startInsertSession()
+	m.mode = Insert
+	m.cursor.Row = len(m.content) - 1
+	m.cursor.Col = len(m.content[m.cursor.Row])
+	*m = m.adjustScroll()
+}
+
 func (m *Model) SetWidth(width int) {
 	m.width = width
 }
 
+// SetTabWidth sets the display width of a tab character
+func (m *Model) SetTabWidth(width int) {
+	if width > 0 {
+		m.tabWidth = width
+	}
+}
+
+// SetExpandTab sets whether Tab inserts spaces instead of a tab character
+func (m *Model) SetExpandTab(expandTab bool) {
+	m.expandTab = expandTab
+}
+
+// SetPlain sets whether the textarea stays in Insert mode like a regular
+// input field instead of using vim modes
+func (m *Model) SetPlain(plain bool) {
+	m.plain = plain
+	if plain && m.mode != Insert {
+		*m = m.startInsertSession()
+		m.mode = Insert
+		m.selection = nil
+	}
+}
+
+// Plain reports whether vim modes are bypassed
+func (m Model) Plain() bool {
+	return m.plain
+}
+
+// SetAutoIndent sets whether new lines inherit the indentation of the current line
+func (m *Model) SetAutoIndent(autoIndent bool) {
+	m.autoIndent = autoIndent
+}
+
 func (m *Model) SetHeight(height int) {
 	m.height = height
-	m.adjustScroll()
+	*m = m.adjustScroll()
 }
 
 func (m Model) Height() int {
@@ -566,6 +708,18 @@ func (m Model) CompletionState() completion.CompletionState {
 	return m.completionState
 }
 
+// CompletionColumn returns the column in View of the character that
+// triggered completion, so the popup can be drawn next to it
+func (m Model) CompletionColumn() int {
+	start := m.completionStartPos
+	if start.Row >= len(m.content) {
+		return 0
+	}
+	line := m.content[start.Row]
+	col := min(start.Col, len(line))
+	return 2 + utf8.RuneCountInString(m.expandTabs(line[:col], 0)) // After the "> " prompt
+}
+
 func (m *Model) SetCompletionEngine(engine *completion.CompletionEngine) {
 	m.completionEngine = engine
 }
@@ -749,6 +903,14 @@ func (m Model) insertCompletion(text string) Model {
 		m.cursor = Position{Row: startPos.Row, Col: newCursorCol}
 	}
 
+	// A completed directory stays open for completion so its contents can
+	// be picked next
+	if m.completionState.Trigger == '@' && strings.HasSuffix(text, "/") {
+		m.completionState.Selected = 0
+		return m.updateCompletion()
+	}
+
+	m.completionState.Reset()
 	return m
 }
 
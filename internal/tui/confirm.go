@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/agent"
+	"reapo/internal/config"
+	"reapo/internal/tui/components"
+)
+
+// With confirm_requests set, each API request waits for the user's
+// go-ahead, showing its estimated size and cost. A run_task sub-agent makes
+// its own requests, so a tool batch that starts one is confirmed as a whole.
+
+// pendingRequest is an API request waiting for the user's go-ahead
+type pendingRequest struct {
+	send           tea.Cmd
+	decline        func(m Model) (Model, tea.Cmd) // Undoes the preparations for the request
+	processingText string                         // Shown again once the request is sent
+}
+
+// confirmRequest returns send, or, with confirm_requests set, asks the user
+// to confirm the request first. what names the request in the prompt and
+// inputTokens estimates its size; pass -1 when the size can't be known.
+func (m Model) confirmRequest(what string, inputTokens int, send tea.Cmd, decline func(m Model) (Model, tea.Cmd)) (Model, tea.Cmd) {
+	if !config.Get().ConfirmRequests {
+		return m, send
+	}
+
+	m.pendingRequest = &pendingRequest{send: send, decline: decline, processingText: m.processingText}
+	m.processingText = "Waiting for your go-ahead..."
+
+	text := fmt.Sprintf("Run %s? It makes its own API requests. Enter: run • Esc: cancel", what)
+	if inputTokens >= 0 {
+		text = fmt.Sprintf("Send %s: %s? Enter: send • Esc: cancel", what, requestEstimate(inputTokens))
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineWarning,
+			Text:     text,
+			Duration: 0, // Cleared once the user decides
+		}
+	}
+}
+
+// requestEstimate describes a request's size and its cost range, from no
+// output to the most the response may use
+func requestEstimate(inputTokens int) string {
+	cfg := config.Get()
+	outputTokens := cfg.MaxTokens + agent.ThinkingBudget(cfg.Effort, cfg.Model)
+
+	text := fmt.Sprintf("~%d input tokens, up to %d output", inputTokens, outputTokens)
+	if low, ok := agent.EstimateCost(cfg.Model, int64(inputTokens), 0); ok {
+		high, _ := agent.EstimateCost(cfg.Model, int64(inputTokens), outputTokens)
+		text += fmt.Sprintf(" (~$%.3f–$%.3f)", low, high)
+	}
+	return text
+}
+
+// handleRequestChoice acts on the user's answer to confirmRequest
+func (m Model) handleRequestChoice(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pending := m.pendingRequest
+	switch msg.String() {
+	case "enter", "y":
+		m = m.endRequestChoice()
+		m.processingText = pending.processingText
+		return m, pending.send
+	case "esc", "n":
+		m = m.endRequestChoice()
+		return pending.decline(m)
+	}
+	return m, nil
+}
+
+// endRequestChoice leaves the confirmation prompt
+func (m Model) endRequestChoice() Model {
+	m.pendingRequest = nil
+	if m.statusline != nil {
+		m.statusline.ClearMessage()
+	}
+	return m
+}
+
+// requestTokens estimates the input tokens of a request with the given
+// conversation, including the system prompt
+func (m Model) requestTokens(conversation []anthropic.MessageParam) int {
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		return m.countConversationTokens()
+	}
+	return countTokens(m.systemPrompt) + countTokens(string(data))
+}
+
+// declineTurn ends a turn whose next request the user cancelled
+func declineTurn(m Model) (Model, tea.Cmd) {
+	m, _ = m.interruptTurn()
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Request cancelled",
+			Duration: 3 * time.Second,
+		}
+	}
+}
+
+// declineTask stops the processing shown for a cancelled request that isn't
+// part of a turn, like /compact
+func declineTask(m Model) (Model, tea.Cmd) {
+	m.processing = false
+	m.processingText = ""
+	m.processingSpinner = nil
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Request cancelled",
+			Duration: 3 * time.Second,
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/agent"
+	"reapo/internal/config"
+	"reapo/internal/tui/components"
+)
+
+// handleEffortCommand shows the reasoning effort, or sets it to low, medium,
+// high or off. Higher effort gives the model a larger thinking budget,
+// trading latency for better answers.
+func (m Model) handleEffortCommand(level string) (tea.Model, tea.Cmd) {
+	if level == "" {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     "Effort: " + effortStatus(config.Get()),
+				Duration: 4 * time.Second,
+			}
+		}
+	}
+
+	if err := config.Set("effort", level); err != nil {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineError,
+				Text:     fmt.Sprintf("Error: %v", err),
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+
+	cfg := config.Get()
+	statusType := components.StatuslineInfo
+	if cfg.Effort != "" && !agent.SupportsThinking(cfg.Model) {
+		statusType = components.StatuslineWarning
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     statusType,
+			Text:     "Effort set to " + effortStatus(cfg),
+			Duration: 4 * time.Second,
+		}
+	}
+}
+
+// effortStatus describes the effort setting and the thinking budget it gives
+func effortStatus(cfg config.Config) string {
+	switch {
+	case cfg.Effort == "":
+		return "off (no extended thinking)"
+	case !agent.SupportsThinking(cfg.Model):
+		return fmt.Sprintf("%s (ignored: %s doesn't support extended thinking)", cfg.Effort, cfg.Model)
+	default:
+		return fmt.Sprintf("%s (%d thinking tokens)", cfg.Effort, agent.ThinkingBudget(cfg.Effort, cfg.Model))
+	}
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/auth"
+	"reapo/internal/config"
+	"reapo/internal/tui/components"
+)
+
+// Some API errors fail every request until the user acts, like a revoked
+// login. Rather than leaving each message to error, they open a modal that
+// explains the recovery steps. Problems with the client's own settings,
+// which are fixed for the session, quit once acknowledged so reapo can be
+// restarted, saving the conversation first for --continue.
+
+// fatalProblem returns the problem behind a failed request if retrying
+// can't help, or nil for errors that may pass, like rate limits
+func fatalProblem(err error) *components.FatalProblem {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+	detail := apiErrorMessage(apiErr)
+
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized:
+		return &components.FatalProblem{
+			Title:  "Authentication failed",
+			Detail: fmt.Sprintf("The API rejected your credentials (%s): %s", auth.GetAuthStatus(), detail),
+			Steps: []string{
+				"Log in again with /login if you use a Claude Max account",
+				"Or set a valid ANTHROPIC_API_KEY and restart reapo (use /logout first so the saved login doesn't take priority)",
+			},
+			Login: true,
+		}
+	case apiErr.StatusCode == http.StatusForbidden:
+		return &components.FatalProblem{
+			Title:  "Permission denied",
+			Detail: detail,
+			Steps: []string{
+				"Switch to a model your account can use with /model",
+				"Or log in with another account using /login",
+			},
+			Login: true,
+		}
+	case apiErr.StatusCode == http.StatusNotFound && strings.HasPrefix(detail, "model:"):
+		return &components.FatalProblem{
+			Title:  "Model not found",
+			Detail: detail,
+			Steps:  []string{"Choose an available model with /model"},
+		}
+	case apiErr.StatusCode == http.StatusNotFound && auth.BaseURL() != "":
+		// The endpoint is set when the client is created, so it takes a restart
+		steps := []string{"Fix base_url in the config file or ANTHROPIC_BASE_URL, then restart reapo"}
+		if config.Get().AutosaveSeconds > 0 {
+			steps = append(steps, "Run reapo --continue to pick up this conversation")
+		}
+		return &components.FatalProblem{
+			Title:  "API endpoint not found",
+			Detail: fmt.Sprintf("%s: %s", auth.BaseURL(), detail),
+			Steps:  steps,
+			Exit:   true,
+		}
+	}
+	return nil
+}
+
+// apiErrorMessage returns the message in an API error's body, or its status
+// when the body has none
+func apiErrorMessage(apiErr *anthropic.Error) string {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(apiErr.RawJSON()), &body); err != nil || body.Error.Message == "" {
+		return fmt.Sprintf("status %d %s", apiErr.StatusCode, http.StatusText(apiErr.StatusCode))
+	}
+	return body.Error.Message
+}
+
+// showFatalProblem opens the fatal error modal for a failed request's error,
+// if it is one that retrying can't fix
+func (m Model) showFatalProblem(err error) Model {
+	if problem := fatalProblem(err); problem != nil && !m.fatalModal.IsVisible() {
+		m.fatalModal.Show(*problem, m.viewport.width, m.viewport.height)
+	}
+	return m
+}
+
+// quitOnFatal quits after the user acknowledged a problem reapo can't
+// recover from. With autosave on, the conversation is saved first so it can
+// be restored with --continue.
+func (m Model) quitOnFatal() (tea.Model, tea.Cmd) {
+	problem := m.fatalModal.Problem()
+	m.fatalExit = &problem
+	if config.Get().AutosaveSeconds <= 0 {
+		return m, tea.Quit
+	}
+	return m, tea.Sequence(m.autosave(), tea.Quit)
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/config"
+	"reapo/internal/tui/components"
+)
+
+// Chat search highlights every match of a query in the transcript and
+// steps between the matching messages, scrolling each one into view.
+// It only reads m.messages; the input's own / search is separate.
+
+// matchesFind reports whether a message's text contains the find query, ignoring case
+func (m Model) matchesFind(msg components.Message) bool {
+	return isQuotable(msg) && strings.Contains(strings.ToLower(msg.Content), strings.ToLower(m.findQuery))
+}
+
+// findMatch returns the nearest matching message index from start in
+// direction step (-1 for older, 1 for newer), or -1 if there is none
+func (m Model) findMatch(start, step int) int {
+	for i := start; i >= 0 && i < len(m.messages); i += step {
+		if m.matchesFind(m.messages[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// startFind searches the chat for query, focusing the most recent match
+func (m Model) startFind(query string) (tea.Model, tea.Cmd) {
+	if query == "" {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "Usage: /find <text>",
+				Duration: 3 * time.Second,
+			}
+		}
+	}
+
+	m.findQuery = query
+	index := m.findMatch(len(m.messages)-1, -1)
+	if index < 0 {
+		m.findQuery = ""
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     fmt.Sprintf("No messages contain %q", query),
+				Duration: 3 * time.Second,
+			}
+		}
+	}
+
+	m.focusedMessage = index
+	return m, m.findStatus()
+}
+
+// handleFind steps between matches or ends the search
+func (m Model) handleFind(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if m.keymap.Matches(config.ActionFind, key) {
+		// Pressing the find key again keeps moving to older matches
+		key = "n"
+	}
+
+	switch key {
+	case "n", "k", "up":
+		if index := m.findMatch(m.focusedMessage-1, -1); index >= 0 {
+			m.focusedMessage = index
+		}
+	case "N", "j", "down":
+		if index := m.findMatch(m.focusedMessage+1, 1); index >= 0 {
+			m.focusedMessage = index
+		}
+	case "g", "home":
+		m.focusedMessage = m.findMatch(0, 1)
+	case "G", "end":
+		m.focusedMessage = m.findMatch(len(m.messages)-1, -1)
+	case "esc", "enter":
+		return m.endFind(), nil
+	default:
+		return m, nil
+	}
+	return m, m.findStatus()
+}
+
+// findStatus shows the position of the focused match in the statusline
+func (m Model) findStatus() tea.Cmd {
+	current, total := 0, 0
+	for i, msg := range m.messages {
+		if m.matchesFind(msg) {
+			total++
+			if i <= m.focusedMessage {
+				current++
+			}
+		}
+	}
+
+	text := fmt.Sprintf("Match %d of %d for %q: n/N for older/newer, Esc to close", current, total, m.findQuery)
+	return func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     text,
+			Duration: 0, // Cleared when the search ends
+		}
+	}
+}
+
+// endFind clears the search highlighting and returns the chat to the newest messages
+func (m Model) endFind() Model {
+	m.findQuery = ""
+	return m.endMessageSelection()
+}
+
+// openFind starts typing a /find command when the input is empty
+func (m Model) openFind() (tea.Model, tea.Cmd) {
+	if m.textarea.Value() != "" {
+		return m, nil
+	}
+	m.textarea.SetValue("/find ")
+	m.textarea.InsertAtEnd()
+	return m, nil
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// gitStatusInterval is how often the footer's git branch is refreshed
+const gitStatusInterval = 5 * time.Second
+
+// GitStatusMsg reports the git branch of the working directory.
+// Branch is empty when the directory isn't inside a git repository.
+type GitStatusMsg struct {
+	Branch string
+	Dirty  bool
+}
+
+// refreshGitStatus reads the current branch and whether the work tree has changes
+func refreshGitStatus() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		out, err := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+		if err != nil {
+			return GitStatusMsg{}
+		}
+		branch := strings.TrimSpace(string(out))
+		if branch == "HEAD" {
+			// Detached HEAD: show the commit instead
+			if out, err := exec.CommandContext(ctx, "git", "rev-parse", "--short", "HEAD").Output(); err == nil {
+				branch = strings.TrimSpace(string(out))
+			}
+		}
+
+		status, err := exec.CommandContext(ctx, "git", "status", "--porcelain").Output()
+		return GitStatusMsg{
+			Branch: branch,
+			Dirty:  err == nil && len(strings.TrimSpace(string(status))) > 0,
+		}
+	}
+}
+
+// scheduleGitStatus refreshes the git status again after gitStatusInterval
+func scheduleGitStatus() tea.Cmd {
+	return tea.Tick(gitStatusInterval, func(time.Time) tea.Msg {
+		return refreshGitStatus()()
+	})
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"reapo/internal/config"
+	"reapo/internal/tui/components/vimtextarea"
+)
+
+// keyHints renders a one-line reminder of the main keys for the current
+// input mode, using the configured keymap. Toggled with /hints.
+func (m Model) keyHints() string {
+	send := keyLabel(m.keymap, config.ActionSend)
+	sendInsert := keyLabel(m.keymap, config.ActionSendInsert)
+	palette := keyLabel(m.keymap, config.ActionCommandPalette)
+	focus := keyLabel(m.keymap, config.ActionCycleFocus)
+
+	var hints []string
+	switch {
+	case m.scratchOpen:
+		copyKey := send
+		if m.scratch.Mode() != vimtextarea.Normal && !m.scratch.Plain() {
+			copyKey = sendInsert
+		}
+		hints = []string{"scratch buffer, not sent", copyKey + " copy to input", keyLabel(m.keymap, config.ActionToggleScratch) + " back to input"}
+	case m.chatFocused:
+		hints = []string{"j/k scroll", "g/G top/bottom", "Enter select message", focus + " input"}
+	case m.textarea.Plain():
+		hints = []string{send + " send", "Alt+Enter newline", palette + " commands", "/help"}
+	case m.textarea.Mode() == vimtextarea.Insert:
+		hints = []string{sendInsert + " send", "Esc normal mode", palette + " commands", "/help"}
+	case m.textarea.Mode() == vimtextarea.Visual:
+		hints = []string{sendInsert + " send", "y yank", "d delete", "Esc normal mode"}
+	default:
+		hints = []string{send + " send", "i insert mode", focus + " focus chat", palette + " commands", "/help"}
+	}
+
+	text := ansi.Truncate("  "+strings.Join(hints, " • "), m.viewport.width, "…")
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(text)
+}
+
+// keyLabel returns the first key bound to action for display, e.g. "Ctrl+S"
+func keyLabel(keymap config.Keymap, action string) string {
+	keys := keymap[action]
+	if len(keys) == 0 {
+		return "(unbound)"
+	}
+
+	parts := strings.Split(keys[0], "+")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
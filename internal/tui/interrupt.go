@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/config"
+	"reapo/internal/tui/components"
+	"reapo/internal/tui/components/vimtextarea"
+)
+
+// With interrupt_on_type set, starting to type while a response is being
+// generated cancels the turn's requests and tools, and anything the turn
+// still delivers afterwards is discarded.
+
+// turnRequest is an inference request or tool batch that belongs to a turn
+type turnRequest struct {
+	turnID string
+	cancel context.CancelFunc
+}
+
+// turnRequests tracks the requests in flight for each turn. Like runningTools
+// it lives outside Model because requests run in goroutines.
+var turnRequests struct {
+	sync.Mutex
+	requests []*turnRequest
+}
+
+// startTurnRequest returns a context derived from parent that is cancelled
+// when the turn is interrupted, along with a function to call once the
+// request has finished
+func startTurnRequest(parent context.Context, turnID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	entry := &turnRequest{turnID: turnID, cancel: cancel}
+
+	turnRequests.Lock()
+	turnRequests.requests = append(turnRequests.requests, entry)
+	turnRequests.Unlock()
+
+	return ctx, func() {
+		// Other requests of the same turn may still be running, so only
+		// this one is removed
+		turnRequests.Lock()
+		for i, request := range turnRequests.requests {
+			if request == entry {
+				turnRequests.requests = append(turnRequests.requests[:i], turnRequests.requests[i+1:]...)
+				break
+			}
+		}
+		turnRequests.Unlock()
+		cancel()
+	}
+}
+
+// cancelTurnRequests cancels every request in flight for a turn
+func cancelTurnRequests(turnID string) {
+	turnRequests.Lock()
+	defer turnRequests.Unlock()
+
+	kept := make([]*turnRequest, 0, len(turnRequests.requests))
+	for _, request := range turnRequests.requests {
+		if request.turnID == turnID {
+			request.cancel()
+			continue
+		}
+		kept = append(kept, request)
+	}
+	turnRequests.requests = kept
+}
+
+// interruptOnType interrupts the turn in flight if the last key started
+// Insert mode or changed the input
+func (m Model) interruptOnType(mode vimtextarea.Mode, value string) (Model, tea.Cmd) {
+	if m.activeTurn == "" || !config.Get().InterruptOnType {
+		return m, nil
+	}
+	startedInsert := mode != vimtextarea.Insert && m.textarea.Mode() == vimtextarea.Insert
+	if !startedInsert && m.textarea.Value() == value {
+		return m, nil
+	}
+	return m.interruptTurn()
+}
+
+// interruptTurn cancels the turn in flight and drops its partial response
+func (m Model) interruptTurn() (Model, tea.Cmd) {
+	turnID := m.activeTurn
+	cancelTurnRequests(turnID)
+	m.interruptedTurns[turnID] = true
+	m.activeTurn = ""
+	m.turnEdited = false
+
+	m.processing = false
+	m.processingText = ""
+	m.processingSpinner = nil
+
+	for i, msg := range m.messages {
+		if msg.ID == turnID {
+			m.messages = append(m.messages[:i], m.messages[i+1:]...)
+			break
+		}
+	}
+	m.contextTokens = m.countConversationTokens()
+
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Response interrupted",
+			Duration: 3 * time.Second,
+		}
+	}
+}
+
+// interrupted reports whether a message belongs to an interrupted turn
+func (m Model) interrupted(turnID string) bool {
+	return m.interruptedTurns[turnID]
+}
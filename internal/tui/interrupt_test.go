@@ -0,0 +1,26 @@
+package tui
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFinishedTurnRequestLeavesOthersCancellable(t *testing.T) {
+	first, doneFirst := startTurnRequest(context.Background(), "turn")
+	defer doneFirst()
+	second, doneSecond := startTurnRequest(context.Background(), "turn")
+
+	// The second request finishing mustn't forget the first, still running
+	doneSecond()
+	if second.Err() == nil {
+		t.Error("finished request's context wasn't cancelled")
+	}
+	if first.Err() != nil {
+		t.Fatal("finishing one request cancelled another")
+	}
+
+	cancelTurnRequests("turn")
+	if first.Err() == nil {
+		t.Error("interrupting the turn didn't cancel its running request")
+	}
+}
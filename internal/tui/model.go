@@ -9,6 +9,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/google/uuid"
 	"reapo/internal/agent"
+	"reapo/internal/auth"
+	"reapo/internal/config"
+	"reapo/internal/personas"
 	"reapo/internal/tools"
 	"reapo/internal/tui/completion"
 	"reapo/internal/tui/components"
@@ -19,6 +22,7 @@ import (
 type Model struct {
 	messages []components.Message
 	textarea vimtextarea.Model
+	scratch  vimtextarea.Model // Notes kept apart from the conversation, see scratch.go
 	viewport struct {
 		width  int
 		height int
@@ -33,10 +37,42 @@ type Model struct {
 	contextTokens     int    // Current context window usage in tokens
 	maxContextTokens  int    // Maximum context window size (200k for both models)
 	currentModel      string // Current model being used
+	previousModel     string // Model used before the last switch, for F2 toggling
+	showTodos         bool   // Whether the todo panel is shown beside the chat
+	verbose           bool   // Whether every tool result is shown, with full inputs and outputs
+	scratchOpen       bool   // Whether the scratch buffer is shown in place of the input
+	offline           bool   // Whether the last request failed to reach the API
+	focusedMessage    int    // Index of the message selected for quoting, or -1 when not selecting
+	chatFocused       bool   // Whether keys scroll the chat instead of going to the input
+	chatScroll        int    // Lines the chat is scrolled back from the newest
+	gitBranch         string // Git branch of the working directory, empty outside a repository
+	gitDirty          bool   // Whether the git work tree has uncommitted changes
+	persona           string // Name of the active persona
+	editingMessageID  string // ID of the earlier user message being edited, if any
+	findQuery         string // Text being searched for in the chat, empty when not searching
+	systemPrompt      string // System prompt of the active persona
+	keymap            config.Keymap // Key bindings for top-level actions, read at startup
+	pendingOversize   bool          // Waiting for the user to decide what to do with an oversized message
+	pendingRequest    *pendingRequest // API request waiting for the user's go-ahead, with confirm_requests set
+	confirmOversize   bool          // The user chose to send the oversized message anyway
+	pendingRecovery   *recovery     // Conversation from an unclean exit, waiting for the user to restore or discard it
+	sessionID         string        // Name the conversation is archived under, see archiveSession
+	turnEdited        bool          // A file-editing tool succeeded during the current turn
+	verifyAttempts    int           // Automatic fix attempts made for the last message the user sent
+	activeTurn        string          // Agent message ID of the turn being generated, empty when idle
+	interruptedTurns  map[string]bool // Turns interrupted by typing, whose late results are discarded
 	spinners          map[string]*components.SpinnerComponent // Track spinners by message ID
 	helpModal         *components.HelpModal                   // Help modal
 	statusModal       *components.StatusModal                 // Status modal
+	configModal       *components.ConfigModal                 // Config modal
+	debugModal        *components.DebugModal                  // Debug modal
+	commandPalette    *components.CommandPalette              // Command palette (Ctrl+P)
+	onboardingModal   *components.OnboardingModal             // First-run modal shown when no auth is configured
+	fatalModal        *components.FatalModal                  // Explains errors that fail every request
+	fatalExit         *components.FatalProblem                // Problem that made the user quit, if any
 	statusline        *components.StatuslineComponent         // Statusline for messages
+	clearedMessages   []components.Message                    // Messages removed by the last /clear, for undo
+	clearedAt         time.Time                               // When /clear last ran
 	// Auth state
 	authVerifier      string // OAuth verifier for code exchange
 	authModal         components.AuthModal
@@ -60,6 +96,9 @@ type MessageUpdateMsg struct {
 	Status    components.MessageStatus
 	Progress  *components.Progress
 	ToolInfo  *components.ToolInfo
+	Truncated bool // Response stopped at the max_tokens limit
+	Append    bool // Add Content to the existing message instead of replacing it
+	Err       error // Why the request failed, if it did
 }
 
 // ToolInvocationMsg represents a tool being invoked
@@ -72,12 +111,20 @@ type ToolInvocationMsg struct {
 
 // ToolResultMsg represents a tool execution result
 type ToolResultMsg struct {
-	ToolName  string
-	ToolID    string
-	Output    string
-	Error     string
-	Duration  string
-	MessageID string
+	ToolName      string
+	ToolID        string
+	Output        string
+	Error         string
+	ErrorCategory agent.ToolErrorCategory
+	Duration      string
+	MessageID     string
+}
+
+// ToolsExecutedMsg carries the results of a batch of tool executions
+type ToolsExecutedMsg struct {
+	Conversation   []anthropic.MessageParam
+	Results        []ToolResultMsg
+	AgentMessageID string
 }
 
 // AnimationTickMsg represents a tick for spinner animations
@@ -88,6 +135,7 @@ type ProcessMessageSequenceMsg struct {
 	UserMessage    string
 	UserMessageID  string
 	AgentMessageID string
+	NoTools        bool // Answer directly without offering the model any tools (/ask)
 }
 
 // AgentStatusMsg represents agent thinking/status updates
@@ -127,6 +175,12 @@ type CompactConversationMsg struct {
 	IsAuto  bool // Whether this is an automatic compaction
 }
 
+// SummaryMsg carries a conversation summary requested with /summary
+type SummaryMsg struct {
+	Summary string
+	Error   error
+}
+
 // ShowStatuslineMsg displays a message in the statusline
 type ShowStatuslineMsg struct {
 	Type     components.StatuslineMessageType
@@ -144,9 +198,12 @@ var systemPromptContent string
 func NewModel(client anthropic.Client, toolDefs []tools.ToolDefinition) Model {
 	// Initialize vim textarea
 	ta := vimtextarea.New()
-	ta.SetPlaceholder("Type a message... (Enter in Normal, Ctrl+S in Insert/Visual)")
+	setInputMode(&ta, config.Get().PlainInput)
 	ta.Focus()
 	ta.SetHeight(1)
+	ta.SetTabWidth(config.Get().TabWidth)
+	ta.SetExpandTab(config.Get().ExpandTab)
+	ta.SetAutoIndent(config.Get().AutoIndent)
 
 	// Get working directory for completion engine
 	workingDir, err := os.Getwd()
@@ -166,25 +223,48 @@ func NewModel(client anthropic.Client, toolDefs []tools.ToolDefinition) Model {
 	model := Model{
 		messages:         []components.Message{},
 		textarea:         ta,
+		scratch:          newScratch(),
 		agent:            chatAgent,
 		client:           client,
 		toolDefs:         toolDefs,
 		contextTokens:    initialTokens,
 		maxContextTokens: 200000, // 200k tokens for both Sonnet 4 and Opus 4
-		currentModel:     "claude-sonnet-4",
+		currentModel:     config.Get().Model,
+		focusedMessage:   -1,
+		persona:          personas.Default,
+		sessionID:        generateMessageID(),
+		systemPrompt:     systemPromptContent,
+		keymap:           config.Get().ResolvedKeymap(),
 		spinners:         make(map[string]*components.SpinnerComponent),
+		interruptedTurns: make(map[string]bool),
 		helpModal:        components.NewHelpModal(),
 		statusModal:      components.NewStatusModal(),
+		configModal:      components.NewConfigModal(),
+		debugModal:       components.NewDebugModal(),
+		commandPalette:   components.NewCommandPalette(),
+		onboardingModal:  components.NewOnboardingModal(),
+		fatalModal:       components.NewFatalModal(),
 		statusline:       components.NewStatuslineComponent(0), // Width will be set on WindowSizeMsg
 		authModal:        components.NewAuthModal(),
 	}
 
+	// Explain how to authenticate up front instead of failing on the first request.
+	// The size is filled in by the first WindowSizeMsg.
+	if !auth.IsAuthenticated() {
+		model.onboardingModal.Show(0, 0)
+	}
+
 	return model
 }
 
 // Init initializes the TUI model
 func (m Model) Init() tea.Cmd {
-	return m.textarea.Init()
+	return tea.Batch(m.textarea.Init(), refreshGitStatus(), loadRecovery(), loadScratch(), scheduleAutosave())
+}
+
+// requestTimeout returns the configured timeout for API requests
+func requestTimeout() time.Duration {
+	return time.Duration(config.Get().TimeoutSeconds) * time.Second
 }
 
 // generateMessageID creates a unique UUIDv7-based message ID
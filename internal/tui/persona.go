@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/agent"
+	"reapo/internal/personas"
+	"reapo/internal/tui/components"
+)
+
+// switchPersona swaps the system prompt for the named persona, keeping the
+// conversation, or lists the available personas when no name is given
+func (m Model) switchPersona(name string) (tea.Model, tea.Cmd) {
+	if name == "" {
+		names, err := personas.List()
+		if err != nil {
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineError,
+					Text:     fmt.Sprintf("Error: %v", err),
+					Duration: 6 * time.Second,
+				}
+			}
+		}
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     fmt.Sprintf("Persona: %s (available: %s)", m.persona, strings.Join(names, ", ")),
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+
+	prompt := systemPromptContent
+	if name != personas.Default {
+		var err error
+		prompt, err = personas.Load(name)
+		if err != nil {
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineError,
+					Text:     fmt.Sprintf("Error: %v", err),
+					Duration: 6 * time.Second,
+				}
+			}
+		}
+	}
+
+	// In-flight requests keep the old agent; the next request uses the new prompt
+	m.persona = name
+	m.systemPrompt = prompt
+	m.agent = agent.NewAgent(&m.client, nil, m.toolDefs, m.systemPrompt)
+	m.contextTokens = m.countConversationTokens()
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     fmt.Sprintf("Switched to persona %q", name),
+			Duration: 3 * time.Second,
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/prompts"
+	"reapo/internal/tui/components"
+)
+
+// savePromptTemplate saves a reusable prompt template. args is "<name> [text]";
+// without text, the last message sent is saved since the input itself holds the command.
+func (m Model) savePromptTemplate(args string) (tea.Model, tea.Cmd) {
+	name, text, _ := strings.Cut(args, " ")
+	text = strings.TrimSpace(text)
+	if name == "" {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "Usage: /save-prompt <name> [text]",
+				Duration: 4 * time.Second,
+			}
+		}
+	}
+
+	if text == "" {
+		text = m.lastUserMessage()
+		if text == "" {
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineWarning,
+					Text:     "Nothing to save: send a message first or pass the text after the name",
+					Duration: 4 * time.Second,
+				}
+			}
+		}
+	}
+
+	if err := prompts.Save(name, text); err != nil {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineError,
+				Text:     fmt.Sprintf("Error: Failed to save prompt: %v", err),
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     fmt.Sprintf("Saved prompt template %q (load it with /load-prompt %s)", name, name),
+			Duration: 4 * time.Second,
+		}
+	}
+}
+
+// loadPromptTemplate puts a saved template into the input for editing, or
+// lists the saved templates when no name is given
+func (m Model) loadPromptTemplate(name string) (tea.Model, tea.Cmd) {
+	if name == "" {
+		names, err := prompts.List()
+		if err != nil {
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineError,
+					Text:     fmt.Sprintf("Error: %v", err),
+					Duration: 6 * time.Second,
+				}
+			}
+		}
+		if len(names) == 0 {
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineWarning,
+					Text:     "No prompt templates saved yet (use /save-prompt <name>)",
+					Duration: 4 * time.Second,
+				}
+			}
+		}
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     "Prompt templates: " + strings.Join(names, ", "),
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+
+	text, err := prompts.Load(name)
+	if err != nil {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineError,
+				Text:     fmt.Sprintf("Error: %v", err),
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+
+	m.textarea.SetValue(strings.TrimRight(text, "\n"))
+	m = m.fitTextareaHeight()
+	m.textarea.InsertAtEnd()
+	return m, nil
+}
+
+// lastUserMessage returns the content of the most recent user message
+func (m Model) lastUserMessage() string {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Role == "user" && m.messages[i].Type != components.MessageTypeToolResult {
+			return m.messages[i].Content
+		}
+	}
+	return ""
+}
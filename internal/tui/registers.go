@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"reapo/internal/tui/components"
+	"reapo/internal/tui/components/vimtextarea"
+)
+
+// registerPreviewWidth is how much of each register /registers shows
+const registerPreviewWidth = 60
+
+// showRegisters lists the input's vim registers in the chat, or empties
+// them with /registers clear
+func (m Model) showRegisters(args string) (tea.Model, tea.Cmd) {
+	if args == "clear" {
+		m.textarea.ClearRegisters()
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     "Registers cleared",
+				Duration: 3 * time.Second,
+			}
+		}
+	}
+
+	registers := m.textarea.Registers()
+	if len(registers) == 0 {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     "Registers are empty; yank with y, or \"ay to use register a",
+				Duration: 4 * time.Second,
+			}
+		}
+	}
+
+	// The unnamed register sorts first since " comes before the letters
+	names := make([]rune, 0, len(registers))
+	for name := range registers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	var content strings.Builder
+	content.WriteString("Registers (paste with \"ap, clear with /registers clear):")
+	for _, name := range names {
+		text := registers[name]
+		preview := ansi.Truncate(strings.ReplaceAll(text, "\n", " ⏎ "), registerPreviewWidth, "…")
+		if lines := strings.Count(text, "\n") + 1; lines > 1 {
+			preview += fmt.Sprintf(" (%d lines)", lines)
+		}
+		if name == vimtextarea.UnnamedRegister {
+			preview += " (unnamed)"
+		}
+		fmt.Fprintf(&content, "\n\"%c  %s", name, preview)
+	}
+
+	// Shown as a system message so it isn't sent to the model
+	m.messages = append(m.messages, components.Message{
+		ID:        generateMessageID(),
+		Role:      "system",
+		Content:   content.String(),
+		Type:      components.MessageTypeText,
+		Status:    components.MessageCompleted,
+		Timestamp: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	return m, nil
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/tui/components"
+)
+
+// resumeConversation handles /resume, which lists the sessions saved for
+// this project, or restores the one numbered in that list. With "all" first
+// in args, it lists and restores the sessions of every project instead.
+func (m Model) resumeConversation(args string) (tea.Model, tea.Cmd) {
+	all := false
+	if rest, ok := strings.CutPrefix(args, "all"); ok && (rest == "" || rest[0] == ' ') {
+		all, args = true, strings.TrimSpace(rest)
+	}
+	if args == "" {
+		return m.listSavedConversations(all)
+	}
+
+	n, err := strconv.Atoi(args)
+	saved := savedSessions(all, m.sessionID)
+	if err != nil || n < 1 || n > len(saved) {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "Usage: /resume [all] [number from the /resume list]",
+				Duration: 4 * time.Second,
+			}
+		}
+	}
+	return m.restoreConversation(saved[n-1])
+}
+
+// listSavedConversations shows the sessions saved for this project, or for
+// every project with all
+func (m Model) listSavedConversations(all bool) (tea.Model, tea.Cmd) {
+	saved := savedSessions(all, m.sessionID)
+	if len(saved) == 0 {
+		text := "No conversations saved for this project (/resume all lists every project's)"
+		if all {
+			text = "No conversations saved"
+		}
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     text,
+				Duration: 3 * time.Second,
+			}
+		}
+	}
+
+	var content strings.Builder
+	if all {
+		content.WriteString("Conversations saved for every project (restore with /resume all <number>):")
+	} else {
+		content.WriteString("Conversations saved for this project (restore with /resume <number>, or see every project's with /resume all):")
+	}
+	for i, session := range saved {
+		fmt.Fprintf(&content, "\n%d. %s", i+1, session.SavedAt.Format("Jan 2 15:04"))
+		if all {
+			fmt.Fprintf(&content, "  %s", session.WorkingDir)
+		}
+		fmt.Fprintf(&content, " (%d messages) %s", len(session.Messages), sessionPreview(session.Messages))
+	}
+
+	// Shown as a system message so it isn't sent to the model
+	m.messages = append(m.messages, components.Message{
+		ID:        generateMessageID(),
+		Role:      "system",
+		Content:   content.String(),
+		Type:      components.MessageTypeText,
+		Status:    components.MessageCompleted,
+		Timestamp: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	return m, nil
+}
+
+// sessionPreview returns the start of a session's first user message, to
+// tell sessions apart in the /resume list
+func sessionPreview(messages []components.Message) string {
+	for _, msg := range messages {
+		if msg.Role != "user" || strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		preview := strings.Join(strings.Fields(msg.Content), " ")
+		if runes := []rune(preview); len(runes) > 50 {
+			preview = string(runes[:50]) + "…"
+		}
+		return fmt.Sprintf("%q", preview)
+	}
+	return ""
+}
+
+// restoreConversation replaces an empty conversation with a saved session,
+// which the conversation then continues, so it is archived in its place
+func (m Model) restoreConversation(session savedSession) (tea.Model, tea.Cmd) {
+	for _, msg := range m.messages {
+		if sentInHistory(msg) {
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineWarning,
+					Text:     "Start a fresh conversation with /new before resuming another",
+					Duration: 4 * time.Second,
+				}
+			}
+		}
+	}
+	if m.processing {
+		return m, nil
+	}
+
+	m.messages = restoredMessages(session.Messages)
+	m.contextTokens = m.countConversationTokens()
+	m.sessionID = session.ID
+
+	text := fmt.Sprintf("Restored %d messages saved %s", len(m.messages), session.SavedAt.Format("Jan 2 15:04"))
+	if session.WorkingDir != m.workingDir() {
+		text = fmt.Sprintf("Restored %d messages from %s", len(m.messages), session.WorkingDir)
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     text,
+			Duration: 3 * time.Second,
+		}
+	}
+}
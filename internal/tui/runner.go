@@ -1,26 +1,114 @@
 package tui
 
 import (
-	"log"
+	"context"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"reapo/internal/config"
+	"reapo/internal/logger"
 	"reapo/internal/tools"
+	"reapo/internal/tui/components"
 )
 
-// RunTUI starts the TUI interface
-func RunTUI(client anthropic.Client, toolDefs []tools.ToolDefinition, systemPrompt string) {
+// AltScreen runs the TUI in the terminal's alternate screen, which is
+// restored on exit. Without it (--no-altscreen) the TUI renders inline and
+// the conversation is left in the terminal's scrollback.
+var AltScreen = true
+
+// RunTUI starts the TUI interface and blocks until it exits.
+// Cancelling ctx (e.g. on SIGTERM) quits the program cleanly.
+func RunTUI(ctx context.Context, client anthropic.Client, toolDefs []tools.ToolDefinition, systemPrompt string) error {
 	// Set the system prompt for the TUI package
 	systemPromptContent = systemPrompt
 
 	// Create the TUI model
 	m := NewModel(client, toolDefs)
 
-	// Run the Bubble Tea program
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		log.Printf("Error: %s\n", err.Error())
-		os.Exit(1)
+	// Run the Bubble Tea program; signals are handled by the caller via ctx
+	opts := []tea.ProgramOption{tea.WithoutSignalHandler()}
+	if AltScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	p := tea.NewProgram(m, opts...)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			logger.Info("Received shutdown signal, quitting TUI")
+			p.Quit()
+		case <-done:
+		}
+	}()
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return err
+	}
+
+	// The scratch buffer is saved whenever it is closed, so only an open
+	// one has unsaved notes
+	final, ok := finalModel.(Model)
+	if ok && final.scratchOpen {
+		saveScratch(final.scratch.Value())
+	}
+	if ok && !AltScreen {
+		printTranscript(final)
+	}
+
+	// Quitting over an unrecoverable error keeps the conversation for
+	// --continue and reports the problem now that the screen is restored
+	if ok && final.fatalExit != nil {
+		return fmt.Errorf("%s: %s", final.fatalExit.Title, final.fatalExit.Detail)
+	}
+
+	// Being terminated by a signal isn't a clean exit, so the conversation is
+	// saved one last time for recovery instead of being cleared
+	if ctx.Err() != nil {
+		if ok && config.Get().AutosaveSeconds > 0 {
+			if save := final.autosave(); save != nil {
+				save()
+			}
+		}
+		return nil
+	}
+
+	// A clean exit archives the conversation, so it doesn't need crash
+	// recovery, unless the user never decided what to do with a
+	// conversation that was recovered
+	if ok {
+		if archive := final.archiveSession(); archive != nil {
+			archive()
+		}
+	}
+	if !ok || final.pendingRecovery == nil {
+		clearRecovery()
+	}
+	return nil
+}
+
+// printTranscript replaces the last frame of an inline TUI with the whole
+// conversation, so it stays in the terminal's scrollback after exiting
+func printTranscript(m Model) {
+	// The renderer leaves the cursor on the frame's last line
+	frameLines := min(strings.Count(m.View(), "\n")+1, max(m.viewport.height, 1))
+	erase := "\r" + ansi.EraseScreenBelow
+	if frameLines > 1 {
+		erase = "\r" + ansi.CursorUp(frameLines-1) + ansi.EraseScreenBelow
+	}
+	fmt.Fprint(os.Stdout, erase)
+
+	if len(m.messages) == 0 {
+		return
 	}
+	chat := components.NewChatComponent(m.messages, 0, m.viewport.width)
+	chat.SetVerbose(m.verbose)
+	fmt.Fprintln(os.Stdout, chat.Transcript())
 }
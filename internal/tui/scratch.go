@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/config"
+	"reapo/internal/logger"
+	"reapo/internal/tui/components"
+	"reapo/internal/tui/components/vimtextarea"
+)
+
+// The scratch buffer is a second input for notes that are never sent to the
+// model. It takes the input's place while open, and its text can be copied
+// into the input to stage a message. Each project keeps its own buffer,
+// saved when it is closed or reapo exits with it open.
+
+// scratchDirName is the directory in the data directory holding a scratch
+// buffer per project, named like the recovery files
+const scratchDirName = "scratch"
+
+// ScratchLoadedMsg carries the scratch buffer saved for this project
+type ScratchLoadedMsg struct {
+	Text string
+}
+
+// newScratch creates the scratch buffer's textarea
+func newScratch() vimtextarea.Model {
+	scratch := vimtextarea.New()
+	scratch.SetPlain(config.Get().PlainInput)
+	scratch.SetPlaceholder("Notes for yourself, never sent to the model")
+	scratch.Focus()
+	scratch.SetHeight(1)
+	scratch.SetTabWidth(config.Get().TabWidth)
+	scratch.SetExpandTab(config.Get().ExpandTab)
+	scratch.SetAutoIndent(config.Get().AutoIndent)
+	return scratch
+}
+
+// loadScratch reads the scratch buffer saved for this project
+func loadScratch() tea.Cmd {
+	return func() tea.Msg {
+		path, err := projectFile(scratchDirName, ".md")
+		if err != nil {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logger.Error("Failed to read scratch buffer: %v", err)
+			}
+			return nil
+		}
+		return ScratchLoadedMsg{Text: string(data)}
+	}
+}
+
+// saveScratch writes the scratch buffer for this project, removing the file
+// once the buffer is empty
+func saveScratch(text string) {
+	path, err := projectFile(scratchDirName, ".md")
+	if err != nil {
+		return
+	}
+	if strings.TrimSpace(text) == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logger.Error("Failed to remove scratch buffer: %v", err)
+		}
+		return
+	}
+	if err := writeFileAtomic(path, []byte(text)); err != nil {
+		logger.Error("Failed to save scratch buffer: %v", err)
+	}
+}
+
+// toggleScratch opens the scratch buffer in place of the input, or closes it
+// and saves it
+func (m Model) toggleScratch() (tea.Model, tea.Cmd) {
+	if m.scratchOpen {
+		return m.closeScratch(), nil
+	}
+
+	m.scratchOpen = true
+	toggle := keyLabel(m.keymap, config.ActionToggleScratch)
+	send := keyLabel(m.keymap, config.ActionSend)
+	if !m.scratch.Plain() {
+		send += "/" + keyLabel(m.keymap, config.ActionSendInsert)
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Scratch buffer: never sent to the model. " + send + " copies it to the input, " + toggle + " returns to the input",
+			Duration: 0, // Cleared when the scratch buffer is closed
+		}
+	}
+}
+
+// closeScratch returns to the input, saving the scratch buffer
+func (m Model) closeScratch() Model {
+	m.scratchOpen = false
+	saveScratch(m.scratch.Value())
+	if m.statusline != nil {
+		m.statusline.ClearMessage()
+	}
+	return m
+}
+
+// handleScratch edits the open scratch buffer. The send keys copy it to the
+// input instead of sending it.
+func (m Model) handleScratch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch {
+	case m.keymap.Matches(config.ActionToggleScratch, key):
+		return m.toggleScratch()
+	case m.keymap.Matches(config.ActionSend, key) && (m.scratch.Mode() == vimtextarea.Normal || m.scratch.Plain()),
+		m.keymap.Matches(config.ActionSendInsert, key) && (m.scratch.Mode() == vimtextarea.Insert || m.scratch.Mode() == vimtextarea.Visual):
+		return m.stageScratch()
+	}
+
+	var cmd tea.Cmd
+	m.scratch, cmd = m.scratch.Update(msg)
+	if height := m.inputHeight(m.scratch.Value()); height != m.scratch.Height() {
+		m.scratch.SetHeight(height)
+	}
+	return m, cmd
+}
+
+// stageScratch appends the scratch buffer to the input and returns to it,
+// leaving the notes in place
+func (m Model) stageScratch() (tea.Model, tea.Cmd) {
+	text := strings.TrimSpace(m.scratch.Value())
+	if text == "" {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "The scratch buffer is empty",
+				Duration: 3 * time.Second,
+			}
+		}
+	}
+
+	m = m.closeScratch()
+	if value := m.textarea.Value(); value != "" {
+		text = value + "\n" + text
+	}
+	m.textarea.SetValue(text)
+	m = m.fitTextareaHeight()
+	m.textarea.InsertAtEnd()
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Copied the scratch buffer to the input",
+			Duration: 3 * time.Second,
+		}
+	}
+}
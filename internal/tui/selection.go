@@ -0,0 +1,185 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/config"
+	"reapo/internal/tui/components"
+)
+
+// Message selection lets the user pick an earlier chat message with the
+// keyboard and quote it into the input, pin it, or edit one of their own
+// messages and regenerate the conversation from that point
+
+// isQuotable reports whether a message can be selected for quoting
+func isQuotable(msg components.Message) bool {
+	return (msg.Type == components.MessageTypeText || msg.Type == "") && msg.Content != ""
+}
+
+// quotableMessage returns the nearest quotable message index from start in
+// direction step (-1 for older, 1 for newer), or -1 if there is none
+func (m Model) quotableMessage(start, step int) int {
+	for i := start; i >= 0 && i < len(m.messages); i += step {
+		if isQuotable(m.messages[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// startMessageSelection focuses the most recent quotable message
+func (m Model) startMessageSelection() (tea.Model, tea.Cmd) {
+	index := m.quotableMessage(len(m.messages)-1, -1)
+	if index < 0 {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "No messages to select",
+				Duration: 3 * time.Second,
+			}
+		}
+	}
+
+	m.focusedMessage = index
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Select a message: j/k to move, Enter or y to quote, e to edit and regenerate, p to pin, Esc to cancel",
+			Duration: 0, // Cleared when selection ends
+		}
+	}
+}
+
+// handleMessageSelection moves the focus or quotes the focused message
+func (m Model) handleMessageSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if m.keymap.Matches(config.ActionSelectMessage, key) {
+		// Pressing the selection key again keeps moving up
+		key = "k"
+	}
+
+	switch key {
+	case "k", "up":
+		if index := m.quotableMessage(m.focusedMessage-1, -1); index >= 0 {
+			m.focusedMessage = index
+		}
+	case "j", "down":
+		if index := m.quotableMessage(m.focusedMessage+1, 1); index >= 0 {
+			m.focusedMessage = index
+		}
+	case "g", "home":
+		m.focusedMessage = m.quotableMessage(0, 1)
+	case "G", "end":
+		m.focusedMessage = m.quotableMessage(len(m.messages)-1, -1)
+	case "enter", "y":
+		m = m.quoteMessage(m.messages[m.focusedMessage].Content)
+		m = m.endMessageSelection()
+	case "e":
+		return m.editMessage()
+	case "p":
+		return m.togglePin()
+	case "esc":
+		m = m.endMessageSelection()
+	}
+	return m, nil
+}
+
+// endMessageSelection leaves selection mode
+func (m Model) endMessageSelection() Model {
+	m.focusedMessage = -1
+	if m.statusline != nil {
+		m.statusline.ClearMessage()
+	}
+	return m
+}
+
+// quoteMessage appends content to the input as a Markdown quote and leaves
+// the cursor on a new line after it, ready for a reply
+func (m Model) quoteMessage(content string) Model {
+	var quote strings.Builder
+	if value := m.textarea.Value(); value != "" {
+		quote.WriteString(value + "\n")
+	}
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		quote.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+	}
+	quote.WriteString("\n")
+
+	m.textarea.SetValue(quote.String())
+	m = m.fitTextareaHeight()
+	m.textarea.InsertAtEnd()
+	return m
+}
+
+// editMessage loads the focused user message into the input. Sending it
+// drops that message and everything after it, then regenerates from there.
+func (m Model) editMessage() (tea.Model, tea.Cmd) {
+	target := m.messages[m.focusedMessage]
+	if target.Role != "user" {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "Only your own messages can be edited; pick one with j/k",
+				Duration: 3 * time.Second,
+			}
+		}
+	}
+
+	m = m.endMessageSelection()
+	m.editingMessageID = target.ID
+	m.textarea.SetValue(target.Content)
+	m = m.fitTextareaHeight()
+	m.textarea.InsertAtEnd()
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Editing an earlier message: send to regenerate from there, or send an empty input to cancel",
+			Duration: 0, // Cleared when the edit is sent or cancelled
+		}
+	}
+}
+
+// truncateForEdit drops the message being edited and everything after it, so
+// the edited text is sent in its place. It reports whether the message was found.
+func (m Model) truncateForEdit() (Model, bool) {
+	id := m.editingMessageID
+	m.editingMessageID = ""
+	for i, msg := range m.messages {
+		if msg.ID == id {
+			m.messages = m.messages[:i]
+			m.contextTokens = m.countConversationTokens()
+			return m, true
+		}
+	}
+	return m, false
+}
+
+// togglePin pins or unpins the focused message. Pinned messages are kept
+// verbatim when the conversation is compacted or cleared.
+func (m Model) togglePin() (tea.Model, tea.Cmd) {
+	m.messages[m.focusedMessage].Pinned = !m.messages[m.focusedMessage].Pinned
+	text := "Message pinned: /compact and /clear will keep it"
+	if !m.messages[m.focusedMessage].Pinned {
+		text = "Message unpinned"
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     text,
+			Duration: 3 * time.Second,
+		}
+	}
+}
+
+// pinnedMessages returns the pinned messages of the conversation, in order
+func (m Model) pinnedMessages() []components.Message {
+	pinned := []components.Message{}
+	for _, msg := range m.messages {
+		if msg.Pinned {
+			pinned = append(pinned, msg)
+		}
+	}
+	return pinned
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/logger"
+)
+
+// Conversations are archived as sessions when reapo exits cleanly and when
+// /new starts a fresh one. Unlike the recovery file, sessions are kept: each
+// project has a directory of them in the data directory, named by a hash of
+// the project's path like its recovery file. /resume lists the current
+// project's sessions, and /resume all those of every project.
+
+// sessionsDirName is the directory in the data directory holding a
+// directory of sessions per project
+const sessionsDirName = "sessions"
+
+// savedSession is an archived conversation, stored like a recovery file
+type savedSession struct {
+	ID string // Name of the session's file, the running conversation's sessionID
+	recovery
+}
+
+// SessionFoundMsg carries the latest session of this project, restored at
+// startup by --continue when there is nothing to recover
+type SessionFoundMsg struct {
+	Session savedSession
+}
+
+// sessionPath returns the location of the session file with id for the
+// current working directory
+func sessionPath(id string) (string, error) {
+	dir, err := projectFile(sessionsDirName, "")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
+
+// archiveSession saves the conversation as this session, replacing what was
+// saved for it before. Conversations with nothing sent to the model aren't
+// archived. Like autosave, the messages are encoded on the update loop and
+// the file is written by the returned command.
+func (m Model) archiveSession() tea.Cmd {
+	sent := false
+	for _, msg := range m.messages {
+		sent = sent || sentInHistory(msg)
+	}
+	if !sent {
+		return nil
+	}
+
+	path, err := sessionPath(m.sessionID)
+	if err != nil {
+		return nil
+	}
+	data, err := json.Marshal(recovery{
+		SavedAt:    time.Now(),
+		WorkingDir: m.workingDir(),
+		Messages:   m.messages,
+	})
+	if err != nil {
+		logger.Error("Failed to encode conversation for its session: %v", err)
+		return nil
+	}
+
+	return func() tea.Msg {
+		if err := writeFileAtomic(path, data); err != nil {
+			logger.Error("Failed to save session: %v", err)
+		}
+		return nil
+	}
+}
+
+// savedSessions returns the sessions saved for this project, or for every
+// project with all, newest first, leaving out the session with id exclude
+func savedSessions(all bool, exclude string) []savedSession {
+	dir, err := projectFile(sessionsDirName, "")
+	if err != nil {
+		return nil
+	}
+	if all {
+		dir = filepath.Join(filepath.Dir(dir), "*")
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+
+	var saved []savedSession
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), ".json")
+		if id == exclude {
+			continue
+		}
+		if rec := readRecovery(path); rec != nil {
+			saved = append(saved, savedSession{ID: id, recovery: *rec})
+		}
+	}
+	sort.Slice(saved, func(i, j int) bool { return saved[i].SavedAt.After(saved[j].SavedAt) })
+	return saved
+}
+
+// loadLatestSession finds this project's latest session, for --continue
+func loadLatestSession() tea.Msg {
+	saved := savedSessions(false, "")
+	if len(saved) == 0 {
+		return nil
+	}
+	return SessionFoundMsg{Session: saved[0]}
+}
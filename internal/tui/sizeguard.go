@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/config"
+	"reapo/internal/logger"
+	"reapo/internal/references"
+	"reapo/internal/tui/components"
+)
+
+// truncationNote marks input that was cut down to fit the context window
+const truncationNote = "\n\n[message truncated to fit the context window]"
+
+// estimateMessageTokens estimates the tokens a message will add to the
+// conversation: the typed text and the files it @-references. Command output
+// is not counted since running the commands here would run them twice.
+func (m Model) estimateMessageTokens(text string) (typed, referenced int) {
+	typed = countTokens(text)
+	for _, ref := range references.Parse(text) {
+		referenced += int(ref.Size(m.workingDir()) / 4)
+	}
+	return typed, referenced
+}
+
+// availableTokens returns how many tokens a new message may use, leaving
+// room for the response
+func (m Model) availableTokens() int {
+	return m.maxContextTokens - m.contextTokens - int(config.Get().MaxTokens)
+}
+
+// warnOversize asks the user whether to send, truncate or cancel a message
+// that is estimated to exceed the context window
+func (m Model) warnOversize(typed, referenced int) (tea.Model, tea.Cmd) {
+	m.pendingOversize = true
+
+	text := fmt.Sprintf("Message is ~%d tokens but only ~%d fit in the context. Enter: send anyway • t: truncate • Esc: cancel",
+		typed+referenced, max(m.availableTokens(), 0))
+	if referenced > 0 {
+		text = fmt.Sprintf("Message is ~%d tokens (~%d from @references) but only ~%d fit in the context. Enter: send anyway • t: truncate • Esc: cancel",
+			typed+referenced, referenced, max(m.availableTokens(), 0))
+	}
+
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineWarning,
+			Text:     text,
+			Duration: 0, // Cleared once the user decides
+		}
+	}
+}
+
+// handleOversizeChoice acts on the user's answer to warnOversize
+func (m Model) handleOversizeChoice(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "y":
+		m = m.endOversizeChoice()
+		m.confirmOversize = true
+		return m.submitInput()
+	case "t":
+		m = m.endOversizeChoice()
+		return m.truncateInput()
+	case "esc", "n":
+		m = m.endOversizeChoice()
+	}
+	return m, nil
+}
+
+// endOversizeChoice leaves the oversize prompt, keeping the input for editing
+func (m Model) endOversizeChoice() Model {
+	m.pendingOversize = false
+	if m.statusline != nil {
+		m.statusline.ClearMessage()
+	}
+	return m
+}
+
+// truncateInput cuts the typed text down to what fits alongside its
+// @references and puts it back in the input for review before sending
+func (m Model) truncateInput() (tea.Model, tea.Cmd) {
+	value := m.textarea.Value()
+	_, referenced := m.estimateMessageTokens(value)
+
+	budget := m.availableTokens() - referenced - countTokens(truncationNote)
+	if budget <= 0 {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineError,
+				Text:     "The @referenced files alone exceed the context; remove some references or /compact first",
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+
+	// Inverse of countTokens' 4 characters per token, backed up to a rune boundary
+	cut := min(len(value), budget*4)
+	for cut > 0 && cut < len(value) && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	m.textarea.SetValue(value[:cut] + truncationNote)
+	m = m.fitTextareaHeight()
+	m.textarea.InsertAtEnd()
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Message truncated to fit; review it and send again",
+			Duration: 4 * time.Second,
+		}
+	}
+}
+
+// sentInHistory reports whether buildConversationHistory sends a message to the model
+func sentInHistory(msg components.Message) bool {
+	if msg.Content == "" || msg.Nested {
+		return false
+	}
+	return msg.Role == "user" || (msg.Role == "assistant" && !msg.IsError && msg.Status == components.MessageCompleted && strings.TrimSpace(msg.Content) != "")
+}
+
+// trimToBudget leaves the oldest unpinned messages out of a request whose
+// estimated size exceeds context_budget_tokens. The newest message is always
+// kept, and the history doesn't start with a dropped exchange's reply.
+func (m Model) trimToBudget(messages []components.Message) []components.Message {
+	budget := config.Get().ContextBudgetTokens
+	if budget <= 0 {
+		return messages
+	}
+
+	total := countTokens(m.systemPrompt)
+	for _, msg := range messages {
+		if sentInHistory(msg) {
+			total += countTokens(msg.Content)
+		}
+	}
+	if total <= budget {
+		return messages
+	}
+
+	kept := make([]components.Message, 0, len(messages))
+	dropped, droppedTokens := 0, 0
+	trimming := true
+	for i, msg := range messages {
+		if trimming && i < len(messages)-1 && !msg.Pinned && sentInHistory(msg) {
+			// Keep dropping replies to dropped messages so the history starts with the user
+			if total > budget || msg.Role == "assistant" {
+				total -= countTokens(msg.Content)
+				dropped++
+				droppedTokens += countTokens(msg.Content)
+				continue
+			}
+			trimming = false
+		}
+		kept = append(kept, msg)
+	}
+
+	if dropped > 0 {
+		logger.Info("Context budget of %d tokens exceeded: left out the %d oldest messages (~%d tokens), ~%d tokens remain",
+			budget, dropped, droppedTokens, total)
+	}
+	return kept
+}
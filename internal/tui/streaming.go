@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/tui/components"
+)
+
+// StreamDeltaMsg carries a piece of response text as it streams in
+type StreamDeltaMsg struct {
+	MessageID string
+	Text      string
+	stream    <-chan tea.Msg // Source of the next message in the stream
+}
+
+// streamInference runs an inference request in the background, delivering a
+// StreamDeltaMsg for each piece of text as it arrives and then the message
+// returned by run. Nothing starts until the returned command runs, so it can
+// wait for confirm_requests' go-ahead.
+func streamInference(agentMessageID string, run func(onText func(text string)) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		stream := make(chan tea.Msg, 64)
+		go func() {
+			defer close(stream)
+			result := run(func(text string) {
+				stream <- StreamDeltaMsg{MessageID: agentMessageID, Text: text, stream: stream}
+			})
+			stream <- result
+		}()
+		return waitForStream(stream)()
+	}
+}
+
+// waitForStream returns the next message from a stream started by streamInference
+func waitForStream(stream <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-stream
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// appendStreamedText adds streamed text to the agent message, creating it on
+// the first token that isn't whitespace, so a response that is only a tool
+// call doesn't leave a blank bubble. The processing spinner is dropped at
+// that point since the text itself now shows progress.
+func (m Model) appendStreamedText(msg StreamDeltaMsg) Model {
+	for i := range m.messages {
+		if m.messages[i].ID == msg.MessageID {
+			m.messages[i].Content += msg.Text
+			m.messages[i].Status = components.MessageStreaming
+			m.messages[i].UpdatedAt = time.Now()
+			return m
+		}
+	}
+	if strings.TrimSpace(msg.Text) == "" {
+		return m
+	}
+
+	m.processingText = ""
+	m.processingSpinner = nil
+	m.messages = append(m.messages, components.Message{
+		ID:        msg.MessageID,
+		Role:      "assistant",
+		Content:   msg.Text,
+		Type:      components.MessageTypeText,
+		Status:    components.MessageStreaming,
+		Timestamp: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	return m
+}
+
+// finishStreamedText completes text streamed before a tool call under its own
+// ID, so the turn's final answer is added after the tool output instead of
+// replacing it
+func (m Model) finishStreamedText(agentMessageID string) Model {
+	for i := range m.messages {
+		if m.messages[i].ID == agentMessageID {
+			m.messages[i].ID = generateMessageID()
+			m.messages[i].Status = components.MessageCompleted
+			break
+		}
+	}
+	return m
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/agent"
+	"reapo/internal/tui/components"
+)
+
+// While run_task works, its sub-agent's tool calls and the text it writes
+// between them are shown in the chat, indented under the task.
+
+// SubtaskEventMsg reports a tool call or text from the run_task sub-agent
+type SubtaskEventMsg struct {
+	AgentMessageID string
+	Event          string // "start", "complete", "error" or "text", as for agent.ToolCallback
+	ToolName       string
+	ToolID         string
+	Data           string
+	stream         <-chan tea.Msg // Source of the next message from the tool batch
+}
+
+// streamSubtasks runs a tool batch in the background, delivering a
+// SubtaskEventMsg for each event reported by a sub-agent and then the
+// message returned by run. Like streamInference, nothing starts until the
+// returned command runs.
+func streamSubtasks(agentMessageID string, run func(report agent.ToolCallback) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		stream := make(chan tea.Msg, 64)
+		go func() {
+			defer close(stream)
+			result := run(func(event, toolName, toolID, data string) {
+				stream <- SubtaskEventMsg{
+					AgentMessageID: agentMessageID,
+					Event:          event,
+					ToolName:       toolName,
+					ToolID:         toolID,
+					Data:           data,
+					stream:         stream,
+				}
+			})
+			stream <- result
+		}()
+		return waitForStream(stream)()
+	}
+}
+
+// addSubtaskEvent shows a sub-agent event in the chat
+func (m Model) addSubtaskEvent(msg SubtaskEventMsg) Model {
+	nested := components.Message{
+		ID:        generateMessageID(),
+		Role:      "assistant",
+		Type:      components.MessageTypeText,
+		Status:    components.MessageCompleted,
+		Timestamp: time.Now(),
+		UpdatedAt: time.Now(),
+		Nested:    true,
+	}
+
+	switch msg.Event {
+	case "start":
+		nested.Content = fmt.Sprintf("%s(%s)", msg.ToolName, formatToolArguments(msg.ToolName, json.RawMessage(msg.Data)))
+		m.processingText = fmt.Sprintf("Task agent running %s...", msg.ToolName)
+	case "text":
+		nested.Content = msg.Data
+	case "complete", "error":
+		result := ToolResultMsg{ToolName: msg.ToolName, ToolID: msg.ToolID, MessageID: nested.ID}
+		if msg.Event == "error" {
+			result.Error = msg.Data
+		} else {
+			var data struct {
+				Output   string `json:"output"`
+				Duration string `json:"duration"`
+			}
+			if err := json.Unmarshal([]byte(msg.Data), &data); err == nil {
+				result.Output = data.Output
+				result.Duration = data.Duration
+				if duration, err := time.ParseDuration(data.Duration); err == nil {
+					result.Duration = duration.Round(time.Millisecond).String()
+				}
+			}
+		}
+		m = m.addToolResultMessage(result)
+		m.messages[len(m.messages)-1].Nested = true
+		return m
+	default:
+		return m
+	}
+
+	m.messages = append(m.messages, nested)
+	return m
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/tui/components"
+)
+
+// runningTool is a tool execution that can still be aborted
+type runningTool struct {
+	id     string
+	name   string
+	cancel context.CancelFunc
+}
+
+// runningTools tracks executing tools in start order. It lives outside Model
+// because tools run in goroutines while Model is copied on every update.
+var runningTools struct {
+	sync.Mutex
+	tools []runningTool
+}
+
+// startTool registers a tool as running and returns a context derived from
+// parent to run it under, along with a function to call once it has finished
+func startTool(parent context.Context, id, name string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	runningTools.Lock()
+	runningTools.tools = append(runningTools.tools, runningTool{id: id, name: name, cancel: cancel})
+	runningTools.Unlock()
+
+	return ctx, func() {
+		runningTools.Lock()
+		for i, tool := range runningTools.tools {
+			if tool.id == id {
+				runningTools.tools = append(runningTools.tools[:i], runningTools.tools[i+1:]...)
+				break
+			}
+		}
+		runningTools.Unlock()
+		cancel()
+	}
+}
+
+// abortRunningTool cancels the longest-running tool. It returns the aborted
+// tool's name, how many tools are still running, and whether one was aborted.
+func abortRunningTool() (string, int, bool) {
+	runningTools.Lock()
+	defer runningTools.Unlock()
+
+	if len(runningTools.tools) == 0 {
+		return "", 0, false
+	}
+
+	tool := runningTools.tools[0]
+	runningTools.tools = runningTools.tools[1:]
+	tool.cancel()
+	return tool.name, len(runningTools.tools), true
+}
+
+// abortTool handles the abort-tool key by cancelling the running tool; the
+// model is told the tool was cancelled and continues with the other results
+func (m Model) abortTool() (Model, tea.Cmd) {
+	name, remaining, ok := abortRunningTool()
+	if !ok {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     "No tool is running",
+				Duration: 2 * time.Second,
+			}
+		}
+	}
+
+	text := fmt.Sprintf("Aborted %s", name)
+	if remaining > 0 {
+		text += fmt.Sprintf(" (%d still running, press again to abort the next)", remaining)
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineWarning,
+			Text:     text,
+			Duration: 3 * time.Second,
+		}
+	}
+}
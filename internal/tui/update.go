@@ -7,7 +7,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,7 +15,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"reapo/internal/agent"
 	"reapo/internal/auth"
+	"reapo/internal/config"
 	"reapo/internal/logger"
+	"reapo/internal/references"
+	"reapo/internal/tui/completion"
 	"reapo/internal/tui/components"
 	"reapo/internal/tui/components/vimtextarea"
 )
@@ -23,19 +26,22 @@ import (
 //go:embed summary_prompt.txt
 var summaryPrompt string
 
+// clearUndoWindow is how long Ctrl+Z can restore a conversation after /clear
+const clearUndoWindow = 10 * time.Second
+
 // Update handles messages and updates the model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
 	// Handle auth modal updates first
-	if m.authModal.Active() {
+	if m.authModal.IsVisible() {
 		m.authModal, cmd = m.authModal.Update(msg)
 		if cmd != nil {
 			return m, cmd
 		}
 		// If modal is still active, don't process other messages
-		if m.authModal.Active() {
+		if m.authModal.IsVisible() {
 			return m, nil
 		}
 	}
@@ -44,7 +50,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.viewport.width = msg.Width
 		m.viewport.height = msg.Height
-		m.textarea.SetWidth(msg.Width - 6) // Account for border padding + prefix
+		m.textarea.SetWidth(max(msg.Width-6, 1)) // Account for border padding + prefix
+		m.scratch.SetWidth(max(msg.Width-6, 1))
 		m.ready = true
 		// Update statusline width
 		if m.statusline != nil {
@@ -52,54 +59,178 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Update auth modal size
 		m.authModal, cmd = m.authModal.Update(msg)
+		// Update help modal size
+		if m.helpModal != nil {
+			helpModal, _ := m.helpModal.Update(msg)
+			m.helpModal = &helpModal
+		}
 		// Update status modal size
 		if m.statusModal != nil {
 			statusModal, _ := m.statusModal.Update(msg)
 			m.statusModal = &statusModal
 		}
+		// Update config modal size
+		if m.configModal != nil {
+			configModal, _ := m.configModal.Update(msg)
+			m.configModal = &configModal
+		}
+		// Update debug modal size
+		if m.debugModal != nil {
+			debugModal, _ := m.debugModal.Update(msg)
+			m.debugModal = &debugModal
+		}
+		// Update command palette size
+		if m.commandPalette != nil {
+			commandPalette, _ := m.commandPalette.Update(msg)
+			m.commandPalette = &commandPalette
+		}
+		// Update onboarding modal size
+		if m.onboardingModal != nil {
+			onboardingModal, _ := m.onboardingModal.Update(msg)
+			m.onboardingModal = &onboardingModal
+		}
+		// Update fatal error modal size
+		if m.fatalModal != nil {
+			fatalModal, _ := m.fatalModal.Update(msg)
+			m.fatalModal = &fatalModal
+		}
 		return m, cmd
 
 	case tea.KeyMsg:
-		// Handle status modal key events first
+		// A request error that needs the user's attention comes before everything else
+		if m.fatalModal.IsVisible() && !m.keymap.Matches(config.ActionQuit, msg.String()) {
+			fatalModal, cmd := m.fatalModal.Update(msg)
+			m.fatalModal = &fatalModal
+			return m, cmd
+		}
+
+		// Handle help modal key events
+		if m.helpModal.IsVisible() {
+			helpModal, cmd := m.helpModal.Update(msg)
+			m.helpModal = &helpModal
+			return m, cmd
+		}
+
+		// Handle status modal key events
 		if m.statusModal.IsVisible() {
 			statusModal, cmd := m.statusModal.Update(msg)
 			m.statusModal = &statusModal
 			return m, cmd
 		}
+
+		// Handle config modal key events
+		if m.configModal.IsVisible() {
+			configModal, cmd := m.configModal.Update(msg)
+			m.configModal = &configModal
+			return m, cmd
+		}
+
+		// Handle debug modal key events
+		if m.debugModal.IsVisible() {
+			debugModal, cmd := m.debugModal.Update(msg)
+			m.debugModal = &debugModal
+			return m, cmd
+		}
+
+		// Handle command palette key events
+		if m.commandPalette.IsVisible() {
+			commandPalette, cmd := m.commandPalette.Update(msg)
+			m.commandPalette = &commandPalette
+			return m, cmd
+		}
+
+		// Handle onboarding modal key events
+		if m.onboardingModal.IsVisible() && !m.keymap.Matches(config.ActionQuit, msg.String()) {
+			onboardingModal, cmd := m.onboardingModal.Update(msg)
+			m.onboardingModal = &onboardingModal
+			return m, cmd
+		}
 		
+		// Handle the restore/discard choice for a recovered conversation
+		if m.pendingRecovery != nil && !m.keymap.Matches(config.ActionQuit, msg.String()) {
+			return m.handleRecoveryChoice(msg)
+		}
+
+		// Handle the go-ahead for an API request with confirm_requests set
+		if m.pendingRequest != nil && !m.keymap.Matches(config.ActionQuit, msg.String()) {
+			return m.handleRequestChoice(msg)
+		}
+
+		// Handle the send-anyway/truncate/cancel choice for an oversized message
+		if m.pendingOversize && !m.keymap.Matches(config.ActionQuit, msg.String()) {
+			return m.handleOversizeChoice(msg)
+		}
+
+		// Handle search keys while stepping through /find matches
+		if m.findQuery != "" && !m.keymap.Matches(config.ActionQuit, msg.String()) {
+			return m.handleFind(msg)
+		}
+
+		// Handle message selection keys while selecting a message to quote
+		if m.focusedMessage >= 0 && !m.keymap.Matches(config.ActionQuit, msg.String()) {
+			return m.handleMessageSelection(msg)
+		}
+
+		// The scratch buffer takes every key while it's open
+		if m.scratchOpen && !m.keymap.Matches(config.ActionQuit, msg.String()) {
+			return m.handleScratch(msg)
+		}
+
+		// Handle scrolling keys while the chat has the focus
+		if m.chatFocused && !m.keymap.Matches(config.ActionQuit, msg.String()) {
+			return m.handleChatFocus(msg)
+		}
+
 		// Handle key events before passing to textarea
 		switch {
-		case msg.String() == "ctrl+c":
+		case m.keymap.Matches(config.ActionQuit, msg.String()):
 			return m, tea.Quit
-		case msg.String() == "esc" && m.helpModal.IsVisible():
-			// Hide help modal on Esc
-			m.helpModal.Hide()
+		case m.keymap.Matches(config.ActionSelectMessage, msg.String()) && !m.textarea.CompletionState().Active:
+			return m.startMessageSelection()
+		case m.keymap.Matches(config.ActionCommandPalette, msg.String()) && !m.textarea.CompletionState().Active:
+			// Open the command palette
+			m.commandPalette.Show(paletteItems(), m.viewport.width, m.viewport.height)
 			return m, nil
-		case msg.String() == "enter" && m.textarea.Mode() == vimtextarea.Normal:
+		case m.keymap.Matches(config.ActionSwitchModel, msg.String()):
+			return m.switchToPreviousModel()
+		case m.keymap.Matches(config.ActionAbortTool, msg.String()):
+			return m.abortTool()
+		case m.keymap.Matches(config.ActionFind, msg.String()) && !m.textarea.CompletionState().Active:
+			return m.openFind()
+		case m.keymap.Matches(config.ActionCycleFocus, msg.String()) && m.textarea.Mode() == vimtextarea.Normal && !m.textarea.Plain() && !m.textarea.CompletionState().Active:
+			// Tab still inserts or completes in Insert mode and plain input
+			return m.focusChat()
+		case m.keymap.Matches(config.ActionToggleScratch, msg.String()) && !m.textarea.CompletionState().Active:
+			return m.toggleScratch()
+		case m.keymap.Matches(config.ActionToggleVerbose, msg.String()):
+			return m.toggleVerbose()
+		case m.keymap.Matches(config.ActionRedraw, msg.String()):
+			// Wipe artifacts left by background output or resizes; the next render repaints everything
+			return m, tea.ClearScreen
+		case m.keymap.Matches(config.ActionUndoClear, msg.String()) && m.clearedMessages != nil && time.Since(m.clearedAt) < clearUndoWindow:
+			// Restore the conversation removed by /clear
+			restored := len(m.clearedMessages)
+			m.messages = m.clearedMessages
+			m.clearedMessages = nil
+			m.contextTokens = m.countConversationTokens()
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineInfo,
+					Text:     fmt.Sprintf("Restored %d messages", restored),
+					Duration: 3 * time.Second,
+				}
+			}
+		case m.keymap.Matches(config.ActionSend, msg.String()) && (m.textarea.Mode() == vimtextarea.Normal || m.textarea.Plain()):
 			// Don't send message if completion is active
 			if m.textarea.CompletionState().Active {
 				// Let textarea handle completion selection
 				break
 			}
-			// Enter sends message in Normal mode
-			if m.textarea.Value() != "" && !m.processing {
-				userMessage := m.textarea.Value()
-				m.textarea.SetValue("")
-				
-				m.processing = true
-				return m, m.processMessage(userMessage)
-			}
-			return m, nil
-		case msg.String() == "ctrl+s" && (m.textarea.Mode() == vimtextarea.Insert || m.textarea.Mode() == vimtextarea.Visual):
+			// Enter sends message in Normal mode, or always in plain mode
+			return m.submitInput()
+		case m.keymap.Matches(config.ActionSendInsert, msg.String()) && (m.textarea.Mode() == vimtextarea.Insert || m.textarea.Mode() == vimtextarea.Visual):
 			// Ctrl+S sends message in Insert and Visual modes
-			if m.textarea.Value() != "" && !m.processing {
-				userMessage := m.textarea.Value()
-				m.textarea.SetValue("")
-				
-				m.processing = true
-				return m, m.processMessage(userMessage)
-			}
-			return m, nil
+			return m.submitInput()
 		}
 
 	case AddMessageMsg:
@@ -120,22 +251,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case MessageUpdateMsg:
+		if m.interrupted(msg.MessageID) {
+			return m, nil
+		}
+		if msg.MessageID == m.activeTurn && (msg.Status == components.MessageCompleted || msg.Status == components.MessageError) {
+			m.activeTurn = ""
+		}
+
 		// Check if this is the final agent response (no existing message to update)
 		messageExists := false
 		for i, message := range m.messages {
 			if message.ID == msg.MessageID {
 				messageExists = true
-				m.messages[i].Content = msg.Content
+				if msg.Append {
+					m.messages[i].Content += msg.Content
+				} else {
+					m.messages[i].Content = msg.Content
+				}
 				m.messages[i].Status = msg.Status
 				m.messages[i].Progress = msg.Progress
 				m.messages[i].ToolInfo = msg.ToolInfo
+				m.messages[i].Truncated = msg.Truncated
 				m.messages[i].UpdatedAt = time.Now()
+
+				// A completed reply with no text would be a blank bubble
+				if blankReply(m.messages[i]) {
+					m.messages = append(m.messages[:i], m.messages[i+1:]...)
+				}
 				break
 			}
 		}
 
 		// If no message exists, this is the final agent response - add it
-		if !messageExists && msg.Content != "" {
+		if !messageExists && strings.TrimSpace(msg.Content) != "" {
 			agentMsg := components.Message{
 				ID:        msg.MessageID,
 				Role:      "assistant",
@@ -143,6 +291,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Type:      components.MessageTypeText,
 				Status:    msg.Status,
 				IsError:   msg.Status == components.MessageError,
+				Truncated: msg.Truncated,
 				Timestamp: time.Now(),
 				UpdatedAt: time.Now(),
 			}
@@ -156,11 +305,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.processingSpinner = nil
 			// Update context tokens when message is complete
 			m.contextTokens = m.countConversationTokens()
-			
+
+			// Surface connectivity changes separately from API errors
+			var networkCmd tea.Cmd
+			m, networkCmd = m.checkNetworkState()
+			m = m.showFatalProblem(msg.Err)
+
+			// Say so when a turn ends with nothing to show for it
+			if msg.Status == components.MessageCompleted && len(m.messages) > 0 && m.messages[len(m.messages)-1].Role == "user" {
+				networkCmd = tea.Batch(networkCmd, func() tea.Msg {
+					return ShowStatuslineMsg{
+						Type:     components.StatuslineWarning,
+						Text:     "The model returned an empty response",
+						Duration: 4 * time.Second,
+					}
+				})
+			}
+
 			// Check if we need auto-compaction
 			if cmd := m.checkAutoCompaction(); cmd != nil {
-				return m, cmd
+				return m, tea.Batch(networkCmd, cmd)
+			}
+
+			// Check the edits made during the turn with the verify command
+			if msg.Status == components.MessageCompleted {
+				var verifyCmd tea.Cmd
+				m, verifyCmd = m.startVerify()
+				return m, tea.Batch(networkCmd, verifyCmd)
 			}
+			m.turnEdited = false
+			return m, networkCmd
 		}
 
 		return m, nil
@@ -185,30 +359,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case ToolResultMsg:
-		// Add tool result message
-		toolMsg := components.Message{
-			ID:        msg.MessageID,
-			Role:      "assistant",
-			Content:   "",
-			Type:      components.MessageTypeToolResult,
-			Status:    components.MessageCompleted,
-			Timestamp: time.Now(),
-			UpdatedAt: time.Now(),
-			ToolInfo: &components.ToolInfo{
-				Name:       msg.ToolName,
-				Output:     msg.Output,
-				Error:      msg.Error,
-				Duration:   msg.Duration,
-				ShowOutput: components.ShouldShowToolOutput(msg.ToolName),
-			},
+		m = m.addToolResultMessage(msg)
+		return m, nil
+
+	case GitStatusMsg:
+		m.gitBranch = msg.Branch
+		m.gitDirty = msg.Dirty
+		return m, scheduleGitStatus()
+
+	case ToolsExecutedMsg:
+		if m.interrupted(msg.AgentMessageID) {
+			return m, nil
 		}
-		if msg.Error != "" {
-			toolMsg.Status = components.MessageError
+		m = m.noteEdits(msg.Results)
+
+		// Add the results, then continue the turn. Results of tools that don't
+		// surface output are only shown in verbose mode or if the tool failed.
+		for _, result := range msg.Results {
+			m = m.addToolResultMessage(result)
 		}
-		m.messages = append(m.messages, toolMsg)
-		return m, nil
+		// Show the spinner again until the follow-up response starts streaming
+		m.processingText = "Processing tool results..."
+		m.processingSpinner = components.NewSpinnerComponent("")
+		m, cmd := m.confirmRequest("tool results", m.requestTokens(msg.Conversation),
+			m.respondAfterTools(msg.Conversation, msg.AgentMessageID), declineTurn)
+		return m, tea.Batch(m.startAnimation(), cmd)
 
 	case ProcessMessageSequenceMsg:
+		// A new message ends the chance to undo /clear
+		m.clearedMessages = nil
+
 		// Add user message with original content for TUI display
 		userMsg := components.Message{
 			ID:        msg.UserMessageID,
@@ -231,13 +411,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Set processing state instead of adding a message
 		m.processing = true
+		m.activeTurn = msg.AgentMessageID
 		m.processingText = "Processing your request..."
 		m.processingSpinner = components.NewSpinnerComponent("")
 
-		return m, tea.Batch(
-			m.startAnimation(),
-			m.processAgentRequestWithID(msg.UserMessage, msg.AgentMessageID),
-		)
+		_, referenced := m.estimateMessageTokens(msg.UserMessage)
+		m, cmd := m.confirmRequest("message", m.requestTokens(m.buildConversationHistory())+referenced,
+			m.processAgentRequestWithID(msg.UserMessage, msg.AgentMessageID, msg.NoTools), declineTurn)
+		return m, tea.Batch(m.startAnimation(), cmd)
 
 	case AnimationTickMsg:
 		// Update all active spinners
@@ -277,27 +458,230 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.contextTokens = m.countConversationTokens()
 		return m, nil
 
+	case StreamDeltaMsg:
+		// Keep draining an interrupted stream so its goroutine can finish
+		if !m.interrupted(msg.MessageID) {
+			m = m.appendStreamedText(msg)
+		}
+		return m, waitForStream(msg.stream)
+
+	case SubtaskEventMsg:
+		// Keep draining an interrupted batch so its goroutine can finish
+		if !m.interrupted(msg.AgentMessageID) {
+			m = m.addSubtaskEvent(msg)
+		}
+		return m, waitForStream(msg.stream)
+
 	case ProcessToolsMsg:
+		if m.interrupted(msg.AgentMessageID) {
+			return m, nil
+		}
 		// Handle tool processing by returning the batch command
+		m = m.finishStreamedText(msg.AgentMessageID)
+		for _, toolUse := range extractToolUses(msg.Response) {
+			if toolUse.Name == "run_task" {
+				return m.confirmRequest("run_task", -1, m.processToolUse(msg.Conversation, msg.Response, msg.AgentMessageID), declineTurn)
+			}
+		}
 		return m, m.processToolUse(msg.Conversation, msg.Response, msg.AgentMessageID)
 
+	case components.CommandPaletteSelectMsg:
+		// Run the chosen palette entry
+		if strings.HasPrefix(msg.Item.Text, "/") {
+			return m, func() tea.Msg {
+				return vimtextarea.SlashCommandMsg{Command: msg.Item.Text}
+			}
+		}
+		switch msg.Item.Text {
+		case paletteSwitchModel:
+			return m.switchToPreviousModel()
+		case paletteOpenEditor:
+			return m, m.openExternalEditor()
+		}
+		return m, nil
+
+	case vimtextarea.ClipboardPasteMsg:
+		if msg.Err != nil {
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineError,
+					Text:     fmt.Sprintf("Error: Failed to read clipboard: %v", msg.Err),
+					Duration: 4 * time.Second,
+				}
+			}
+		}
+		// The paste goes to the scratch buffer while it has the input's place
+		if m.scratchOpen {
+			m.scratch, cmd = m.scratch.Update(msg)
+			if height := m.inputHeight(m.scratch.Value()); height != m.scratch.Height() {
+				m.scratch.SetHeight(height)
+			}
+			return m, cmd
+		}
+		m.textarea, cmd = m.textarea.Update(msg)
+		m = m.fitTextareaHeight()
+		return m, cmd
+
+	case components.FatalExitMsg:
+		return m.quitOnFatal()
+
+	case components.OnboardingLoginMsg:
+		// Start the same flow as /login
+		return m, func() tea.Msg {
+			return vimtextarea.SlashCommandMsg{Command: "/login"}
+		}
+
 	case vimtextarea.SlashCommandMsg:
-		// Handle slash commands
-		switch msg.Command {
+		// Handle slash commands, splitting off any arguments and resolving aliases
+		command, args, _ := strings.Cut(strings.TrimSpace(msg.Command), " ")
+		command = completion.ResolveCommand(command)
+		args = strings.TrimSpace(args)
+		switch command {
 		case "/help":
 			// Show help modal
-			m.helpModal.Show()
+			m.helpModal.Show(m.viewport.width, m.viewport.height)
 			return m, nil
 		case "/status":
 			// Show status modal
 			authStatus := auth.GetAuthStatus()
-			m.statusModal.Show(authStatus, m.viewport.width, m.viewport.height)
+			m.statusModal.Show(authStatus, m.currentModel, effortStatus(config.Get()), projectStatus(config.Get()), m.viewport.width, m.viewport.height)
 			return m, nil
 		case "/clear":
-			// Clear conversation history
-			m.messages = []components.Message{}
-			m.contextTokens = 0
+			if len(m.messages) == 0 {
+				return m, nil
+			}
+			// Clear conversation history except pinned messages, keeping it
+			// briefly so it can be restored
+			m.clearedMessages = m.messages
+			m.clearedAt = time.Now()
+			m.messages = m.pinnedMessages()
+			m.contextTokens = m.countConversationTokens()
+			text := fmt.Sprintf("Cleared %d messages (Ctrl+Z to undo)", len(m.clearedMessages))
+			if len(m.messages) > 0 {
+				text = fmt.Sprintf("Cleared %d messages, kept %d pinned (Ctrl+Z to undo)", len(m.clearedMessages)-len(m.messages), len(m.messages))
+			}
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineInfo,
+					Text:     text,
+					Duration: clearUndoWindow,
+				}
+			}
+		case "/new":
+			// Start a fresh conversation, keeping auth and config. The old
+			// one is archived first so /resume can bring it back.
+			archive := m.archiveSession()
+			m = m.resetConversation()
+			return m, tea.Batch(archive, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineInfo,
+					Text:     "Started a new conversation",
+					Duration: 3 * time.Second,
+				}
+			})
+		case "/config":
+			return m.handleConfigCommand(args)
+		case "/effort":
+			return m.handleEffortCommand(args)
+		case "/model":
+			return m.handleModelCommand(args)
+		case "/continue":
+			return m.continueResponse()
+		case "/todos":
+			// Toggle the todo panel
+			m.showTodos = !m.showTodos
+			return m, nil
+		case "/verbose":
+			return m.toggleVerbose()
+		case "/scratch":
+			return m.toggleScratch()
+		case "/debug":
+			// Show recent API request/response summaries
+			m.debugModal.Show(agent.RecentExchanges(), m.viewport.width, m.viewport.height)
 			return m, nil
+		case "/dryrun":
+			// Toggle intercepting tool calls instead of executing them
+			agent.SetDryRun(!agent.DryRun())
+			text := "Dry run disabled: tools will be executed"
+			if agent.DryRun() {
+				text = "Dry run enabled: tool calls will be shown but not executed"
+			}
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineInfo,
+					Text:     text,
+					Duration: 4 * time.Second,
+				}
+			}
+		case "/plain":
+			// Toggle between vim modes and a plain Enter-to-send input
+			plain := !m.textarea.Plain()
+			if err := config.Set("plain_input", strconv.FormatBool(plain)); err != nil {
+				logger.Error("Failed to set plain_input: %v", err)
+			}
+			setInputMode(&m.textarea, plain)
+			m.scratch.SetPlain(plain)
+			text := "Plain input disabled: vim modes restored"
+			if plain {
+				text = "Plain input enabled: Enter sends, Alt+Enter inserts a newline"
+			}
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineInfo,
+					Text:     text,
+					Duration: 4 * time.Second,
+				}
+			}
+		case "/confirm":
+			// Toggle asking before each API request
+			confirm := !config.Get().ConfirmRequests
+			if err := config.Set("confirm_requests", strconv.FormatBool(confirm)); err != nil {
+				logger.Error("Failed to set confirm_requests: %v", err)
+			}
+			text := "Requests are sent without asking"
+			if confirm {
+				text = "Each API request now waits for your go-ahead, showing its estimated tokens and cost"
+			}
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineInfo,
+					Text:     text,
+					Duration: 4 * time.Second,
+				}
+			}
+		case "/hints":
+			// Toggle the key hint bar below the input
+			hints := !config.Get().KeyHints
+			if err := config.Set("key_hints", strconv.FormatBool(hints)); err != nil {
+				logger.Error("Failed to set key_hints: %v", err)
+			}
+			text := "Key hints off"
+			if config.Get().KeyHints {
+				text = "Key hints on"
+			}
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineInfo,
+					Text:     text,
+					Duration: 3 * time.Second,
+				}
+			}
+		case "/save-prompt":
+			return m.savePromptTemplate(args)
+		case "/load-prompt":
+			return m.loadPromptTemplate(args)
+		case "/persona":
+			return m.switchPersona(args)
+		case "/find":
+			return m.startFind(args)
+		case "/export-actions":
+			return m.exportActions(args)
+		case "/resume":
+			return m.resumeConversation(args)
+		case "/registers":
+			return m.showRegisters(args)
+		case "/ask":
+			return m.askQuestion(args)
 		case "/editor":
 			// Open external editor
 			return m, m.openExternalEditor()
@@ -306,10 +690,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.processing = true
 			m.processingText = "Compacting conversation..."
 			m.processingSpinner = components.NewSpinnerComponent("")
-			return m, tea.Batch(
-				m.startAnimation(),
-				m.compactConversation(false), // false = manual compaction
-			)
+			m, cmd := m.confirmRequest("compaction", m.requestTokens(m.fullConversationHistory()),
+				m.compactConversation(false), declineTask) // false = manual compaction
+			return m, tea.Batch(m.startAnimation(), cmd)
+		case "/summary":
+			// Summarize the conversation, keeping it intact
+			m.processing = true
+			m.processingText = "Summarizing conversation..."
+			m.processingSpinner = components.NewSpinnerComponent("")
+			m, cmd := m.confirmRequest("summary", m.requestTokens(m.fullConversationHistory()),
+				m.showSummary(), declineTask)
+			return m, tea.Batch(m.startAnimation(), cmd)
 		case "/login":
 			// Start login flow
 			cmds = append(cmds, func() tea.Msg {
@@ -332,6 +723,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 			cmds = append(cmds, m.startLogoutFlow())
 			return m, tea.Batch(cmds...)
+		case "/quit":
+			return m, tea.Quit
+		default:
+			// Give feedback for mistyped commands instead of ignoring them
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineWarning,
+					Text:     fmt.Sprintf("Unknown command: %s (try /help)", command),
+					Duration: 4 * time.Second,
+				}
+			}
 		}
 
 	case EditorFinishedMsg:
@@ -383,10 +785,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Clear conversation history
-		m.messages = []components.Message{}
+		// Remember the size of the conversation being replaced
+		compactedMessages := len(m.messages)
+		tokensBefore := m.countConversationTokens()
+
+		// Clear conversation history, keeping pinned messages verbatim
+		m.messages = m.pinnedMessages()
 
-		// Add summary as first user message
+		// Add summary as the first unpinned user message
 		summaryMsg := components.Message{
 			ID:        generateMessageID(),
 			Role:      "user",
@@ -414,28 +820,81 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.messages = append(m.messages, systemMsg)
 
-		// Reset token count to just the summary
-		m.contextTokens = countTokens(systemPromptContent) + countTokens(msg.Summary)
+		// Reset token count to the summary and pinned messages
+		m.contextTokens = m.countConversationTokens()
 
 		// Clear processing state
 		m.processing = false
 		m.processingText = ""
 		m.processingSpinner = nil
 
-		// Show success statusline for auto-compaction
+		// Summarize how much the conversation shrank
+		text := fmt.Sprintf("Compacted %d messages: ~%d → ~%d tokens", compactedMessages, tokensBefore, m.contextTokens)
 		if msg.IsAuto {
 			percentage := float64(m.contextTokens) / float64(m.maxContextTokens) * 100
+			text = fmt.Sprintf("Auto-compaction complete. %s (%.1f%% of context)", text, percentage)
+		}
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     text,
+				Duration: 6 * time.Second,
+			}
+		}
+		
+	case VerifyResultMsg:
+		return m.handleVerifyResult(msg)
+
+	case AutosaveMsg:
+		return m, tea.Batch(m.autosave(), scheduleAutosave())
+
+	case ScratchLoadedMsg:
+		if m.scratch.Value() != "" {
+			// Notes were taken before the saved ones loaded
+			return m, nil
+		}
+		m.scratch.SetValue(msg.Text)
+		if height := m.inputHeight(msg.Text); height != m.scratch.Height() {
+			m.scratch.SetHeight(height)
+		}
+		return m, nil
+
+	case RecoveryFoundMsg:
+		return m.offerRecovery(msg.Recovery)
+
+	case SessionFoundMsg:
+		if len(m.messages) > 0 {
+			return m, nil
+		}
+		return m.restoreConversation(msg.Session)
+
+	case SummaryMsg:
+		m.processing = false
+		m.processingText = ""
+		m.processingSpinner = nil
+
+		if msg.Error != nil {
 			return m, func() tea.Msg {
 				return ShowStatuslineMsg{
-					Type:     components.StatuslineInfo,
-					Text:     fmt.Sprintf("Auto-compaction complete. Context reduced to %.1f%%", percentage),
-					Duration: 4 * time.Second,
+					Type:     components.StatuslineError,
+					Text:     fmt.Sprintf("Error summarizing conversation: %s", msg.Error.Error()),
+					Duration: 6 * time.Second,
 				}
 			}
 		}
 
+		// Shown as a system message so it isn't sent back to the model
+		m.messages = append(m.messages, components.Message{
+			ID:        generateMessageID(),
+			Role:      "system",
+			Content:   "Conversation summary:\n\n" + msg.Summary,
+			Type:      components.MessageTypeText,
+			Status:    components.MessageCompleted,
+			Timestamp: time.Now(),
+			UpdatedAt: time.Now(),
+		})
 		return m, nil
-		
+
 	case SetProcessingMsg:
 		// Update processing state
 		m.processing = msg.Active
@@ -444,10 +903,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.processingSpinner = components.NewSpinnerComponent("")
 			// Check if this is for auto-compaction
 			if msg.Text == "Auto-compacting to save context..." {
-				return m, tea.Batch(
-					m.startAnimation(),
-					m.compactConversation(true), // true = auto
-				)
+				m, cmd := m.confirmRequest("compaction", m.requestTokens(m.fullConversationHistory()),
+					m.compactConversation(true), declineTask) // true = auto
+				return m, tea.Batch(m.startAnimation(), cmd)
 			}
 			return m, m.startAnimation()
 		} else {
@@ -543,15 +1001,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.authModal.Hide()
 		
 		if msg.Success {
-			// Reinitialize client
-			newClient, err := auth.NewClient()
-			if err != nil {
-				logger.Debug("Failed to reinitialize client: %v", err)
-			} else {
-				m.client = newClient
-				m.agent = agent.NewAgent(&m.client, nil, m.toolDefs, systemPromptContent)
-			}
-			// Show success in statusline
+			// The client looks the token up for each request, so it needs
+			// no reinitializing. Show success in statusline
 			return m, func() tea.Msg {
 				return ShowStatuslineMsg{
 					Type:     components.StatuslineInfo,
@@ -593,79 +1044,531 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	
 	}
 
+	mode, value := m.textarea.Mode(), m.textarea.Value()
 	m.textarea, cmd = m.textarea.Update(msg)
+	m = m.fitTextareaHeight()
+	cmds = append(cmds, cmd)
 
-	// Dynamically adjust textarea height based on content
-	lines := strings.Count(m.textarea.Value(), "\n") + 1
-
-	maxHeight := min(max((m.viewport.height)/2, 1), 12) // Between 1-12 lines
-	height := min(max(lines, 1), maxHeight)
-
-	if height != m.textarea.Height() {
-		m.textarea.SetHeight(height)
+	// Starting to type a new message can interrupt the response in flight
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m, cmd = m.interruptOnType(mode, value)
+		cmds = append(cmds, cmd)
 	}
-
-	cmds = append(cmds, cmd)
 	return m, tea.Batch(cmds...)
 }
 
-// buildConversationHistory converts TUI messages to Claude conversation format
-func (m Model) buildConversationHistory() []anthropic.MessageParam {
-	var conversation []anthropic.MessageParam
-	for _, msg := range m.messages {
-		if msg.Role == "user" && msg.Content != "" {
-			conversation = append(conversation, anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)))
-		} else if msg.Role == "assistant" && !msg.IsError && msg.Content != "" && msg.Status == components.MessageCompleted {
-			conversation = append(conversation, anthropic.NewAssistantMessage(anthropic.NewTextBlock(msg.Content)))
-		}
-		// Skip error messages, empty messages, and processing messages from conversation history
+// fitTextareaHeight dynamically adjusts the textarea height based on content
+func (m Model) fitTextareaHeight() Model {
+	if height := m.inputHeight(m.textarea.Value()); height != m.textarea.Height() {
+		m.textarea.SetHeight(height)
 	}
-	return conversation
+	return m
 }
 
-// processMessage sends a message to the agent with conversation history using new message system
-func (m Model) processMessage(message string) tea.Cmd {
-	// Generate IDs ahead of time
-	userMessageID := generateMessageID()
-	agentMessageID := generateMessageID()
+// inputHeight returns the height of an input holding value: a line for each
+// of its lines, up to 12 and half the screen
+func (m Model) inputHeight(value string) int {
+	lines := strings.Count(value, "\n") + 1
 
-	return func() tea.Msg {
-		return ProcessMessageSequenceMsg{
-			UserMessage:    message,
-			UserMessageID:  userMessageID,
-			AgentMessageID: agentMessageID,
-		}
-	}
+	maxHeight := min(max((m.viewport.height)/2, 1), 12) // Between 1-12 lines
+	return min(max(lines, 1), maxHeight)
 }
 
-// processAgentRequestWithID handles the actual agent processing with progress updates
-func (m Model) processAgentRequestWithID(originalMessage string, agentMessageID string) tea.Cmd {
-	// First, return a batch command that includes file reference messages
-	fileRefMessages, fileRefCmds, err := m.executeFileReferences(originalMessage)
-	if err != nil {
-		return func() tea.Msg {
-			return MessageUpdateMsg{
-				MessageID: agentMessageID,
-				Content:   fmt.Sprintf("Error processing file references: %s", err.Error()),
-				Status:    components.MessageError,
-				Progress:  nil,
+// submitInput sends the textarea contents to the agent, or dispatches it
+// as a slash command when it is a single line starting with "/"
+func (m Model) submitInput() (tea.Model, tea.Cmd) {
+	value := m.textarea.Value()
+	if value == "" {
+		if m.editingMessageID != "" {
+			// Sending nothing abandons an edit of an earlier message
+			m.editingMessageID = ""
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineInfo,
+					Text:     "Edit cancelled",
+					Duration: 2 * time.Second,
+				}
 			}
 		}
+		return m, nil
 	}
 
-	// If we have file reference commands, batch them with the main processing
-	if len(fileRefCmds) > 0 {
-		cmds := fileRefCmds
-		cmds = append(cmds, m.processAgentRequestCore(originalMessage, agentMessageID, fileRefMessages))
-		return tea.Batch(cmds...)
+	if strings.HasPrefix(value, "/") && !strings.Contains(value, "\n") {
+		m.textarea.SetValue("")
+		return m, func() tea.Msg {
+			return vimtextarea.SlashCommandMsg{Command: value}
+		}
 	}
 
-	// No file references, just do the main processing
-	return m.processAgentRequestCore(originalMessage, agentMessageID, fileRefMessages)
-}
+	if m.processing {
+		return m, nil
+	}
 
-func (m Model) processAgentRequestCore(originalMessage string, agentMessageID string, fileRefMessages []anthropic.MessageParam) tea.Cmd {
+	// Warn before sending a message that can't fit in the context window
+	if !m.confirmOversize {
+		if typed, referenced := m.estimateMessageTokens(value); typed+referenced > m.availableTokens() {
+			return m.warnOversize(typed, referenced)
+		}
+	}
+	m.confirmOversize = false
+
+	// Without auth the request would fail; keep the input and explain how to log in
+	if !auth.IsAuthenticated() {
+		m.onboardingModal.Show(m.viewport.width, m.viewport.height)
+		return m, nil
+	}
+
+	// Sending an edited message regenerates the conversation from that point
+	if m.editingMessageID != "" {
+		var found bool
+		if m, found = m.truncateForEdit(); found && m.statusline != nil {
+			m.statusline.ClearMessage()
+		}
+	}
+
+	m.textarea.SetValue("")
+	m.processing = true
+	m.verifyAttempts = 0
+	return m, m.processMessage(value)
+}
+
+// checkNetworkState updates the offline flag from the agent and returns a
+// statusline command when connectivity was lost or restored
+func (m Model) checkNetworkState() (Model, tea.Cmd) {
+	offline := agent.Offline()
+	if offline == m.offline {
+		return m, nil
+	}
+	m.offline = offline
+
+	if offline {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineError,
+				Text:     "Offline: network unreachable. Your next message will retry the connection",
+				Duration: 0, // Stays until connectivity returns
+			}
+		}
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     "Back online",
+			Duration: 3 * time.Second,
+		}
+	}
+}
+
+// setInputMode switches the textarea between vim modes and plain input,
+// updating the placeholder to describe how to send
+func setInputMode(textarea *vimtextarea.Model, plain bool) {
+	textarea.SetPlain(plain)
+	if plain {
+		textarea.SetPlaceholder("Type a message... (Enter to send, Alt+Enter for newline)")
+	} else {
+		textarea.SetPlaceholder("Type a message... (Enter in Normal, Ctrl+S in Insert/Visual)")
+	}
+}
+
+// handleConfigCommand shows the effective configuration, sets a runtime
+// option ("/config <key> <value>"), or persists it ("/config save")
+func (m Model) handleConfigCommand(args string) (tea.Model, tea.Cmd) {
+	if args == "" {
+		m.configModal.Show(config.Entries(), config.Path(), m.viewport.width, m.viewport.height)
+		return m, nil
+	}
+
+	if args == "save" {
+		if err := config.Save(); err != nil {
+			return m, func() tea.Msg {
+				return ShowStatuslineMsg{
+					Type:     components.StatuslineError,
+					Text:     fmt.Sprintf("Error: Failed to save config: %v", err),
+					Duration: 6 * time.Second,
+				}
+			}
+		}
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     fmt.Sprintf("Config saved to %s", config.Path()),
+				Duration: 4 * time.Second,
+			}
+		}
+	}
+
+	key, value, _ := strings.Cut(args, " ")
+	if err := config.Set(key, strings.TrimSpace(value)); err != nil {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineError,
+				Text:     fmt.Sprintf("Error: %v", err),
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+
+	// Keep the footer and input in sync with the new settings
+	cfg := config.Get()
+	if cfg.Model != m.currentModel {
+		m.previousModel = m.currentModel
+		m.currentModel = cfg.Model
+	}
+	m.textarea.SetTabWidth(cfg.TabWidth)
+	m.textarea.SetExpandTab(cfg.ExpandTab)
+	m.textarea.SetAutoIndent(cfg.AutoIndent)
+	setInputMode(&m.textarea, cfg.PlainInput)
+	m.scratch.SetTabWidth(cfg.TabWidth)
+	m.scratch.SetExpandTab(cfg.ExpandTab)
+	m.scratch.SetAutoIndent(cfg.AutoIndent)
+	m.scratch.SetPlain(cfg.PlainInput)
+
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     fmt.Sprintf("Set %s = %s (use /config save to persist)", key, strings.TrimSpace(value)),
+			Duration: 4 * time.Second,
+		}
+	}
+}
+
+// Command palette actions that are not slash commands
+const (
+	paletteSwitchModel = "Switch to previous model"
+	paletteOpenEditor  = "Open external editor"
+)
+
+// paletteItems returns everything the command palette can run
+func paletteItems() []completion.CompletionItem {
+	return append(completion.SlashCommands(),
+		completion.CompletionItem{Text: paletteSwitchModel, Description: "Toggle to the previously used model (F2)"},
+		completion.CompletionItem{Text: paletteOpenEditor, Description: "Edit in $EDITOR"},
+	)
+}
+
+// switchToPreviousModel toggles back to the model used before the last switch
+func (m Model) switchToPreviousModel() (tea.Model, tea.Cmd) {
+	if m.previousModel == "" {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "No previous model to switch to (use /model <name>)",
+				Duration: 3 * time.Second,
+			}
+		}
+	}
+	return m.handleModelCommand(m.previousModel)
+}
+
+// handleModelCommand shows the active model, or switches to the named one
+func (m Model) handleModelCommand(name string) (tea.Model, tea.Cmd) {
+	if name == "" {
+		text := fmt.Sprintf("Model: %s", m.currentModel)
+		if m.previousModel != "" {
+			text += fmt.Sprintf(" (F2 switches to %s)", m.previousModel)
+		}
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     text,
+				Duration: 4 * time.Second,
+			}
+		}
+	}
+
+	if err := config.Set("model", name); err != nil {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineError,
+				Text:     fmt.Sprintf("Error: %v", err),
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+
+	if name != m.currentModel {
+		m.previousModel = m.currentModel
+		m.currentModel = name
+	}
+
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     fmt.Sprintf("Switched to %s", name),
+			Duration: 3 * time.Second,
+		}
+	}
+}
+
+// continueResponse asks the model to keep going after a response that was
+// cut off at the max_tokens limit, appending the continuation to the same message
+func (m Model) continueResponse() (tea.Model, tea.Cmd) {
+	if m.processing {
+		return m, nil
+	}
+
+	index := -1
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Role == "assistant" && m.messages[i].Type == components.MessageTypeText {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 || !m.messages[index].Truncated {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "Nothing to continue: the last response was not truncated",
+				Duration: 4 * time.Second,
+			}
+		}
+	}
+
+	// The partial response is already in the history; ask the model to resume it
+	conversation := m.buildConversationHistory()
+	conversation = append(conversation, anthropic.NewUserMessage(anthropic.NewTextBlock(
+		"Your previous response was cut off. Continue exactly where you left off, without repeating anything.",
+	)))
+
+	m.messages[index].Truncated = false
+	m.processing = true
+	m.processingText = "Continuing response..."
+	m.processingSpinner = components.NewSpinnerComponent("")
+
+	messageID := m.messages[index].ID
+	m, cmd := m.confirmRequest("continuation", m.requestTokens(conversation),
+		m.continueAgentRequest(conversation, messageID),
+		func(m Model) (Model, tea.Cmd) {
+			// Keep the response truncated so /continue can be tried again
+			for i := range m.messages {
+				if m.messages[i].ID == messageID {
+					m.messages[i].Truncated = true
+				}
+			}
+			return declineTask(m)
+		})
+	return m, tea.Batch(m.startAnimation(), cmd)
+}
+
+// continueAgentRequest runs inference for /continue and appends the resulting text
+// to the truncated message
+func (m Model) continueAgentRequest(conversation []anthropic.MessageParam, agentMessageID string) tea.Cmd {
+	var partial string
+	for _, msg := range m.messages {
+		if msg.ID == agentMessageID {
+			partial = msg.Content
+		}
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(agent.WithRequestID(context.Background(), agentMessageID), requestTimeout())
+		defer cancel()
+
+		response, err := m.agent.RunInference(ctx, conversation)
+		if err != nil {
+			var errMsg string
+			if ctx.Err() == context.DeadlineExceeded {
+				errMsg = fmt.Sprintf("Request timed out after %s", requestTimeout())
+			} else {
+				errMsg = fmt.Sprintf("Error: %s", err.Error())
+			}
+			// Keep the partial response as-is so /continue can be retried
+			return MessageUpdateMsg{
+				MessageID: agentMessageID,
+				Status:    components.MessageCompleted,
+				Progress:  &components.Progress{Description: "Failed to continue: " + errMsg},
+				Truncated: true,
+				Append:    true,
+				Err:       err,
+			}
+		}
+
+		// If the continuation calls tools, its final answer becomes a new message
+		// after the tool output rather than being appended above it
+		for _, content := range response.Content {
+			if content.Type == "tool_use" {
+				return ProcessToolsMsg{
+					Conversation:   conversation,
+					Response:       response,
+					AgentMessageID: generateMessageID(),
+				}
+			}
+		}
+
+		updateMsg := finalResponseMsg(agentMessageID, response)
+		updateMsg.Append = true
+		// Whether a code block is still open depends on the whole response
+		updateMsg.Truncated = response.StopReason == anthropic.StopReasonMaxTokens ||
+			components.EndsInCodeBlock(partial+updateMsg.Content)
+		return updateMsg
+	}
+}
+
+// addToolResultMessage appends a tool result message to the chat
+func (m Model) addToolResultMessage(msg ToolResultMsg) Model {
+	toolMsg := components.Message{
+		ID:        msg.MessageID,
+		Role:      "assistant",
+		Content:   "",
+		Type:      components.MessageTypeToolResult,
+		Status:    components.MessageCompleted,
+		Timestamp: time.Now(),
+		UpdatedAt: time.Now(),
+		ToolInfo: &components.ToolInfo{
+			Name:          msg.ToolName,
+			Output:        msg.Output,
+			Error:         msg.Error,
+			ErrorCategory: msg.ErrorCategory,
+			Duration:      msg.Duration,
+			ShowOutput:    components.ShouldShowToolOutput(msg.ToolName),
+		},
+	}
+	if msg.Error != "" {
+		toolMsg.Status = components.MessageError
+	}
+	m.messages = append(m.messages, toolMsg)
+	return m
+}
+
+// resetConversation returns the model to a fresh-conversation state.
+// Unlike /clear, it also resets token accounting and any in-flight
+// processing/spinner state. Auth, client, and config are preserved. The
+// conversation gets a new session ID so archiving it doesn't replace the
+// previous one.
+func (m Model) resetConversation() Model {
+	m.messages = []components.Message{}
+	m.sessionID = generateMessageID()
+	m.editingMessageID = ""
+	m.findQuery = ""
+	m.contextTokens = countTokens(m.systemPrompt)
+	m.spinners = make(map[string]*components.SpinnerComponent)
+	m.processing = false
+	m.processingText = ""
+	m.processingSpinner = nil
+	m.agent = agent.NewAgent(&m.client, nil, m.toolDefs, m.systemPrompt)
+	if m.statusline != nil {
+		m.statusline.ClearMessage()
+	}
+	return m
+}
+
+// buildConversationHistory converts TUI messages to Claude conversation format
+// for a turn, leaving out what doesn't fit in context_budget_tokens
+func (m Model) buildConversationHistory() []anthropic.MessageParam {
+	return conversationHistory(m.trimToBudget(m.messages))
+}
+
+// fullConversationHistory is buildConversationHistory without the budget, for
+// summaries that must cover the whole conversation
+func (m Model) fullConversationHistory() []anthropic.MessageParam {
+	return conversationHistory(m.messages)
+}
+
+// conversationHistory converts messages to Claude conversation format
+func conversationHistory(messages []components.Message) []anthropic.MessageParam {
+	var conversation []anthropic.MessageParam
+	for _, msg := range messages {
+		// Sub-agent activity is only shown; the model sees the task's result
+		if msg.Nested {
+			continue
+		}
+		if msg.Role == "user" && msg.Content != "" {
+			conversation = append(conversation, anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)))
+		} else if msg.Role == "assistant" && !msg.IsError && strings.TrimSpace(msg.Content) != "" && msg.Status == components.MessageCompleted {
+			conversation = append(conversation, anthropic.NewAssistantMessage(anthropic.NewTextBlock(msg.Content)))
+		}
+		// Skip error messages, empty messages, and processing messages from conversation history
+	}
+	return conversation
+}
+
+// processMessage sends a message to the agent with conversation history using new message system
+func (m Model) processMessage(message string) tea.Cmd {
+	// Generate IDs ahead of time
+	userMessageID := generateMessageID()
+	agentMessageID := generateMessageID()
+
+	return func() tea.Msg {
+		return ProcessMessageSequenceMsg{
+			UserMessage:    message,
+			UserMessageID:  userMessageID,
+			AgentMessageID: agentMessageID,
+		}
+	}
+}
+
+// askQuestion sends a message that the model answers without using tools
+func (m Model) askQuestion(question string) (tea.Model, tea.Cmd) {
+	if question == "" {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineWarning,
+				Text:     "Usage: /ask <question>",
+				Duration: 3 * time.Second,
+			}
+		}
+	}
+	if m.processing {
+		return m, nil
+	}
+	if !auth.IsAuthenticated() {
+		m.onboardingModal.Show(m.viewport.width, m.viewport.height)
+		return m, nil
+	}
+
+	m.processing = true
+	m.verifyAttempts = 0
+	userMessageID := generateMessageID()
+	agentMessageID := generateMessageID()
+	return m, func() tea.Msg {
+		return ProcessMessageSequenceMsg{
+			UserMessage:    question,
+			UserMessageID:  userMessageID,
+			AgentMessageID: agentMessageID,
+			NoTools:        true,
+		}
+	}
+}
+
+// processAgentRequestWithID handles the actual agent processing with progress updates.
+// References are only read, and @!commands run, once the returned command
+// runs, so a request declined under confirm_requests leaves them untouched.
+func (m Model) processAgentRequestWithID(originalMessage string, agentMessageID string, noTools bool) tea.Cmd {
 	return func() tea.Msg {
+		// Each user message starts a new turn with fresh tool results
+		m.agent.ResetToolCache()
+
+		// Reference tools share the request deadline, and interrupting the
+		// turn stops any @!command still running
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout())
+		defer cancel()
+		ctx, done := startTurnRequest(ctx, agentMessageID)
+		defer done()
+
+		// First, return a batch command that includes file reference messages
+		fileRefMessages, fileRefCmds, err := m.executeFileReferences(ctx, originalMessage)
+		if ctx.Err() == context.Canceled {
+			// The turn was interrupted, so it's dropped
+			return nil
+		}
+		if err != nil {
+			return MessageUpdateMsg{
+				MessageID: agentMessageID,
+				Content:   fmt.Sprintf("Error processing file references: %s", err.Error()),
+				Status:    components.MessageError,
+				Progress:  nil,
+			}
+		}
+
+		// Batch any file reference messages with the main processing
+		cmds := append(fileRefCmds, m.processAgentRequestCore(originalMessage, agentMessageID, fileRefMessages, noTools))
+		return tea.BatchMsg(cmds)
+	}
+}
+
+func (m Model) processAgentRequestCore(originalMessage string, agentMessageID string, fileRefMessages []anthropic.MessageParam, noTools bool) tea.Cmd {
+	return streamInference(agentMessageID, func(onText func(text string)) tea.Msg {
 
 		// Update progress helper function (available for future use)
 		_ = func(description string) MessageUpdateMsg {
@@ -684,16 +1587,22 @@ func (m Model) processAgentRequestCore(originalMessage string, agentMessageID st
 		// Add simulated tool call cycle if any @references were found
 		conversation = append(conversation, fileRefMessages...)
 
-		// Create context with timeout and cancellation
-		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		// Create context with timeout and cancellation, tagged with the turn's
+		// message ID so the chat log can match responses to their requests
+		ctx, cancel := context.WithTimeout(agent.WithRequestID(context.Background(), agentMessageID), requestTimeout())
 		defer cancel()
+		ctx, done := startTurnRequest(ctx, agentMessageID)
+		defer done()
+		if noTools {
+			ctx = agent.WithoutTools(ctx)
+		}
 
 		// Use the persistent agent with conversation history
-		response, err := m.agent.RunInference(ctx, conversation)
+		response, err := m.agent.RunInferenceStream(ctx, conversation, onText)
 		if err != nil {
 			var errMsg string
 			if ctx.Err() == context.DeadlineExceeded {
-				errMsg = "Request timed out after 60 seconds"
+				errMsg = fmt.Sprintf("Request timed out after %s", requestTimeout())
 			} else if ctx.Err() == context.Canceled {
 				errMsg = "Request was cancelled"
 			} else {
@@ -704,6 +1613,7 @@ func (m Model) processAgentRequestCore(originalMessage string, agentMessageID st
 				Content:   errMsg,
 				Status:    components.MessageError,
 				Progress:  nil,
+				Err:       err,
 			}
 		}
 
@@ -723,21 +1633,8 @@ func (m Model) processAgentRequestCore(originalMessage string, agentMessageID st
 			}
 		}
 
-		// Extract text content from response
-		var responseText strings.Builder
-		for _, content := range response.Content {
-			if content.Type == "text" {
-				responseText.WriteString(content.Text)
-			}
-		}
-
-		return MessageUpdateMsg{
-			MessageID: agentMessageID,
-			Content:   responseText.String(),
-			Status:    components.MessageCompleted,
-			Progress:  nil,
-		}
-	}
+		return finalResponseMsg(agentMessageID, response)
+	})
 }
 
 // processToolUse handles tool execution and continues the conversation
@@ -780,48 +1677,94 @@ func (m Model) processToolUse(conversation []anthropic.MessageParam, response *a
 	return tea.Batch(cmds...)
 }
 
-// executeToolsAndRespond executes tools concurrently and updates the agent message with the final response
+// executeToolsAndRespond executes tools concurrently and reports their results back to the model
 func (m Model) executeToolsAndRespond(conversation []anthropic.MessageParam, toolUses []agent.ToolUseInfo, agentMessageID string) tea.Cmd {
-	return func() tea.Msg {
+	return streamSubtasks(agentMessageID, func(report agent.ToolCallback) tea.Msg {
 		// Execute tools concurrently
 		type toolResult struct {
-			index  int
-			result anthropic.ContentBlockParamUnion
+			index    int
+			result   anthropic.ContentBlockParamUnion
+			err      error
+			duration time.Duration
 		}
 
 		resultChan := make(chan toolResult, len(toolUses))
 
+		// Tools run under the request deadline so a slow tool can't stall the turn
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout())
+		defer cancel()
+		ctx, done := startTurnRequest(ctx, agentMessageID)
+		defer done()
+
+		// A run_task sub-agent reports its progress to the chat
+		ctx = agent.WithToolCallback(ctx, report)
+
 		// Launch concurrent tool executions
 		for i, toolUse := range toolUses {
 			go func(index int, tu agent.ToolUseInfo) {
-				result := m.agent.ExecuteTool(tu.ID, tu.Name, tu.Input)
+				ctx, done := startTool(ctx, tu.ID, tu.Name)
+				defer done()
+				startTime := time.Now()
+				result, err := m.agent.ExecuteTool(ctx, tu.ID, tu.Name, tu.Input)
 				resultChan <- toolResult{
-					index:  index,
-					result: result,
+					index:    index,
+					result:   result,
+					err:      err,
+					duration: time.Since(startTime),
 				}
 			}(i, toolUse)
 		}
 
 		// Collect results in order
 		toolResults := make([]anthropic.ContentBlockParamUnion, len(toolUses))
+		displayResults := make([]ToolResultMsg, len(toolUses))
 		for i := 0; i < len(toolUses); i++ {
 			res := <-resultChan
 			toolResults[res.index] = res.result
+
+			toolUse := toolUses[res.index]
+			output, isError := toolResultText(res.result)
+			displayResult := ToolResultMsg{
+				ToolName:  toolUse.Name,
+				ToolID:    toolUse.ID,
+				Duration:  res.duration.Round(time.Millisecond).String(),
+				MessageID: generateMessageID(),
+			}
+			if isError {
+				displayResult.Error = output
+				displayResult.ErrorCategory = agent.ErrorCategory(res.err)
+			} else {
+				displayResult.Output = output
+			}
+			displayResults[res.index] = displayResult
 		}
 
 		// Add tool results to conversation
 		conversation = append(conversation, anthropic.NewUserMessage(toolResults...))
 
+		return ToolsExecutedMsg{
+			Conversation:   conversation,
+			Results:        displayResults,
+			AgentMessageID: agentMessageID,
+		}
+	})
+}
+
+// respondAfterTools sends the tool results back to the model and handles its follow-up response
+func (m Model) respondAfterTools(conversation []anthropic.MessageParam, agentMessageID string) tea.Cmd {
+	return streamInference(agentMessageID, func(onText func(text string)) tea.Msg {
 		// Create context with timeout for follow-up response
-		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		ctx, cancel := context.WithTimeout(agent.WithRequestID(context.Background(), agentMessageID), requestTimeout())
 		defer cancel()
+		ctx, done := startTurnRequest(ctx, agentMessageID)
+		defer done()
 
 		// Get follow-up response after tool execution
-		followUpResponse, err := m.agent.RunInference(ctx, conversation)
+		followUpResponse, err := m.agent.RunInferenceStream(ctx, conversation, onText)
 		if err != nil {
 			var errMsg string
 			if ctx.Err() == context.DeadlineExceeded {
-				errMsg = "Follow-up request timed out after 60 seconds"
+				errMsg = fmt.Sprintf("Follow-up request timed out after %s", requestTimeout())
 			} else if ctx.Err() == context.Canceled {
 				errMsg = "Follow-up request was cancelled"
 			} else {
@@ -832,6 +1775,7 @@ func (m Model) executeToolsAndRespond(conversation []anthropic.MessageParam, too
 				Content:   errMsg,
 				Status:    components.MessageError,
 				Progress:  nil,
+				Err:       err,
 			}
 		}
 
@@ -847,22 +1791,52 @@ func (m Model) executeToolsAndRespond(conversation []anthropic.MessageParam, too
 			}
 		}
 
-		// Extract text from follow-up response
-		var responseText strings.Builder
-		for _, content := range followUpResponse.Content {
-			if content.Type == "text" {
-				responseText.WriteString(content.Text)
-			}
+		// Update the agent message with the final response
+		return finalResponseMsg(agentMessageID, followUpResponse)
+	})
+}
+
+// blankReply reports whether msg is a completed assistant reply with only
+// whitespace, which is neither shown nor sent back to the model
+func blankReply(msg components.Message) bool {
+	return msg.Role == "assistant" && msg.Type == components.MessageTypeText && msg.Status == components.MessageCompleted &&
+		msg.Progress == nil && strings.TrimSpace(msg.Content) == ""
+}
+
+// finalResponseMsg builds the completed agent message from the model's final response
+func finalResponseMsg(agentMessageID string, response *anthropic.Message) MessageUpdateMsg {
+	// Extract text content from response
+	var responseText strings.Builder
+	for _, content := range response.Content {
+		if content.Type == "text" {
+			responseText.WriteString(content.Text)
 		}
+	}
 
-		// Update the agent message with the final response
-		return MessageUpdateMsg{
-			MessageID: agentMessageID,
-			Content:   responseText.String(),
-			Status:    components.MessageCompleted,
-			Progress:  nil,
+	return MessageUpdateMsg{
+		MessageID: agentMessageID,
+		Content:   responseText.String(),
+		Status:    components.MessageCompleted,
+		Progress:  nil,
+		// A response ending in an open code block was cut off mid-code even
+		// if the API didn't report hitting max_tokens
+		Truncated: response.StopReason == anthropic.StopReasonMaxTokens || components.EndsInCodeBlock(responseText.String()),
+	}
+}
+
+// toolResultText extracts the text content and error flag from a tool result block
+func toolResultText(block anthropic.ContentBlockParamUnion) (string, bool) {
+	if block.OfToolResult == nil {
+		return "", false
+	}
+
+	var text strings.Builder
+	for _, content := range block.OfToolResult.Content {
+		if content.OfText != nil {
+			text.WriteString(content.OfText.Text)
 		}
 	}
+	return text.String(), block.OfToolResult.IsError.Value
 }
 
 // extractToolUses extracts tool use information from a message
@@ -880,65 +1854,49 @@ func extractToolUses(message *anthropic.Message) []agent.ToolUseInfo {
 	return toolUses
 }
 
-// extractFileReferences extracts @filename references from text and returns list of file paths
-func (m Model) extractFileReferences(text string) []string {
-	var references []string
-	runes := []rune(text)
-
-	for i := 0; i < len(runes); i++ {
-		char := runes[i]
-
-		// Check for @ that is not escaped
-		if char == '@' && (i == 0 || runes[i-1] != '\\') {
-			// Find the end of the filename
-			start := i + 1
-			end := start
-
-			// Find word boundary or whitespace (but allow / and . in filenames)
-			for end < len(runes) && !isWhitespace(runes[end]) {
-				end++
-			}
-
-			if end > start {
-				// Extract filename
-				filename := string(runes[start:end])
-				references = append(references, filename)
-
-				// Skip past the filename
-				i = end - 1
-			}
-		} else if char == '\\' && i+1 < len(runes) && runes[i+1] == '@' {
-			// Skip escaped @: \@ becomes @
-			i++ // Skip the @
-		}
-	}
-
-	return references
-}
-
 // executeFileReferences executes appropriate tools for @filename references and returns simulated tool call cycle
-func (m Model) executeFileReferences(text string) ([]anthropic.MessageParam, []tea.Cmd, error) {
-	references := m.extractFileReferences(text)
-	if len(references) == 0 {
+func (m Model) executeFileReferences(ctx context.Context, text string) ([]anthropic.MessageParam, []tea.Cmd, error) {
+	refs := references.Parse(text)
+	if len(refs) == 0 {
 		return nil, nil, nil
 	}
 
 	var toolUseBlocks []anthropic.ContentBlockParamUnion
 	var toolResultBlocks []anthropic.ContentBlockParamUnion
+	var commandOutputBlocks []anthropic.ContentBlockParamUnion
+	var attachmentBlocks []anthropic.ContentBlockParamUnion
 	var cmds []tea.Cmd
 
-	// Get working directory from completion engine via textarea
-	workingDir := "."
-	if completionEngine := m.textarea.CompletionEngine(); completionEngine != nil {
-		workingDir = completionEngine.GetWorkingDir()
-	}
+	workingDir := m.workingDir()
+
+	for _, r := range refs {
+		ref := r.Target
+		kind, err := r.Resolve(workingDir)
 
-	for _, ref := range references {
-		// Build full path
-		fullPath := filepath.Join(workingDir, ref)
+		// @!command references inline the command's output instead of a file
+		if kind == references.Command {
+			command := ref
+			commandOutputBlocks = append(commandOutputBlocks, anthropic.NewTextBlock(references.CommandOutput(ctx, command)))
+
+			cmd := func(command string) tea.Cmd {
+				return func() tea.Msg {
+					return AddMessageMsg{
+						Message: components.Message{
+							ID:        generateMessageID(),
+							Role:      "assistant",
+							Content:   fmt.Sprintf("run_command(%s)", command),
+							Type:      components.MessageTypeText,
+							Status:    components.MessageCompleted,
+							Timestamp: time.Now(),
+							UpdatedAt: time.Now(),
+						},
+					}
+				}
+			}(command)
+			cmds = append(cmds, cmd)
+			continue
+		}
 
-		// Check if it's a directory or file
-		info, err := os.Stat(fullPath)
 		if err != nil {
 			// Generate error tool result
 			toolID := generateMessageID()
@@ -969,9 +1927,47 @@ func (m Model) executeFileReferences(text string) ([]anthropic.MessageParam, []t
 			continue
 		}
 
+		// PDFs are attached as document blocks, and other binary files are
+		// skipped with an explanation rather than read as garbage text
+		if kind == references.File && (r.IsDocument() || r.IsBinary(workingDir)) {
+			var attachErr error
+			if r.IsDocument() {
+				var block anthropic.ContentBlockParamUnion
+				if block, attachErr = r.Document(workingDir); attachErr == nil {
+					attachmentBlocks = append(attachmentBlocks, block)
+				} else {
+					attachmentBlocks = append(attachmentBlocks, anthropic.NewTextBlock(fmt.Sprintf("%s was not attached: %v", ref, attachErr)))
+				}
+			} else {
+				attachErr = fmt.Errorf("unsupported binary file")
+				attachmentBlocks = append(attachmentBlocks, anthropic.NewTextBlock(references.UnsupportedMessage(ref)))
+			}
+
+			cmd := func(ref string, err error) tea.Cmd {
+				return func() tea.Msg {
+					msg := components.Message{
+						ID:        generateMessageID(),
+						Role:      "assistant",
+						Content:   fmt.Sprintf("attach(%s)", ref),
+						Type:      components.MessageTypeText,
+						Status:    components.MessageCompleted,
+						Timestamp: time.Now(),
+						UpdatedAt: time.Now(),
+					}
+					if err != nil {
+						msg.Content += fmt.Sprintf(" - Error: %v", err)
+						msg.Status = components.MessageError
+					}
+					return AddMessageMsg{Message: msg}
+				}
+			}(ref, attachErr)
+			cmds = append(cmds, cmd)
+			continue
+		}
+
 		toolID := generateMessageID()
 
-		if info.IsDir() {
+		if kind == references.Directory {
 			// Create tool use block for list_files
 			toolInput := map[string]string{"path": ref}
 			toolInputJSON, _ := json.Marshal(toolInput)
@@ -996,11 +1992,26 @@ func (m Model) executeFileReferences(text string) ([]anthropic.MessageParam, []t
 			cmds = append(cmds, cmd)
 
 			// Execute list_files tool and get result
-			result := m.agent.ExecuteTool(toolID, "list_files", toolInputJSON)
+			result, _ := m.agent.ExecuteTool(ctx, toolID, "list_files", toolInputJSON)
 			toolResultBlocks = append(toolResultBlocks, result)
 		} else {
-			// Create tool use block for read_file
-			toolInput := map[string]string{"path": ref}
+			// Create tool use block for read_file, limited to any @path:start-end range
+			toolInput := map[string]any{"path": ref}
+			label := ref
+			start, end, warning := r.LineRange(workingDir)
+			if start != 0 {
+				toolInput["start_line"], toolInput["end_line"] = start, end
+				label = fmt.Sprintf("%s:%d-%d", ref, start, end)
+			}
+			if warning != "" {
+				cmds = append(cmds, func() tea.Msg {
+					return ShowStatuslineMsg{
+						Type:     components.StatuslineWarning,
+						Text:     warning,
+						Duration: 4 * time.Second,
+					}
+				})
+			}
 			toolInputJSON, _ := json.Marshal(toolInput)
 			toolUseBlocks = append(toolUseBlocks, anthropic.NewToolUseBlock(toolID, toolInput, "read_file"))
 
@@ -1019,11 +2030,14 @@ func (m Model) executeFileReferences(text string) ([]anthropic.MessageParam, []t
 						},
 					}
 				}
-			}(ref)
+			}(label)
 			cmds = append(cmds, cmd)
 
 			// Execute read_file tool and get result
-			result := m.agent.ExecuteTool(toolID, "read_file", toolInputJSON)
+			result, _ := m.agent.ExecuteTool(ctx, toolID, "read_file", toolInputJSON)
+			if config.Get().InterpolateEnv {
+				result = interpolateToolResult(result, ref)
+			}
 			toolResultBlocks = append(toolResultBlocks, result)
 		}
 	}
@@ -1036,120 +2050,42 @@ func (m Model) executeFileReferences(text string) ([]anthropic.MessageParam, []t
 		messages = append(messages, anthropic.NewAssistantMessage(toolUseBlocks...))
 	}
 
-	// User message with tool results
-	if len(toolResultBlocks) > 0 {
-		messages = append(messages, anthropic.NewUserMessage(toolResultBlocks...))
+	// User message with tool results, followed by any command output and attachments
+	if len(toolResultBlocks) > 0 || len(commandOutputBlocks) > 0 || len(attachmentBlocks) > 0 {
+		userBlocks := append(toolResultBlocks, commandOutputBlocks...)
+		userBlocks = append(userBlocks, attachmentBlocks...)
+		messages = append(messages, anthropic.NewUserMessage(userBlocks...))
 	}
 
 	return messages, cmds, nil
 }
 
-// expandFileReferences expands @filename references to actual file contents
-func (m Model) expandFileReferences(text string) string {
-	var result strings.Builder
-	runes := []rune(text)
-
-	for i := 0; i < len(runes); i++ {
-		char := runes[i]
-
-		// Check for @ that is not escaped
-		if char == '@' && (i == 0 || runes[i-1] != '\\') {
-			// Find the end of the filename
-			start := i + 1
-			end := start
-
-			// Find word boundary or whitespace (but allow / and . in filenames)
-			for end < len(runes) && !isWhitespace(runes[end]) {
-				end++
-			}
-
-			if end > start {
-				// Extract filename
-				filename := string(runes[start:end])
-
-				// Expand to file contents
-				fileContents := m.readFileOrDirectoryContents(filename)
-				result.WriteString(fileContents)
-
-				// Skip past the filename
-				i = end - 1
-			} else {
-				// No filename after @, just write the @
-				result.WriteRune(char)
-			}
-		} else if char == '\\' && i+1 < len(runes) && runes[i+1] == '@' {
-			// Handle escaped @: \@ becomes @
-			result.WriteRune('@')
-			i++ // Skip the @
-		} else {
-			result.WriteRune(char)
+// interpolateToolResult replaces ${VAR} placeholders in a referenced file's
+// read_file result with environment variables
+func interpolateToolResult(block anthropic.ContentBlockParamUnion, path string) anthropic.ContentBlockParamUnion {
+	if block.OfToolResult == nil {
+		return block
+	}
+	for _, content := range block.OfToolResult.Content {
+		if content.OfText != nil {
+			content.OfText.Text = references.Interpolate(content.OfText.Text, path)
 		}
 	}
+	return block
+}
 
-	return result.String()
+// expandFileReferences expands @filename references to actual file contents
+func (m Model) expandFileReferences(text string) string {
+	return references.Expand(text, m.workingDir())
 }
 
-func (m Model) readFileOrDirectoryContents(relativePath string) string {
+// workingDir returns the directory @ references are resolved against
+func (m Model) workingDir() string {
 	// Get working directory from completion engine via textarea
-	workingDir := "."
 	if completionEngine := m.textarea.CompletionEngine(); completionEngine != nil {
-		workingDir = completionEngine.GetWorkingDir()
-	}
-
-	// Build full path
-	fullPath := filepath.Join(workingDir, relativePath)
-
-	// Check if it's a directory or file
-	info, err := os.Stat(fullPath)
-	if err != nil {
-		return fmt.Sprintf("Error accessing %s: %v", relativePath, err)
-	}
-
-	if info.IsDir() {
-		return m.readDirectoryContents(fullPath, relativePath)
-	} else {
-		return m.readFileContents(fullPath, relativePath)
-	}
-}
-
-func (m Model) readFileContents(fullPath, relativePath string) string {
-	content, err := os.ReadFile(fullPath)
-	if err != nil {
-		return fmt.Sprintf("Error reading file %s: %v", relativePath, err)
-	}
-
-	// Format as a code block with file path
-	return fmt.Sprintf("Contents of %s:\n```\n%s\n```", relativePath, string(content))
-}
-
-func (m Model) readDirectoryContents(fullPath, relativePath string) string {
-	entries, err := os.ReadDir(fullPath)
-	if err != nil {
-		return fmt.Sprintf("Error reading directory %s: %v", relativePath, err)
-	}
-
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("Contents of directory %s:\n", relativePath))
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			result.WriteString(fmt.Sprintf("- %s/ (directory)\n", entry.Name()))
-		} else {
-			// Get file info for size
-			info, err := entry.Info()
-			if err == nil {
-				result.WriteString(fmt.Sprintf("- %s (%d bytes)\n", entry.Name(), info.Size()))
-			} else {
-				result.WriteString(fmt.Sprintf("- %s\n", entry.Name()))
-			}
-		}
+		return completionEngine.GetWorkingDir()
 	}
-
-	return result.String()
-}
-
-func isWhitespace(r rune) bool {
-	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	return "."
 }
 
 // startAnimation returns a command to tick spinner animations
@@ -1162,7 +2098,7 @@ func (m Model) startAnimation() tea.Cmd {
 // formatToolArguments formats tool arguments for display
 func formatToolArguments(toolName string, input json.RawMessage) string {
 	switch toolName {
-	case "read_file", "edit_file", "write_file":
+	case "read_file", "edit_file", "write_file", "search_replace_file":
 		var args struct {
 			Path string `json:"path"`
 		}
@@ -1280,60 +2216,73 @@ func (m Model) checkAutoCompaction() tea.Cmd {
 // compactConversation summarizes the current conversation and clears history
 func (m Model) compactConversation(isAuto bool) tea.Cmd {
 	return func() tea.Msg {
-		// Build conversation history for summarization
-		conversation := m.buildConversationHistory()
-
-		// If no conversation to compact, return early
-		if len(conversation) == 0 {
+		summary, err := m.summarizeConversation()
+		if err != nil {
 			return CompactConversationMsg{
 				Summary: "",
-				Error:   fmt.Errorf("no conversation to compact"),
+				Error:   err,
 				IsAuto:  isAuto,
 			}
 		}
 
-		// Build a new conversation for summarization
-		summaryConversation := []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(summaryPrompt)),
+		return CompactConversationMsg{
+			Summary: summary,
+			Error:   nil,
+			IsAuto:  isAuto,
 		}
+	}
+}
 
-		// Add the entire conversation as context
-		for _, msg := range conversation {
-			summaryConversation = append(summaryConversation, msg)
-		}
+// showSummary summarizes the current conversation without changing it
+func (m Model) showSummary() tea.Cmd {
+	return func() tea.Msg {
+		summary, err := m.summarizeConversation()
+		return SummaryMsg{Summary: summary, Error: err}
+	}
+}
 
-		// Add final instruction to summarize
-		summaryConversation = append(summaryConversation,
-			anthropic.NewUserMessage(anthropic.NewTextBlock("Please summarize this conversation.")))
+// summarizeConversation asks the model for a summary of the conversation using summaryPrompt
+func (m Model) summarizeConversation() (string, error) {
+	// Summarize the whole conversation, not just what fits in a turn's budget
+	conversation := m.fullConversationHistory()
 
-		// Create context with timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+	// If no conversation to summarize, return early
+	if len(conversation) == 0 {
+		return "", fmt.Errorf("no conversation to summarize")
+	}
 
-		// Run inference to get summary
-		response, err := m.agent.RunInference(ctx, summaryConversation)
-		if err != nil {
-			return CompactConversationMsg{
-				Summary: "",
-				Error:   fmt.Errorf("failed to generate summary: %w", err),
-				IsAuto:  isAuto,
-			}
-		}
+	// Build a new conversation for summarization
+	summaryConversation := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock(summaryPrompt)),
+	}
 
-		// Extract text content from response
-		var summary strings.Builder
-		for _, content := range response.Content {
-			if content.Type == "text" {
-				summary.WriteString(content.Text)
-			}
-		}
+	// Add the entire conversation as context
+	for _, msg := range conversation {
+		summaryConversation = append(summaryConversation, msg)
+	}
 
-		return CompactConversationMsg{
-			Summary: summary.String(),
-			Error:   nil,
-			IsAuto:  isAuto,
+	// Add final instruction to summarize
+	summaryConversation = append(summaryConversation,
+		anthropic.NewUserMessage(anthropic.NewTextBlock("Please summarize this conversation.")))
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Run inference to get summary
+	response, err := m.agent.RunInference(ctx, summaryConversation)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	// Extract text content from response
+	var summary strings.Builder
+	for _, content := range response.Content {
+		if content.Type == "text" {
+			summary.WriteString(content.Text)
 		}
 	}
+	return summary.String(), nil
 }
 
 // countTokens estimates the number of tokens in a string
@@ -1350,12 +2299,12 @@ func countTokens(text string) int {
 func (m Model) countConversationTokens() int {
 	tokens := 0
 	
-	// Count system prompt tokens (from systemPromptContent)
-	tokens += countTokens(systemPromptContent)
+	// Count system prompt tokens (from the active persona)
+	tokens += countTokens(m.systemPrompt)
 	
 	// Count message tokens
 	for _, msg := range m.messages {
-		if msg.Role == "user" || msg.Role == "assistant" {
+		if (msg.Role == "user" || msg.Role == "assistant") && !msg.Nested {
 			tokens += countTokens(msg.Content)
 			
 			// Count tool invocation/result tokens
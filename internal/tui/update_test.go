@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/config"
+	"reapo/internal/tui/components"
+	"reapo/internal/tui/components/vimtextarea"
+)
+
+// newTestModel returns a Model whose config and data live in temporary directories
+func newTestModel(t *testing.T) Model {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := config.Init(""); err != nil {
+		t.Fatal(err)
+	}
+	return NewModel(anthropic.NewClient(), nil)
+}
+
+func TestOnlyTurnsAreTrimmedToBudget(t *testing.T) {
+	m := newTestModel(t)
+	for i := 0; i < 20; i++ {
+		m.messages = append(m.messages,
+			components.Message{Role: "user", Content: strings.Repeat("question ", 50), Type: components.MessageTypeText},
+			components.Message{Role: "assistant", Content: strings.Repeat("answer ", 50), Type: components.MessageTypeText, Status: components.MessageCompleted},
+		)
+	}
+	if err := config.Set("context_budget_tokens", strconv.Itoa(countTokens(m.systemPrompt)+500)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(m.buildConversationHistory()); got >= 40 {
+		t.Errorf("turn history has %d messages, want it trimmed", got)
+	}
+	if got := len(m.fullConversationHistory()); got != 40 {
+		t.Errorf("summary history has %d messages, want all 40", got)
+	}
+}
+
+// update feeds msg to m and returns the updated Model
+func update(m Model, msg MessageUpdateMsg) Model {
+	updated, _ := m.Update(msg)
+	return updated.(Model)
+}
+
+func TestWhitespaceReplyAddsNoBubble(t *testing.T) {
+	for _, content := range []string{"", " ", "\n\n", " \t\n "} {
+		m := newTestModel(t)
+		before := len(m.messages)
+
+		// A final response for a message that was never streamed
+		m = update(m, MessageUpdateMsg{MessageID: "final", Content: content, Status: components.MessageCompleted})
+		if len(m.messages) != before {
+			t.Errorf("final reply %q added a message: %+v", content, m.messages[before:])
+		}
+
+		// A streamed reply that ends up blank
+		m.messages = append(m.messages, components.Message{
+			ID:     "streamed",
+			Role:   "assistant",
+			Type:   components.MessageTypeText,
+			Status: components.MessageStreaming,
+		})
+		m = update(m, MessageUpdateMsg{MessageID: "streamed", Content: content, Status: components.MessageCompleted})
+		for _, message := range m.messages {
+			if message.ID == "streamed" {
+				t.Errorf("streamed reply %q left a bubble: %+v", content, message)
+			}
+		}
+	}
+}
+
+func TestReplyWithTextAddsBubble(t *testing.T) {
+	m := newTestModel(t)
+	m = update(m, MessageUpdateMsg{MessageID: "final", Content: "  done\n", Status: components.MessageCompleted})
+
+	last := m.messages[len(m.messages)-1]
+	if last.ID != "final" || last.Content != "  done\n" {
+		t.Errorf("last message = %+v, want the reply", last)
+	}
+}
+
+func TestNewArchivesConversation(t *testing.T) {
+	m := newTestModel(t)
+	m.messages = append(m.messages,
+		components.Message{ID: "q", Role: "user", Content: "hello", Type: components.MessageTypeText},
+		components.Message{ID: "a", Role: "assistant", Content: "hi", Type: components.MessageTypeText, Status: components.MessageCompleted},
+	)
+	oldSession := m.sessionID
+
+	updated, cmd := m.Update(vimtextarea.SlashCommandMsg{Command: "/new"})
+	m = updated.(Model)
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, cmd := range batch {
+			if cmd != nil {
+				cmd()
+			}
+		}
+	}
+
+	if m.sessionID == oldSession {
+		t.Error("/new kept the old session ID")
+	}
+	sessions := savedSessions(false, "")
+	if len(sessions) != 1 || sessions[0].ID != oldSession || len(sessions[0].Messages) != 2 {
+		t.Fatalf("saved sessions = %+v, want the old conversation", sessions)
+	}
+}
+
+func TestClipboardPasteIntoScratch(t *testing.T) {
+	m := newTestModel(t)
+	m.scratchOpen = true
+
+	updated, _ := m.Update(vimtextarea.ClipboardPasteMsg{Text: "first\nsecond\nthird"})
+	m = updated.(Model)
+	if got := m.scratch.Value(); got != "first\nsecond\nthird" {
+		t.Errorf("scratch buffer = %q, want the clipboard", got)
+	}
+	if got := m.textarea.Value(); got != "" {
+		t.Errorf("input = %q, want it untouched", got)
+	}
+	if got, want := m.scratch.Height(), m.inputHeight(m.scratch.Value()); got != want {
+		t.Errorf("scratch buffer height = %d, want %d", got, want)
+	}
+}
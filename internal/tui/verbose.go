@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/tui/components"
+)
+
+// toggleVerbose switches between showing every tool result with its full
+// input and output, and the usual view, where results of read-only tools
+// like read_file are hidden and previews are cut to a few lines. Results
+// are always kept, so toggling also applies to earlier tool calls.
+func (m Model) toggleVerbose() (tea.Model, tea.Cmd) {
+	m.verbose = !m.verbose
+	m.chatScroll = min(m.chatScroll, m.maxChatScroll())
+
+	text := "Verbose off: read-only tool results hidden, previews shortened"
+	if m.verbose {
+		text = "Verbose on: showing every tool result with full inputs and outputs"
+	}
+	return m, func() tea.Msg {
+		return ShowStatuslineMsg{
+			Type:     components.StatuslineInfo,
+			Text:     text,
+			Duration: 3 * time.Second,
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"reapo/internal/config"
+	"reapo/internal/logger"
+	"reapo/internal/project"
+	"reapo/internal/tui/components"
+)
+
+// When verify_command is set, or project_defaults picked one for the
+// detected project type, it runs after every turn in which the model
+// edited files. A failure is sent back to the model as a new message asking
+// for a fix, up to verify_max_attempts times per message the user sends.
+
+// verifyTimeout bounds how long the verify command may run
+const verifyTimeout = 5 * time.Minute
+
+// verifyOutputLimit caps how much verify output is sent back to the model
+const verifyOutputLimit = 8000
+
+// editTools are the tools whose success means files were changed
+var editTools = map[string]bool{
+	"edit_file":           true,
+	"search_replace_file": true,
+}
+
+// VerifyResultMsg carries the outcome of running the verify command
+type VerifyResultMsg struct {
+	Command string
+	Output  string
+	Error   error
+}
+
+// noteEdits remembers whether any tool in a batch edited files
+func (m Model) noteEdits(results []ToolResultMsg) Model {
+	for _, result := range results {
+		if result.Error == "" && editTools[result.ToolName] {
+			m.turnEdited = true
+		}
+	}
+	return m
+}
+
+// startVerify runs the verify command if the finished turn edited files
+func (m Model) startVerify() (Model, tea.Cmd) {
+	command := verifyCommand(config.Get())
+	edited := m.turnEdited
+	m.turnEdited = false
+	if !edited || strings.TrimSpace(command) == "" {
+		return m, nil
+	}
+
+	m.processing = true
+	m.processingText = fmt.Sprintf("Verifying: %s", command)
+	m.processingSpinner = components.NewSpinnerComponent("")
+	return m, tea.Batch(m.startAnimation(), runVerify(command))
+}
+
+// verifyCommand returns the configured verify command, or the detected
+// project type's with project_defaults on, or "" once verify_command is off
+func verifyCommand(cfg config.Config) string {
+	switch {
+	case cfg.VerifyCommand == config.VerifyOff:
+		return ""
+	case cfg.VerifyCommand == "" && cfg.ProjectDefaults:
+		return project.Current.VerifyCommand
+	}
+	return cfg.VerifyCommand
+}
+
+// projectStatus describes the detected project type and the default
+// commands it supplies in place of unset format_commands and verify_command
+func projectStatus(cfg config.Config) string {
+	status := project.Current.String()
+	if !cfg.ProjectDefaults {
+		return status + " (project_defaults off)"
+	}
+
+	var defaults []string
+	if cfg.VerifyCommand == "" && project.Current.VerifyCommand != "" {
+		defaults = append(defaults, "verify with "+project.Current.VerifyCommand)
+	}
+	exts := make([]string, 0, len(project.Current.FormatCommands))
+	for ext := range project.Current.FormatCommands {
+		if _, ok := cfg.FormatCommands[ext]; !ok {
+			exts = append(exts, ext)
+		}
+	}
+	sort.Strings(exts)
+	for _, ext := range exts {
+		defaults = append(defaults, "format "+ext+" with "+project.Current.FormatCommands[ext])
+	}
+	if len(defaults) > 0 {
+		status += "; " + strings.Join(defaults, ", ")
+	}
+	return status
+}
+
+// runVerify runs command in the working directory. Like the shell tool it is
+// split on whitespace and run without a shell.
+func runVerify(command string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+		defer cancel()
+
+		fields := strings.Fields(command)
+		output, err := exec.CommandContext(ctx, fields[0], fields[1:]...).CombinedOutput()
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", verifyTimeout)
+		}
+		logger.Info("Verify command %q finished: err=%v", command, err)
+		return VerifyResultMsg{Command: command, Output: string(output), Error: err}
+	}
+}
+
+// handleVerifyResult reports a passing verify, or asks the model to fix a
+// failing one while attempts remain
+func (m Model) handleVerifyResult(msg VerifyResultMsg) (tea.Model, tea.Cmd) {
+	m.processing = false
+	m.processingText = ""
+	m.processingSpinner = nil
+
+	if msg.Error == nil {
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineInfo,
+				Text:     fmt.Sprintf("Verify passed: %s", msg.Command),
+				Duration: 4 * time.Second,
+			}
+		}
+	}
+
+	// Keep the end of long output, where build and test summaries are
+	output := strings.TrimSpace(msg.Output)
+	if len(output) > verifyOutputLimit {
+		cut := len(output) - verifyOutputLimit
+		for cut < len(output) && !utf8.RuneStart(output[cut]) {
+			cut++
+		}
+		output = "...\n" + output[cut:]
+	}
+
+	maxAttempts := config.Get().VerifyMaxAttempts
+	if m.verifyAttempts >= maxAttempts {
+		m.messages = append(m.messages, components.Message{
+			ID:        generateMessageID(),
+			Role:      "system",
+			Content:   fmt.Sprintf("%s still fails after %d fix attempts (%v):\n%s", msg.Command, maxAttempts, msg.Error, output),
+			Type:      components.MessageTypeText,
+			Status:    components.MessageError,
+			IsError:   true,
+			Timestamp: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+		return m, func() tea.Msg {
+			return ShowStatuslineMsg{
+				Type:     components.StatuslineError,
+				Text:     fmt.Sprintf("Verify failed: %s", msg.Command),
+				Duration: 6 * time.Second,
+			}
+		}
+	}
+
+	m.verifyAttempts++
+	m.processing = true
+	// Escape @ so the output isn't read as file or command references
+	prompt := fmt.Sprintf("Running `%s` after your edits failed (%v):\n\n```\n%s\n```\n\nPlease fix the problems. (Automatic fix attempt %d of %d)",
+		msg.Command, msg.Error, strings.ReplaceAll(output, "@", "\\@"), m.verifyAttempts, maxAttempts)
+	return m, m.processMessage(prompt)
+}
@@ -1,15 +1,30 @@
 package tui
 
 import (
+	"github.com/charmbracelet/lipgloss"
+	"reapo/internal/config"
+	"reapo/internal/personas"
+	"reapo/internal/tools"
 	"reapo/internal/tui/components"
 )
 
+// Smallest terminal size the main layout can be drawn in
+const (
+	minViewWidth  = 30
+	minViewHeight = 12
+)
+
 // View renders the TUI
 func (m Model) View() string {
 	if !m.ready {
 		return "Loading..."
 	}
 
+	// Avoid drawing a garbled layout; the full UI returns once the terminal is resized
+	if m.viewport.width < minViewWidth || m.viewport.height < minViewHeight {
+		return "Terminal too small"
+	}
+
 	// Get completion state
 	completionState := m.textarea.CompletionState()
 
@@ -22,22 +37,26 @@ func (m Model) View() string {
 			completionState.Selected,
 			m.viewport.width,
 		)
+		// Line the popup up with the trigger, past the input's border and padding
+		completionComponent.SetAnchor(2 + m.textarea.CompletionColumn())
 		completionHeight = completionComponent.Height()
 	}
 
-	// Calculate processing indicator height (if active)
-	processingHeight := 0
-	if m.processing {
-		processingHeight = 2 // 1 line for content + 1 for spacing
-	}
-
-	// Calculate heights: total - textarea height - completion height - processing height - border (2 lines) - footer line - statusline - spacing
-	textareaHeight := m.textarea.Height()
-	chatHeight := m.viewport.height - textareaHeight - completionHeight - processingHeight - 5
+	chatHeight, chatWidth := m.chatSize(completionHeight)
+	todoPanelWidth := m.todoPanelWidth()
+	showTodoPanel := todoPanelWidth > 0
 
 	// Create and render components
-	chatComponent := components.NewChatComponent(m.messages, chatHeight, m.viewport.width)
+	chatComponent := components.NewChatComponent(m.messages, chatHeight, chatWidth)
+	chatComponent.SetFocused(m.focusedMessage)
+	chatComponent.SetHighlight(m.findQuery)
+	chatComponent.SetScroll(m.chatScroll)
+	chatComponent.SetVerbose(m.verbose)
 	chat := chatComponent.RenderWithSpinners(m.spinners)
+	if showTodoPanel {
+		todoPanel := components.NewTodoPanel(tools.Todos(), todoPanelWidth, chatHeight)
+		chat = lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Width(chatWidth).Render(chat), todoPanel.Render())
+	}
 
 	// Render processing indicator if active
 	var processingIndicator string
@@ -51,11 +70,26 @@ func (m Model) View() string {
 		completion = completionComponent.Render() + "\n"
 	}
 
-	inputComponent := components.NewInputComponent(m.textarea, m.viewport.width)
+	// The scratch buffer takes the input's place while it is open
+	active := m.textarea
+	if m.scratchOpen {
+		active = m.scratch
+	}
+	inputComponent := components.NewInputComponent(active, m.viewport.width)
 	input := inputComponent.Render()
 
-	footerComponent := components.NewFooterComponent(m.textarea.Mode(), m.viewport.width)
+	// The key hints take the first of the blank lines between the input and footer
+	gap := "\n\n\n"
+	if config.Get().KeyHints {
+		gap = "\n" + m.keyHints() + "\n\n"
+	}
+
+	footerComponent := components.NewFooterComponent(active.Mode(), m.viewport.width)
 	footerComponent.UpdateContextInfo(m.contextTokens, m.maxContextTokens, m.currentModel)
+	footerComponent.SetGitStatus(m.gitBranch, m.gitDirty)
+	if m.persona != personas.Default {
+		footerComponent.SetPersona(m.persona)
+	}
 	footer := footerComponent.Render()
 	
 	// Render statusline
@@ -64,6 +98,11 @@ func (m Model) View() string {
 		statusline = m.statusline.Render()
 	}
 
+	// Render fatal error modal if visible (overlay on top of everything)
+	if m.fatalModal.IsVisible() {
+		return m.fatalModal.View()
+	}
+
 	// Render help modal if visible (overlay on top)
 	if m.helpModal.IsVisible() {
 		return m.helpModal.View()
@@ -74,10 +113,59 @@ func (m Model) View() string {
 		return m.statusModal.View()
 	}
 	
+	// Render config modal if visible (overlay on top)
+	if m.configModal.IsVisible() {
+		return m.configModal.View()
+	}
+
+	// Render debug modal if visible (overlay on top)
+	if m.debugModal.IsVisible() {
+		return m.debugModal.View()
+	}
+
+	// Render command palette if visible (overlay on top)
+	if m.commandPalette.IsVisible() {
+		return m.commandPalette.View()
+	}
+	
+	// Render onboarding modal if visible (overlay on top)
+	if m.onboardingModal.IsVisible() {
+		return m.onboardingModal.View()
+	}
+
 	// Render auth modal if active (overlay on top)
-	if m.authModal.Active() {
+	if m.authModal.IsVisible() {
 		return m.authModal.View()
 	}
 
-	return chat + processingIndicator + completion + input + "\n\n\n" + footer + "\n" + statusline
+	return chat + processingIndicator + completion + input + gap + footer + "\n" + statusline
+}
+
+// chatSize returns the height and width of the chat area, given the height
+// of the completion menu shown above the input
+func (m Model) chatSize(completionHeight int) (int, int) {
+	// Calculate processing indicator height (if active)
+	processingHeight := 0
+	if m.processing {
+		processingHeight = 2 // 1 line for content + 1 for spacing
+	}
+
+	// Calculate heights: total - textarea height - completion height - processing height - border (2 lines) - footer line - statusline - spacing
+	textareaHeight := m.textarea.Height()
+	if m.scratchOpen {
+		textareaHeight = m.scratch.Height()
+	}
+	chatHeight := max(m.viewport.height-textareaHeight-completionHeight-processingHeight-5, 1)
+
+	// Make room for the todo panel beside the chat if it is enabled
+	return chatHeight, m.viewport.width - m.todoPanelWidth()
+}
+
+// todoPanelWidth returns the width of the todo panel beside the chat, or 0
+// when it is hidden or the terminal is too narrow for it
+func (m Model) todoPanelWidth() int {
+	if !m.showTodos || m.viewport.width < 60 {
+		return 0
+	}
+	return min(36, m.viewport.width/3)
 }